@@ -1,3 +1,25 @@
 module api-gateway
 
 go 1.23
+
+require (
+	github.com/golang-jwt/jwt/v5 v5.0.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237
+	google.golang.org/grpc v1.64.0
+	google.golang.org/protobuf v1.33.0
+	shared v0.0.0
+	task-service/proto v0.0.0
+	user-service/proto v0.0.0
+)
+
+require (
+	golang.org/x/net v0.22.0 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
+
+replace shared => ../shared
+
+replace task-service/proto => ../task-service/proto
+
+replace user-service/proto => ../user-service/proto
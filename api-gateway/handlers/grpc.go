@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var jsonMarshal = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+var jsonUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// preconditionVersion — тип нарушения PreconditionFailure, которым сервисы помечают устаревшую
+// expected_version (If-Match): только такая ошибка FailedPrecondition становится 412
+const preconditionVersion = "VERSION"
+
+// HTTPStatusFromCode сопоставляет gRPC-код с HTTP-статусом
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteGRPCError переводит ошибку gRPC в JSON-ответ с подходящим HTTP-статусом
+func WriteGRPCError(w http.ResponseWriter, err error) {
+	st, _ := status.FromError(err)
+	code := HTTPStatusFromCode(st.Code())
+	if isVersionMismatch(st) {
+		code = http.StatusPreconditionFailed
+	}
+	WriteJSONError(w, code, st.Message())
+}
+
+// isVersionMismatch сообщает, что запрос отклонён из-за устаревшей версии объекта
+func isVersionMismatch(st *status.Status) bool {
+	if st.Code() != codes.FailedPrecondition {
+		return false
+	}
+	for _, detail := range st.Details() {
+		if failure, ok := detail.(*errdetails.PreconditionFailure); ok {
+			for _, v := range failure.Violations {
+				if v.Type == preconditionVersion {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// WriteProtoJSON сериализует proto-сообщение в JSON (имена полей как в .proto)
+func WriteProtoJSON(w http.ResponseWriter, code int, msg proto.Message) {
+	body, err := jsonMarshal.Marshal(msg)
+	if err != nil {
+		WriteJSONError(w, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(body)
+}
+
+// ReadProtoJSON читает JSON-тело запроса в proto-сообщение.
+// Пустое тело допустимо и оставляет сообщение без изменений.
+func ReadProtoJSON(r *http.Request, msg proto.Message) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	return jsonUnmarshal.Unmarshal(body, msg)
+}
+
+// ReadPagination разбирает query-параметры page и page_size (по умолчанию 1 и 20)
+func ReadPagination(r *http.Request) (page, pageSize int32, err error) {
+	page, pageSize = 1, 20
+	q := r.URL.Query()
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("invalid page")
+		}
+		page = int32(n)
+	}
+	if v := q.Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("invalid page_size")
+		}
+		pageSize = int32(n)
+	}
+	return page, pageSize, nil
+}
+
+// ReadPageToken читает параметры page_token и include_total постраничных списков
+func ReadPageToken(r *http.Request) (token string, includeTotal bool, err error) {
+	q := r.URL.Query()
+	if v := q.Get("include_total"); v != "" {
+		if includeTotal, err = strconv.ParseBool(v); err != nil {
+			return "", false, errors.New("invalid include_total")
+		}
+	}
+	return q.Get("page_token"), includeTotal, nil
+}
+
+// ReadPageTokenSize читает параметры page_token и page_size списков без общего числа записей;
+// page_size 0 — значение сервиса по умолчанию
+func ReadPageTokenSize(r *http.Request) (token string, pageSize int32, err error) {
+	q := r.URL.Query()
+	if v := q.Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return "", 0, errors.New("invalid page_size")
+		}
+		pageSize = int32(n)
+	}
+	return q.Get("page_token"), pageSize, nil
+}
+
+// Identity — данные пользователя из проверенного gateway JWT
+type Identity struct {
+	UserID string
+	Role   string
+	// Unconfirmed — токен выдан до подтверждения email (claim email_verified=false)
+	Unconfirmed bool
+}
+
+type identityKey struct{}
+
+// WithIdentity сохраняет проверенные данные пользователя в контексте запроса
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext возвращает данные пользователя, сохранённые JWTMiddleware
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// OutgoingContext пробрасывает заголовок Authorization и проверенные
+// x-user-id/x-user-role в gRPC metadata
+func OutgoingContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", auth)
+	}
+	if id, ok := IdentityFromContext(ctx); ok {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-user-id", id.UserID, "x-user-role", id.Role)
+	}
+	return ctx
+}
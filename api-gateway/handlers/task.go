@@ -0,0 +1,477 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	taskpb "task-service/proto"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// TaskHandler транслирует REST-запросы /task/* в вызовы TaskService
+type TaskHandler struct {
+	client taskpb.TaskServiceClient
+}
+
+// NewTaskHandler возвращает http.Handler для маршрутов /task/tasks, /task/projects, /task/columns и /task/comments
+func NewTaskHandler(client taskpb.TaskServiceClient) http.Handler {
+	h := &TaskHandler{client: client}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /task/tasks", h.createTask)
+	mux.HandleFunc("GET /task/tasks", h.listTasks)
+	mux.HandleFunc("GET /task/tasks/{id}", h.getTask)
+	mux.HandleFunc("PATCH /task/tasks/{id}", h.updateTask)
+	mux.HandleFunc("DELETE /task/tasks/{id}", h.deleteTask)
+	mux.HandleFunc("POST /task/tasks/{id}/status", h.changeStatus)
+	mux.HandleFunc("POST /task/projects", h.createProject)
+	mux.HandleFunc("GET /task/projects", h.listProjects)
+	mux.HandleFunc("POST /task/projects/{id}/members", h.addMember)
+	mux.HandleFunc("POST /task/projects/{id}/columns", h.createColumn)
+	mux.HandleFunc("GET /task/projects/{id}/columns", h.listColumns)
+	mux.HandleFunc("PATCH /task/columns/{id}", h.updateColumn)
+	mux.HandleFunc("DELETE /task/columns/{id}", h.deleteColumn)
+	mux.HandleFunc("POST /task/tasks/{id}/move", h.moveTask)
+	mux.HandleFunc("POST /task/tasks/{id}/comments", h.addComment)
+	mux.HandleFunc("GET /task/tasks/{id}/comments", h.listComments)
+	mux.HandleFunc("PATCH /task/comments/{id}", h.editComment)
+	mux.HandleFunc("DELETE /task/comments/{id}", h.deleteComment)
+	mux.HandleFunc("GET /task/tasks/{id}/history", h.getTaskHistory)
+	mux.HandleFunc("PUT /task/tasks/{id}/parent", h.setTaskParent)
+	mux.HandleFunc("POST /task/tasks/{id}/dependencies", h.addDependency)
+	mux.HandleFunc("GET /task/tasks/{id}/dependencies", h.listDependencies)
+	mux.HandleFunc("DELETE /task/tasks/{id}/dependencies/{blocker_id}", h.removeDependency)
+	mux.HandleFunc("POST /task/tasks/{id}/archive", h.archiveTask)
+	mux.HandleFunc("POST /task/tasks/{id}/unarchive", h.unarchiveTask)
+	mux.HandleFunc("POST /task/tasks/{id}/restore", h.restoreTask)
+	mux.HandleFunc("POST /task/tasks/batch", h.batchUpdateTasks)
+	return mux
+}
+
+func (h *TaskHandler) createTask(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.CreateTaskRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	resp, err := h.client.CreateTask(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusCreated, resp)
+}
+
+func (h *TaskHandler) getTask(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.client.GetTask(OutgoingContext(r), &taskpb.GetTaskRequest{TaskId: r.PathValue("id")})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	w.Header().Set("ETag", taskETag(resp.Task.Version))
+	WriteProtoJSON(w, http.StatusOK, resp.Task)
+}
+
+// updateTask — обновление задачи; версию, на основе которой сделаны изменения, можно передать
+// полем expected_version или заголовком If-Match с ETag из GET /task/tasks/{id}.
+// Изменяемые поля — параметр update_mask=title,due_date (или update_mask в теле, в camelCase).
+func (h *TaskHandler) updateTask(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.UpdateTaskRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.TaskId = r.PathValue("id")
+	if v := r.URL.Query().Get("update_mask"); v != "" {
+		req.UpdateMask = &fieldmaskpb.FieldMask{}
+		for _, path := range strings.Split(v, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				req.UpdateMask.Paths = append(req.UpdateMask.Paths, path)
+			}
+		}
+	}
+	if v := r.Header.Get("If-Match"); v != "" && req.ExpectedVersion == 0 {
+		version, ok := parseTaskETag(v)
+		if !ok {
+			WriteJSONError(w, http.StatusBadRequest, "invalid If-Match")
+			return
+		}
+		req.ExpectedVersion = version
+	}
+	resp, err := h.client.UpdateTask(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	w.Header().Set("ETag", taskETag(resp.Version))
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+// taskETag — ETag задачи: её версия в кавычках
+func taskETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseTaskETag разбирает If-Match: "3" (или W/"3") — версия 3, * — любая версия (0)
+func parseTaskETag(v string) (int64, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+	if v == "*" {
+		return 0, true
+	}
+	if len(v) < 2 || v[0] != '"' || v[len(v)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(v[1:len(v)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+func (h *TaskHandler) deleteTask(w http.ResponseWriter, r *http.Request) {
+	_, err := h.client.DeleteTask(OutgoingContext(r), &taskpb.DeleteTaskRequest{TaskId: r.PathValue("id")})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TaskHandler) listTasks(w http.ResponseWriter, r *http.Request) {
+	page, pageSize, err := ReadPagination(r)
+	if err != nil {
+		WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageToken, includeTotal, err := ReadPageToken(r)
+	if err != nil {
+		WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	q := r.URL.Query()
+	req := &taskpb.ListTasksRequest{
+		Status:       q.Get("status"),
+		AssigneeId:   q.Get("assignee_id"),
+		CreatorId:    q.Get("creator_id"),
+		ProjectId:    q.Get("project_id"),
+		ColumnId:     q.Get("column_id"),
+		ParentId:     q.Get("parent_id"),
+		LabelsMatch:  q.Get("labels_match"),
+		DueFrom:      q.Get("due_from"),
+		DueTo:        q.Get("due_to"),
+		Search:       q.Get("search"),
+		Sort:         q.Get("sort"),
+		View:         q.Get("view"),
+		Page:         page,
+		PageSize:     pageSize,
+		PageToken:    pageToken,
+		IncludeTotal: includeTotal,
+	}
+	// метки — через запятую или повторением параметра: labels=a,b или labels=a&labels=b
+	for _, v := range q["labels"] {
+		for _, label := range strings.Split(v, ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				req.Labels = append(req.Labels, label)
+			}
+		}
+	}
+	if v := q.Get("overdue"); v != "" {
+		if req.Overdue, err = strconv.ParseBool(v); err != nil {
+			WriteJSONError(w, http.StatusBadRequest, "invalid overdue")
+			return
+		}
+	}
+	resp, err := h.client.ListTasks(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) changeStatus(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.ChangeStatusRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.TaskId = r.PathValue("id")
+	resp, err := h.client.ChangeStatus(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) createProject(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.CreateProjectRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	resp, err := h.client.CreateProject(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusCreated, resp.Project)
+}
+
+func (h *TaskHandler) listProjects(w http.ResponseWriter, r *http.Request) {
+	page, pageSize, err := ReadPagination(r)
+	if err != nil {
+		WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := h.client.ListProjects(OutgoingContext(r), &taskpb.ListProjectsRequest{Page: page, PageSize: pageSize})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) addMember(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.AddMemberRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.ProjectId = r.PathValue("id")
+	resp, err := h.client.AddMember(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) createColumn(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.CreateColumnRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.ProjectId = r.PathValue("id")
+	resp, err := h.client.CreateColumn(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusCreated, resp.Column)
+}
+
+func (h *TaskHandler) listColumns(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.client.ListColumns(OutgoingContext(r), &taskpb.ListColumnsRequest{ProjectId: r.PathValue("id")})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) updateColumn(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.UpdateColumnRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.ColumnId = r.PathValue("id")
+	resp, err := h.client.UpdateColumn(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp.Column)
+}
+
+func (h *TaskHandler) deleteColumn(w http.ResponseWriter, r *http.Request) {
+	_, err := h.client.DeleteColumn(OutgoingContext(r), &taskpb.DeleteColumnRequest{ColumnId: r.PathValue("id")})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TaskHandler) moveTask(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.MoveTaskRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.TaskId = r.PathValue("id")
+	resp, err := h.client.MoveTask(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp.Task)
+}
+
+func (h *TaskHandler) addComment(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.AddCommentRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.TaskId = r.PathValue("id")
+	resp, err := h.client.AddComment(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusCreated, resp.Comment)
+}
+
+func (h *TaskHandler) listComments(w http.ResponseWriter, r *http.Request) {
+	pageToken, pageSize, err := ReadPageTokenSize(r)
+	if err != nil {
+		WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req := &taskpb.ListCommentsRequest{
+		TaskId:         r.PathValue("id"),
+		PageToken:      pageToken,
+		PageSize:       pageSize,
+		IncludeHistory: r.URL.Query().Get("include_history") == "true",
+	}
+	resp, err := h.client.ListComments(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) editComment(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.EditCommentRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.CommentId = r.PathValue("id")
+	resp, err := h.client.EditComment(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp.Comment)
+}
+
+func (h *TaskHandler) deleteComment(w http.ResponseWriter, r *http.Request) {
+	_, err := h.client.DeleteComment(OutgoingContext(r), &taskpb.DeleteCommentRequest{CommentId: r.PathValue("id")})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TaskHandler) getTaskHistory(w http.ResponseWriter, r *http.Request) {
+	pageToken, pageSize, err := ReadPageTokenSize(r)
+	if err != nil {
+		WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req := &taskpb.GetTaskHistoryRequest{TaskId: r.PathValue("id"), PageToken: pageToken, PageSize: pageSize}
+	resp, err := h.client.GetTaskHistory(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) setTaskParent(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.SetTaskParentRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.TaskId = r.PathValue("id")
+	resp, err := h.client.SetTaskParent(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp.Task)
+}
+
+func (h *TaskHandler) addDependency(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.AddDependencyRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.TaskId = r.PathValue("id")
+	_, err := h.client.AddDependency(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TaskHandler) listDependencies(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.client.ListDependencies(OutgoingContext(r), &taskpb.ListDependenciesRequest{TaskId: r.PathValue("id")})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) removeDependency(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.RemoveDependencyRequest{TaskId: r.PathValue("id"), BlockerId: r.PathValue("blocker_id")}
+	_, err := h.client.RemoveDependency(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TaskHandler) archiveTask(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.client.ArchiveTask(OutgoingContext(r), &taskpb.ArchiveTaskRequest{TaskId: r.PathValue("id")})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp.Task)
+}
+
+func (h *TaskHandler) unarchiveTask(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.client.UnarchiveTask(OutgoingContext(r), &taskpb.UnarchiveTaskRequest{TaskId: r.PathValue("id")})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp.Task)
+}
+
+func (h *TaskHandler) restoreTask(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.client.RestoreTask(OutgoingContext(r), &taskpb.RestoreTaskRequest{TaskId: r.PathValue("id")})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp.Task)
+}
+
+// batchUpdateTasks отвечает 200 и при ошибках отдельных задач: их коды — в results
+func (h *TaskHandler) batchUpdateTasks(w http.ResponseWriter, r *http.Request) {
+	req := &taskpb.BatchUpdateTasksRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	resp, err := h.client.BatchUpdateTasks(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"net/http"
+
+	userpb "user-service/proto"
+)
+
+// UserHandler транслирует REST-запросы /user/* в вызовы UserService
+type UserHandler struct {
+	client userpb.UserServiceClient
+}
+
+// NewUserHandler возвращает http.Handler для маршрутов /user/*
+func NewUserHandler(client userpb.UserServiceClient) http.Handler {
+	h := &UserHandler{client: client}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /user/register", h.register)
+	mux.HandleFunc("POST /user/login", h.login)
+	mux.HandleFunc("POST /user/token/refresh", h.refreshToken)
+	mux.HandleFunc("POST /user/logout", h.logout)
+	mux.HandleFunc("POST /user/confirm-email", h.confirmEmail)
+	mux.HandleFunc("POST /user/confirm-email/resend", h.resendConfirmation)
+	mux.HandleFunc("POST /user/password-reset", h.requestPasswordReset)
+	mux.HandleFunc("POST /user/password-reset/confirm", h.resetPassword)
+	mux.HandleFunc("GET /user/profile/{id}", h.getProfile)
+	mux.HandleFunc("PUT /user/profile/{id}", h.updateUser)
+	mux.HandleFunc("DELETE /user/profile/{id}", h.deleteUser)
+	mux.HandleFunc("GET /user/users", h.listUsers)
+	mux.HandleFunc("POST /user/users/{id}/revoke-sessions", h.revokeUserSessions)
+	mux.HandleFunc("PUT /user/users/{id}/role", h.setUserRole)
+	mux.HandleFunc("GET /user/roles", h.listRoles)
+	mux.HandleFunc("PUT /user/roles/{name}", h.upsertRole)
+	mux.HandleFunc("DELETE /user/roles/{name}", h.deleteRole)
+	return mux
+}
+
+func (h *UserHandler) register(w http.ResponseWriter, r *http.Request) {
+	req := &userpb.RegisterRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	resp, err := h.client.Register(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusCreated, resp)
+}
+
+func (h *UserHandler) login(w http.ResponseWriter, r *http.Request) {
+	req := &userpb.LoginRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	resp, err := h.client.Login(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *UserHandler) refreshToken(w http.ResponseWriter, r *http.Request) {
+	req := &userpb.RefreshTokenRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	resp, err := h.client.RefreshToken(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *UserHandler) logout(w http.ResponseWriter, r *http.Request) {
+	req := &userpb.LogoutRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if _, err := h.client.Logout(OutgoingContext(r), req); err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) confirmEmail(w http.ResponseWriter, r *http.Request) {
+	req := &userpb.ConfirmEmailRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	resp, err := h.client.ConfirmEmail(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	if !resp.Success {
+		WriteJSONError(w, http.StatusBadRequest, resp.Message)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *UserHandler) resendConfirmation(w http.ResponseWriter, r *http.Request) {
+	req := &userpb.ResendConfirmationRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	resp, err := h.client.ResendConfirmation(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	if !resp.Success {
+		WriteJSONError(w, http.StatusBadRequest, resp.Message)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *UserHandler) requestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	req := &userpb.RequestPasswordResetRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	resp, err := h.client.RequestPasswordReset(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	if !resp.Success {
+		WriteJSONError(w, http.StatusBadRequest, resp.Message)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *UserHandler) resetPassword(w http.ResponseWriter, r *http.Request) {
+	req := &userpb.ResetPasswordRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	resp, err := h.client.ResetPassword(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	if !resp.Success {
+		WriteJSONError(w, http.StatusBadRequest, resp.Message)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *UserHandler) getProfile(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.client.GetProfile(OutgoingContext(r), &userpb.GetProfileRequest{UserId: r.PathValue("id")})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *UserHandler) updateUser(w http.ResponseWriter, r *http.Request) {
+	req := &userpb.UpdateUserRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.UserId = r.PathValue("id")
+	resp, err := h.client.UpdateUser(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *UserHandler) deleteUser(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.client.DeleteUser(OutgoingContext(r), &userpb.DeleteUserRequest{UserId: r.PathValue("id")})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	if !resp.Success {
+		WriteJSONError(w, http.StatusInternalServerError, "failed to delete user")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) listUsers(w http.ResponseWriter, r *http.Request) {
+	page, pageSize, err := ReadPagination(r)
+	if err != nil {
+		WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageToken, includeTotal, err := ReadPageToken(r)
+	if err != nil {
+		WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req := &userpb.ListUsersRequest{Page: page, PageSize: pageSize, PageToken: pageToken, IncludeTotal: includeTotal}
+	resp, err := h.client.ListUsers(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *UserHandler) revokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	req := &userpb.RevokeUserSessionsRequest{UserId: r.PathValue("id")}
+	if _, err := h.client.RevokeUserSessions(OutgoingContext(r), req); err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) setUserRole(w http.ResponseWriter, r *http.Request) {
+	req := &userpb.SetUserRoleRequest{}
+	if err := ReadProtoJSON(r, req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.UserId = r.PathValue("id")
+	resp, err := h.client.SetUserRole(OutgoingContext(r), req)
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *UserHandler) listRoles(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.client.ListRoles(OutgoingContext(r), &userpb.ListRolesRequest{})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *UserHandler) upsertRole(w http.ResponseWriter, r *http.Request) {
+	role := &userpb.RoleInfo{}
+	if err := ReadProtoJSON(r, role); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	role.Name = r.PathValue("name")
+	resp, err := h.client.UpsertRole(OutgoingContext(r), &userpb.UpsertRoleRequest{Role: role})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	WriteProtoJSON(w, http.StatusOK, resp)
+}
+
+func (h *UserHandler) deleteRole(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.client.DeleteRole(OutgoingContext(r), &userpb.DeleteRoleRequest{Name: r.PathValue("name")})
+	if err != nil {
+		WriteGRPCError(w, err)
+		return
+	}
+	if !resp.Success {
+		WriteJSONError(w, http.StatusInternalServerError, "failed to delete role")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -7,6 +7,13 @@ import (
 
 	"api-gateway/handlers"
 	"api-gateway/middlewares"
+	"shared/jwks"
+	"shared/revocation"
+	taskpb "task-service/proto"
+	userpb "user-service/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
@@ -14,16 +21,51 @@ func main() {
 	if v := os.Getenv("GATEWAY_PORT"); v != "" {
 		addr = ":" + v
 	}
+	if jwksURL := os.Getenv("JWKS_URL"); jwksURL != "" {
+		middlewares.SetJWKS(jwks.NewKeySet(jwksURL))
+	} else {
+		jwtSecret := os.Getenv("JWT_SECRET")
+		if jwtSecret == "" {
+			log.Fatal("JWKS_URL или JWT_SECRET должен быть задан в переменных окружения")
+		}
+		middlewares.SetJWTSecret(jwtSecret)
+	}
+	if revocationsURL := os.Getenv("REVOCATIONS_URL"); revocationsURL != "" {
+		middlewares.SetRevocations(revocation.NewList(revocationsURL))
+	}
+
 	mux := http.NewServeMux()
 
 	// Healthcheck endpoint
 	mux.HandleFunc("/health", handlers.HealthHandler)
 
-	// /user/* с JWT и rate limiting
-	userHandler := handlers.NewUserProxy()
+	// /user/* — REST поверх gRPC UserService, с JWT и rate limiting
+	userAddr := "user-service:50051"
+	if v := os.Getenv("USER_SERVICE_ADDR"); v != "" {
+		userAddr = v
+	}
+	userConn, err := grpc.NewClient(userAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("failed to connect to user-service: %v", err)
+	}
+	defer userConn.Close()
+	userHandler := handlers.NewUserHandler(userpb.NewUserServiceClient(userConn))
 	mux.Handle("/user/", middlewares.JWTMiddleware(middlewares.RateLimitMiddleware(userHandler)))
 
-	// Можно добавить другие сервисы: /task/, /chat/ и т.д.
+	// /task/* — REST поверх gRPC TaskService
+	taskAddr := "task-service:50052"
+	if v := os.Getenv("TASK_SERVICE_ADDR"); v != "" {
+		taskAddr = v
+	}
+	taskConn, err := grpc.NewClient(taskAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("failed to connect to task-service: %v", err)
+	}
+	defer taskConn.Close()
+	taskHandler := handlers.NewTaskHandler(taskpb.NewTaskServiceClient(taskConn))
+	mux.Handle("/task/", middlewares.JWTMiddleware(middlewares.RateLimitMiddleware(taskHandler)))
+
+	// Можно добавить другие сервисы: /chat/ и т.д.
 
 	// Оборачиваем всё в CORS
 	handler := middlewares.CORSMiddleware(mux)
@@ -1,19 +1,62 @@
 package middlewares
 
 import (
-	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
-	"time"
 
 	"api-gateway/handlers"
+	"shared/jwks"
+	"shared/revocation"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWTMiddleware проверяет JWT-токен (демо-реализация, без подписи)
+// PublicPaths — эндпоинты, доступные без JWT (регистрация, вход, подтверждение email, сброс пароля)
+var PublicPaths = map[string]bool{
+	"/user/register":               true,
+	"/user/login":                  true,
+	"/user/token/refresh":          true,
+	"/user/confirm-email":          true,
+	"/user/confirm-email/resend":   true,
+	"/user/password-reset":         true,
+	"/user/password-reset/confirm": true,
+}
+
+// AllowedAlgorithms — допустимые алгоритмы подписи для HS256-режима; всё остальное (включая "none") отклоняется
+var AllowedAlgorithms = []string{jwt.SigningMethodHS256.Alg()}
+
+// AsymmetricAlgorithms — допустимые алгоритмы подписи при проверке по JWKS
+var AsymmetricAlgorithms = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
+var jwtSecret []byte
+var jwtKeys *jwks.KeySet
+var revocations *revocation.List
+
+// SetJWTSecret задаёт общий с сервисами секрет для проверки подписи HS256
+func SetJWTSecret(secret string) {
+	jwtSecret = []byte(secret)
+}
+
+// SetJWKS включает проверку подписи публичными ключами user-service (RS256/EdDSA);
+// nil возвращает проверку по общему секрету
+func SetJWKS(keys *jwks.KeySet) {
+	jwtKeys = keys
+}
+
+// SetRevocations включает отклонение токенов из списка отзыва user-service; nil отключает проверку
+func SetRevocations(list *revocation.List) {
+	revocations = list
+}
+
+// JWTMiddleware проверяет подпись и срок действия JWT и кладёт user_id/role в контекст запроса
 func JWTMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if strings.HasPrefix(r.URL.Path, "/user/") {
+		if PublicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/user/") || strings.HasPrefix(r.URL.Path, "/task/") {
 			token := ""
 			auth := r.Header.Get("Authorization")
 			if strings.HasPrefix(auth, "Bearer ") {
@@ -23,25 +66,60 @@ func JWTMiddleware(next http.Handler) http.Handler {
 				handlers.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized: no token")
 				return
 			}
-			parts := strings.Split(token, ".")
-			if len(parts) != 3 {
-				handlers.WriteJSONError(w, http.StatusUnauthorized, "Invalid token format")
-				return
-			}
-			payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+			identity, err := verifyToken(token)
 			if err != nil {
-				handlers.WriteJSONError(w, http.StatusUnauthorized, "Invalid token payload")
+				handlers.WriteJSONError(w, http.StatusUnauthorized, err.Error())
 				return
 			}
-			var claims map[string]interface{}
-			_ = json.Unmarshal(payload, &claims)
-			if exp, ok := claims["exp"].(float64); ok {
-				if int64(exp) < time.Now().Unix() {
-					handlers.WriteJSONError(w, http.StatusUnauthorized, "Token expired")
-					return
-				}
+			if identity.Unconfirmed && !strings.HasPrefix(r.URL.Path, "/user/") {
+				handlers.WriteJSONError(w, http.StatusForbidden, "Email not confirmed")
+				return
 			}
+			r = r.WithContext(handlers.WithIdentity(r.Context(), identity))
 		}
 		next.ServeHTTP(w, r)
 	})
 }
+
+func verifyToken(tokenStr string) (handlers.Identity, error) {
+	if len(strings.Split(tokenStr, ".")) != 3 {
+		return handlers.Identity{}, errors.New("Invalid token format")
+	}
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	}
+	methods := AllowedAlgorithms
+	if jwtKeys != nil {
+		keyFunc = func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return jwtKeys.Key(kid)
+		}
+		methods = AsymmetricAlgorithms
+	} else if len(jwtSecret) == 0 {
+		return handlers.Identity{}, errors.New("Invalid token")
+	}
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, keyFunc, jwt.WithValidMethods(methods))
+	if err != nil || !token.Valid {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return handlers.Identity{}, errors.New("Token expired")
+		}
+		return handlers.Identity{}, errors.New("Invalid token")
+	}
+	if exp, _ := claims.GetExpirationTime(); exp == nil {
+		return handlers.Identity{}, errors.New("Invalid token: no exp")
+	}
+	userID, _ := claims["user_id"].(string)
+	if userID == "" {
+		return handlers.Identity{}, errors.New("Invalid token: no user_id")
+	}
+	if revocations != nil {
+		jti, _ := claims["jti"].(string)
+		if revocations.IsRevoked(jti, userID, revocation.IssuedAt(claims)) {
+			return handlers.Identity{}, errors.New("Token revoked")
+		}
+	}
+	role, _ := claims["role"].(string)
+	verified, ok := claims["email_verified"].(bool)
+	return handlers.Identity{UserID: userID, Role: role, Unconfirmed: ok && !verified}, nil
+}
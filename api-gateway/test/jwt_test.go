@@ -1,14 +1,34 @@
 package test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"api-gateway/handlers"
 	"api-gateway/middlewares"
+	"shared/jwks"
+	"shared/revocation"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
+const testSecret = "testsecret"
+
+// makeJWT подписывает токен тестовым секретом
+func makeJWT(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	tok, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign jwt: %v", err)
+	}
+	return tok
+}
+
 type errorResp struct {
 	Error string `json:"error"`
 }
@@ -76,3 +96,188 @@ func TestJWTMiddleware_ExpiredToken(t *testing.T) {
 		t.Error("expected error message in JSON response")
 	}
 }
+
+func TestJWTMiddleware_PublicPath(t *testing.T) {
+	h := middlewares.JWTMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("POST", "/user/login", nil)
+	rw := httptest.NewRecorder()
+
+	h.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusOK {
+		t.Errorf("expected 200 OK for public path, got %d", rw.Code)
+	}
+}
+
+func TestJWTMiddleware_ValidToken(t *testing.T) {
+	middlewares.SetJWTSecret(testSecret)
+	var identity handlers.Identity
+	h := middlewares.JWTMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ = handlers.IdentityFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	token := makeJWT(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{
+		"user_id": "user-1",
+		"role":    "admin",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	req := httptest.NewRequest("GET", "/task/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rw := httptest.NewRecorder()
+
+	h.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	if identity.UserID != "user-1" || identity.Role != "admin" {
+		t.Errorf("unexpected identity in context: %+v", identity)
+	}
+}
+
+func TestJWTMiddleware_RejectsForgedTokens(t *testing.T) {
+	middlewares.SetJWTSecret(testSecret)
+	h := middlewares.JWTMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	claims := jwt.MapClaims{"user_id": "user-1", "exp": time.Now().Add(time.Hour).Unix()}
+
+	// alg: none без подписи
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	payload, _ := json.Marshal(claims)
+	noneToken := header + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+
+	tokens := map[string]string{
+		"wrong secret": makeJWT(t, jwt.SigningMethodHS256, []byte("othersecret"), claims),
+		"alg none":     noneToken,
+		"HS512":        makeJWT(t, jwt.SigningMethodHS512, []byte(testSecret), claims),
+		"no exp":       makeJWT(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{"user_id": "user-1"}),
+	}
+	for name, token := range tokens {
+		req := httptest.NewRequest("GET", "/task/tasks", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rw := httptest.NewRecorder()
+
+		h.ServeHTTP(rw, req)
+
+		if rw.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401 Unauthorized, got %d", name, rw.Code)
+		}
+	}
+}
+
+func TestJWTMiddleware_JWKS(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	keySet, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "OKP", "crv": "Ed25519", "kid": "key-1", "alg": "EdDSA", "use": "sig",
+			"x": base64.RawURLEncoding.EncodeToString(pub),
+		}},
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(keySet)
+	}))
+	defer srv.Close()
+	middlewares.SetJWKS(jwks.NewKeySet(srv.URL))
+	defer middlewares.SetJWKS(nil)
+
+	h := middlewares.JWTMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	claims := jwt.MapClaims{"user_id": "user-1", "exp": time.Now().Add(time.Hour).Unix()}
+	tok := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	tok.Header["kid"] = "key-1"
+	signed, err := tok.SignedString(priv)
+	if err != nil {
+		t.Fatalf("failed to sign jwt: %v", err)
+	}
+
+	tokens := map[string]struct {
+		token string
+		code  int
+	}{
+		"eddsa":     {signed, http.StatusOK},
+		"hs256":     {makeJWT(t, jwt.SigningMethodHS256, []byte(testSecret), claims), http.StatusUnauthorized},
+		"wrong kid": {makeJWT(t, jwt.SigningMethodEdDSA, priv, claims), http.StatusUnauthorized},
+	}
+	for name, tt := range tokens {
+		req := httptest.NewRequest("GET", "/task/tasks", nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		rw := httptest.NewRecorder()
+
+		h.ServeHTTP(rw, req)
+
+		if rw.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", name, tt.code, rw.Code)
+		}
+	}
+}
+
+func TestJWTMiddleware_RevokedToken(t *testing.T) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"tokens": []map[string]interface{}{{"jti": "revoked-jti", "expires_at": time.Now().Add(time.Hour).Unix()}},
+		"users":  []map[string]interface{}{},
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer srv.Close()
+	middlewares.SetJWTSecret(testSecret)
+	middlewares.SetRevocations(revocation.NewList(srv.URL))
+	defer middlewares.SetRevocations(nil)
+
+	h := middlewares.JWTMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	tokens := map[string]int{
+		"ok-jti":      http.StatusOK,
+		"revoked-jti": http.StatusUnauthorized,
+	}
+	for jti, code := range tokens {
+		token := makeJWT(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{
+			"user_id": "user-1",
+			"jti":     jti,
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		})
+		req := httptest.NewRequest("GET", "/task/tasks", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rw := httptest.NewRecorder()
+
+		h.ServeHTTP(rw, req)
+
+		if rw.Code != code {
+			t.Errorf("%s: expected %d, got %d", jti, code, rw.Code)
+		}
+	}
+}
+
+func TestJWTMiddleware_UnconfirmedEmail(t *testing.T) {
+	middlewares.SetJWTSecret(testSecret)
+	h := middlewares.JWTMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	token := makeJWT(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{
+		"user_id":        "user-1",
+		"email_verified": false,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	})
+	paths := map[string]int{
+		"/user/profile/user-1": http.StatusOK,
+		"/task/tasks":          http.StatusForbidden,
+	}
+	for path, code := range paths {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rw := httptest.NewRecorder()
+
+		h.ServeHTTP(rw, req)
+
+		if rw.Code != code {
+			t.Errorf("%s: expected %d, got %d", path, code, rw.Code)
+		}
+	}
+}
@@ -0,0 +1,730 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"api-gateway/handlers"
+	taskpb "task-service/proto"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// fakeTaskClient — заглушка TaskServiceClient, запоминающая последний запрос
+type fakeTaskClient struct {
+	lastReq interface{}
+	lastMD  metadata.MD
+	err     error
+}
+
+func (f *fakeTaskClient) record(ctx context.Context, req interface{}) {
+	f.lastReq = req
+	f.lastMD, _ = metadata.FromOutgoingContext(ctx)
+}
+
+func (f *fakeTaskClient) CreateTask(ctx context.Context, in *taskpb.CreateTaskRequest, _ ...grpc.CallOption) (*taskpb.CreateTaskResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.CreateTaskResponse{TaskId: "task-1"}, nil
+}
+
+func (f *fakeTaskClient) GetTask(ctx context.Context, in *taskpb.GetTaskRequest, _ ...grpc.CallOption) (*taskpb.GetTaskResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.GetTaskResponse{Task: &taskpb.Task{Id: in.TaskId, Title: "Test", Version: 3}}, nil
+}
+
+func (f *fakeTaskClient) UpdateTask(ctx context.Context, in *taskpb.UpdateTaskRequest, _ ...grpc.CallOption) (*taskpb.UpdateTaskResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.UpdateTaskResponse{TaskId: in.TaskId, Version: 4}, nil
+}
+
+func (f *fakeTaskClient) DeleteTask(ctx context.Context, in *taskpb.DeleteTaskRequest, _ ...grpc.CallOption) (*taskpb.DeleteTaskResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.DeleteTaskResponse{Success: true}, nil
+}
+
+func (f *fakeTaskClient) ListTasks(ctx context.Context, in *taskpb.ListTasksRequest, _ ...grpc.CallOption) (*taskpb.ListTasksResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.ListTasksResponse{Tasks: []*taskpb.Task{{Id: "task-1"}}, Total: 1}, nil
+}
+
+func (f *fakeTaskClient) CreateProject(ctx context.Context, in *taskpb.CreateProjectRequest, _ ...grpc.CallOption) (*taskpb.CreateProjectResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.CreateProjectResponse{Project: &taskpb.Project{Id: "project-1", Name: in.Name}}, nil
+}
+
+func (f *fakeTaskClient) ListProjects(ctx context.Context, in *taskpb.ListProjectsRequest, _ ...grpc.CallOption) (*taskpb.ListProjectsResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.ListProjectsResponse{Projects: []*taskpb.Project{{Id: "project-1"}}, Total: 1}, nil
+}
+
+func (f *fakeTaskClient) AddMember(ctx context.Context, in *taskpb.AddMemberRequest, _ ...grpc.CallOption) (*taskpb.AddMemberResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.AddMemberResponse{ProjectId: in.ProjectId, UserId: in.UserId, Role: in.Role}, nil
+}
+
+func (f *fakeTaskClient) CreateColumn(ctx context.Context, in *taskpb.CreateColumnRequest, _ ...grpc.CallOption) (*taskpb.CreateColumnResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.CreateColumnResponse{Column: &taskpb.Column{Id: "column-1", ProjectId: in.ProjectId, Name: in.Name, WipLimit: in.WipLimit}}, nil
+}
+
+func (f *fakeTaskClient) UpdateColumn(ctx context.Context, in *taskpb.UpdateColumnRequest, _ ...grpc.CallOption) (*taskpb.UpdateColumnResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.UpdateColumnResponse{Column: &taskpb.Column{Id: in.ColumnId, Name: in.Name, WipLimit: in.WipLimit}}, nil
+}
+
+func (f *fakeTaskClient) DeleteColumn(ctx context.Context, in *taskpb.DeleteColumnRequest, _ ...grpc.CallOption) (*taskpb.DeleteColumnResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.DeleteColumnResponse{Success: true}, nil
+}
+
+func (f *fakeTaskClient) ListColumns(ctx context.Context, in *taskpb.ListColumnsRequest, _ ...grpc.CallOption) (*taskpb.ListColumnsResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.ListColumnsResponse{Columns: []*taskpb.Column{{Id: "column-1", ProjectId: in.ProjectId}}}, nil
+}
+
+func (f *fakeTaskClient) MoveTask(ctx context.Context, in *taskpb.MoveTaskRequest, _ ...grpc.CallOption) (*taskpb.MoveTaskResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.MoveTaskResponse{Task: &taskpb.Task{Id: in.TaskId, ColumnId: in.ColumnId, Rank: "i"}}, nil
+}
+
+func (f *fakeTaskClient) AddComment(ctx context.Context, in *taskpb.AddCommentRequest, _ ...grpc.CallOption) (*taskpb.AddCommentResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.AddCommentResponse{Comment: &taskpb.Comment{Id: "comment-1", TaskId: in.TaskId, Body: in.Body}}, nil
+}
+
+func (f *fakeTaskClient) ListComments(ctx context.Context, in *taskpb.ListCommentsRequest, _ ...grpc.CallOption) (*taskpb.ListCommentsResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.ListCommentsResponse{Comments: []*taskpb.Comment{{Id: "comment-1"}}, NextPageToken: "next"}, nil
+}
+
+func (f *fakeTaskClient) EditComment(ctx context.Context, in *taskpb.EditCommentRequest, _ ...grpc.CallOption) (*taskpb.EditCommentResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.EditCommentResponse{Comment: &taskpb.Comment{Id: in.CommentId, Body: in.Body}}, nil
+}
+
+func (f *fakeTaskClient) DeleteComment(ctx context.Context, in *taskpb.DeleteCommentRequest, _ ...grpc.CallOption) (*taskpb.DeleteCommentResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.DeleteCommentResponse{Success: true}, nil
+}
+
+func (f *fakeTaskClient) GetTaskHistory(ctx context.Context, in *taskpb.GetTaskHistoryRequest, _ ...grpc.CallOption) (*taskpb.GetTaskHistoryResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.GetTaskHistoryResponse{Entries: []*taskpb.ActivityEntry{{TaskId: in.TaskId, Action: "create"}}}, nil
+}
+
+func (f *fakeTaskClient) SetTaskParent(ctx context.Context, in *taskpb.SetTaskParentRequest, _ ...grpc.CallOption) (*taskpb.SetTaskParentResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.SetTaskParentResponse{Task: &taskpb.Task{Id: in.TaskId, ParentId: in.ParentId}}, nil
+}
+
+func (f *fakeTaskClient) AddDependency(ctx context.Context, in *taskpb.AddDependencyRequest, _ ...grpc.CallOption) (*taskpb.AddDependencyResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.AddDependencyResponse{Success: true}, nil
+}
+
+func (f *fakeTaskClient) RemoveDependency(ctx context.Context, in *taskpb.RemoveDependencyRequest, _ ...grpc.CallOption) (*taskpb.RemoveDependencyResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.RemoveDependencyResponse{Success: true}, nil
+}
+
+func (f *fakeTaskClient) ListDependencies(ctx context.Context, in *taskpb.ListDependenciesRequest, _ ...grpc.CallOption) (*taskpb.ListDependenciesResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.ListDependenciesResponse{BlockedBy: []*taskpb.Task{{Id: "blocker-1"}}}, nil
+}
+
+func (f *fakeTaskClient) ArchiveTask(ctx context.Context, in *taskpb.ArchiveTaskRequest, _ ...grpc.CallOption) (*taskpb.ArchiveTaskResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.ArchiveTaskResponse{Task: &taskpb.Task{Id: in.TaskId, ArchivedAt: "2026-01-01T00:00:00Z"}}, nil
+}
+
+func (f *fakeTaskClient) UnarchiveTask(ctx context.Context, in *taskpb.UnarchiveTaskRequest, _ ...grpc.CallOption) (*taskpb.UnarchiveTaskResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.UnarchiveTaskResponse{Task: &taskpb.Task{Id: in.TaskId}}, nil
+}
+
+func (f *fakeTaskClient) RestoreTask(ctx context.Context, in *taskpb.RestoreTaskRequest, _ ...grpc.CallOption) (*taskpb.RestoreTaskResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.RestoreTaskResponse{Task: &taskpb.Task{Id: in.TaskId}}, nil
+}
+
+func (f *fakeTaskClient) BatchUpdateTasks(ctx context.Context, in *taskpb.BatchUpdateTasksRequest, _ ...grpc.CallOption) (*taskpb.BatchUpdateTasksResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := &taskpb.BatchUpdateTasksResponse{}
+	for _, id := range in.TaskIds {
+		resp.Results = append(resp.Results, &taskpb.BatchUpdateTaskResult{TaskId: id, Code: int32(codes.PermissionDenied), Message: "forbidden"})
+	}
+	return resp, nil
+}
+
+func (f *fakeTaskClient) ChangeStatus(ctx context.Context, in *taskpb.ChangeStatusRequest, _ ...grpc.CallOption) (*taskpb.ChangeStatusResponse, error) {
+	f.record(ctx, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &taskpb.ChangeStatusResponse{Success: true}, nil
+}
+
+func (f *fakeTaskClient) HealthCheck(ctx context.Context, in *emptypb.Empty, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+func TestTaskHandler_Create(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	req := httptest.NewRequest("POST", "/task/tasks", strings.NewReader(`{"title":"Test","labels":["a"]}`))
+	req.Header.Set("Authorization", "Bearer token")
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d", rw.Code)
+	}
+	in, ok := client.lastReq.(*taskpb.CreateTaskRequest)
+	if !ok || in.Title != "Test" || len(in.Labels) != 1 {
+		t.Errorf("unexpected grpc request: %v", client.lastReq)
+	}
+	if got := client.lastMD.Get("authorization"); len(got) == 0 || got[0] != "Bearer token" {
+		t.Errorf("authorization not forwarded, got %v", got)
+	}
+	if got := client.lastMD.Get("x-user-id"); len(got) != 0 {
+		t.Errorf("x-user-id must not be set without verified identity, got %v", got)
+	}
+	var resp map[string]interface{}
+	_ = json.Unmarshal(rw.Body.Bytes(), &resp)
+	if resp["task_id"] != "task-1" {
+		t.Errorf("expected task_id in response, got %s", rw.Body.String())
+	}
+}
+
+func TestTaskHandler_UpdateUsesPathID(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	req := httptest.NewRequest("PATCH", "/task/tasks/abc", strings.NewReader(`{"task_id":"other","title":"New"}`))
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	in := client.lastReq.(*taskpb.UpdateTaskRequest)
+	if in.TaskId != "abc" || in.Title != "New" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+}
+
+func TestTaskHandler_UpdateIfMatch(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/tasks/abc", nil))
+	if rw.Code != http.StatusOK || rw.Header().Get("ETag") != `"3"` {
+		t.Fatalf("expected 200 with ETag \"3\", got %d %q", rw.Code, rw.Header().Get("ETag"))
+	}
+
+	req := httptest.NewRequest("PATCH", "/task/tasks/abc", strings.NewReader(`{"title":"New"}`))
+	req.Header.Set("If-Match", `"3"`)
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK || rw.Header().Get("ETag") != `"4"` {
+		t.Fatalf("expected 200 with ETag \"4\", got %d %q", rw.Code, rw.Header().Get("ETag"))
+	}
+	if in := client.lastReq.(*taskpb.UpdateTaskRequest); in.ExpectedVersion != 3 {
+		t.Errorf("expected expected_version 3 from If-Match, got %v", in)
+	}
+
+	req = httptest.NewRequest("PATCH", "/task/tasks/abc", strings.NewReader(`{"title":"New"}`))
+	req.Header.Set("If-Match", "3")
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid If-Match, got %d", rw.Code)
+	}
+
+	mismatch, _ := status.New(codes.FailedPrecondition, "task version mismatch").WithDetails(&errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{Type: "VERSION", Subject: "task/abc"}},
+	})
+	client.err = mismatch.Err()
+	req = httptest.NewRequest("PATCH", "/task/tasks/abc", strings.NewReader(`{"title":"New","expected_version":2}`))
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected 412 for a stale version, got %d", rw.Code)
+	}
+
+	// прочие FailedPrecondition (например, запрещённый переход статуса) — 400
+	client.err = status.Error(codes.FailedPrecondition, "status transition not allowed: todo -> backlog")
+	req = httptest.NewRequest("PATCH", "/task/tasks/abc", strings.NewReader(`{"status":"backlog","expected_version":3}`))
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a disallowed transition, got %d", rw.Code)
+	}
+}
+
+func TestTaskHandler_UpdateMask(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("PATCH", "/task/tasks/abc?update_mask=title,due_date", strings.NewReader(`{"title":"New"}`)))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*taskpb.UpdateTaskRequest); strings.Join(in.GetUpdateMask().GetPaths(), ",") != "title,due_date" {
+		t.Errorf("unexpected update mask: %v", in.UpdateMask)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("PATCH", "/task/tasks/abc", strings.NewReader(`{"update_mask":"assigneeId,dueDate"}`)))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*taskpb.UpdateTaskRequest); strings.Join(in.GetUpdateMask().GetPaths(), ",") != "assignee_id,due_date" {
+		t.Errorf("unexpected update mask: %v", in.UpdateMask)
+	}
+}
+
+func TestTaskHandler_ListQuery(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	req := httptest.NewRequest("GET", "/task/tasks?status=done&project_id=p1&page=2&page_size=5", nil)
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	in := client.lastReq.(*taskpb.ListTasksRequest)
+	if in.Status != "done" || in.ProjectId != "p1" || in.Page != 2 || in.PageSize != 5 {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	req = httptest.NewRequest("GET", "/task/tasks?page=abc", nil)
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid page, got %d", rw.Code)
+	}
+}
+
+func TestTaskHandler_ListFilters(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/tasks?creator_id=u1&labels=bug,auth&labels=docs&labels_match=all"+
+		"&due_from=2026-01-01T00:00:00Z&due_to=2026-02-01T00:00:00Z&overdue=true&search=login&sort=-due_date,title", nil))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d %s", rw.Code, rw.Body.String())
+	}
+	in := client.lastReq.(*taskpb.ListTasksRequest)
+	if in.CreatorId != "u1" || strings.Join(in.Labels, "|") != "bug|auth|docs" || in.LabelsMatch != "all" ||
+		in.DueFrom != "2026-01-01T00:00:00Z" || in.DueTo != "2026-02-01T00:00:00Z" || !in.Overdue ||
+		in.Search != "login" || in.Sort != "-due_date,title" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/tasks?overdue=maybe", nil))
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid overdue, got %d", rw.Code)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/tasks?page_token=abc.def&include_total=1", nil))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*taskpb.ListTasksRequest); in.PageToken != "abc.def" || !in.IncludeTotal {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/tasks?include_total=maybe", nil))
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid include_total, got %d", rw.Code)
+	}
+}
+
+func TestTaskHandler_Projects(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("POST", "/task/projects", strings.NewReader(`{"name":"Board"}`)))
+	if rw.Code != http.StatusCreated || !strings.Contains(rw.Body.String(), `"id":"project-1"`) {
+		t.Fatalf("expected 201 with project, got %d %s", rw.Code, rw.Body.String())
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/projects?page=1&page_size=10", nil))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("POST", "/task/projects/project-1/members", strings.NewReader(`{"user_id":"u2","role":"viewer"}`)))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*taskpb.AddMemberRequest); in.ProjectId != "project-1" || in.UserId != "u2" || in.Role != "viewer" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+}
+
+func TestTaskHandler_Columns(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("POST", "/task/projects/project-1/columns", strings.NewReader(`{"name":"Doing","wip_limit":3}`)))
+	if rw.Code != http.StatusCreated || !strings.Contains(rw.Body.String(), `"id":"column-1"`) {
+		t.Fatalf("expected 201 with column, got %d %s", rw.Code, rw.Body.String())
+	}
+	if in := client.lastReq.(*taskpb.CreateColumnRequest); in.ProjectId != "project-1" || in.WipLimit != 3 {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/projects/project-1/columns", nil))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("PATCH", "/task/columns/column-1", strings.NewReader(`{"name":"In progress","wip_limit":2}`)))
+	if in := client.lastReq.(*taskpb.UpdateColumnRequest); rw.Code != http.StatusOK || in.ColumnId != "column-1" || in.WipLimit != 2 {
+		t.Errorf("unexpected update: %d %v", rw.Code, in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("DELETE", "/task/columns/column-1", nil))
+	if rw.Code != http.StatusNoContent {
+		t.Errorf("expected 204 No Content, got %d", rw.Code)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("POST", "/task/tasks/abc/move", strings.NewReader(`{"column_id":"column-1","position":2}`)))
+	if in := client.lastReq.(*taskpb.MoveTaskRequest); rw.Code != http.StatusOK || in.TaskId != "abc" || in.ColumnId != "column-1" || in.Position != 2 {
+		t.Errorf("unexpected move: %d %v", rw.Code, in)
+	}
+
+	client.err = status.Error(codes.FailedPrecondition, "column WIP limit exceeded")
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("POST", "/task/tasks/abc/move", strings.NewReader(`{"column_id":"column-1"}`)))
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for WIP limit, got %d", rw.Code)
+	}
+}
+
+func TestTaskHandler_Comments(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("POST", "/task/tasks/abc/comments", strings.NewReader(`{"body":"hello"}`)))
+	if rw.Code != http.StatusCreated || !strings.Contains(rw.Body.String(), `"id":"comment-1"`) {
+		t.Fatalf("expected 201 with comment, got %d %s", rw.Code, rw.Body.String())
+	}
+	if in := client.lastReq.(*taskpb.AddCommentRequest); in.TaskId != "abc" || in.Body != "hello" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/tasks/abc/comments?page_token=xyz&page_size=5&include_history=true", nil))
+	if rw.Code != http.StatusOK || !strings.Contains(rw.Body.String(), `"next_page_token":"next"`) {
+		t.Fatalf("expected 200 with next_page_token, got %d %s", rw.Code, rw.Body.String())
+	}
+	if in := client.lastReq.(*taskpb.ListCommentsRequest); in.TaskId != "abc" || in.PageToken != "xyz" || in.PageSize != 5 || !in.IncludeHistory {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/tasks/abc/comments?page_size=-1", nil))
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid page_size, got %d", rw.Code)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("PATCH", "/task/comments/comment-1", strings.NewReader(`{"body":"edited"}`)))
+	if in := client.lastReq.(*taskpb.EditCommentRequest); rw.Code != http.StatusOK || in.CommentId != "comment-1" || in.Body != "edited" {
+		t.Errorf("unexpected edit: %d %v", rw.Code, in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("DELETE", "/task/comments/comment-1", nil))
+	if rw.Code != http.StatusNoContent {
+		t.Errorf("expected 204 No Content, got %d", rw.Code)
+	}
+}
+
+func TestTaskHandler_History(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/tasks/abc/history?page_token=c1&page_size=10", nil))
+	if rw.Code != http.StatusOK || !strings.Contains(rw.Body.String(), `"action":"create"`) {
+		t.Fatalf("expected 200 with entries, got %d %s", rw.Code, rw.Body.String())
+	}
+	if in := client.lastReq.(*taskpb.GetTaskHistoryRequest); in.TaskId != "abc" || in.PageToken != "c1" || in.PageSize != 10 {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+}
+
+func TestTaskHandler_Dependencies(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("PUT", "/task/tasks/abc/parent", strings.NewReader(`{"parent_id":"p1"}`)))
+	if rw.Code != http.StatusOK || !strings.Contains(rw.Body.String(), `"parent_id":"p1"`) {
+		t.Fatalf("expected 200 with task, got %d %s", rw.Code, rw.Body.String())
+	}
+	if in := client.lastReq.(*taskpb.SetTaskParentRequest); in.TaskId != "abc" || in.ParentId != "p1" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("POST", "/task/tasks/abc/dependencies", strings.NewReader(`{"blocker_id":"b1"}`)))
+	if rw.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d %s", rw.Code, rw.Body.String())
+	}
+	if in := client.lastReq.(*taskpb.AddDependencyRequest); in.TaskId != "abc" || in.BlockerId != "b1" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/tasks/abc/dependencies", nil))
+	if rw.Code != http.StatusOK || !strings.Contains(rw.Body.String(), `"id":"blocker-1"`) {
+		t.Fatalf("expected 200 with dependencies, got %d %s", rw.Code, rw.Body.String())
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("DELETE", "/task/tasks/abc/dependencies/b1", nil))
+	if rw.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*taskpb.RemoveDependencyRequest); in.TaskId != "abc" || in.BlockerId != "b1" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/tasks?parent_id=abc", nil))
+	if in := client.lastReq.(*taskpb.ListTasksRequest); in.ParentId != "abc" {
+		t.Errorf("expected parent_id passed to ListTasks, got %v", in)
+	}
+}
+
+func TestTaskHandler_Delete(t *testing.T) {
+	h := handlers.NewTaskHandler(&fakeTaskClient{})
+
+	req := httptest.NewRequest("DELETE", "/task/tasks/abc", nil)
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusNoContent {
+		t.Errorf("expected 204 No Content, got %d", rw.Code)
+	}
+}
+
+func TestTaskHandler_GRPCErrorMapping(t *testing.T) {
+	tests := []struct {
+		code   codes.Code
+		status int
+	}{
+		{codes.InvalidArgument, http.StatusBadRequest},
+		{codes.Unauthenticated, http.StatusUnauthorized},
+		{codes.PermissionDenied, http.StatusForbidden},
+		{codes.NotFound, http.StatusNotFound},
+		{codes.Internal, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		client := &fakeTaskClient{err: status.Error(tt.code, "boom")}
+		h := handlers.NewTaskHandler(client)
+
+		req := httptest.NewRequest("GET", "/task/tasks/abc", nil)
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+
+		if rw.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.code, tt.status, rw.Code)
+		}
+		var resp errorResp
+		_ = json.Unmarshal(rw.Body.Bytes(), &resp)
+		if resp.Error != "boom" {
+			t.Errorf("%s: expected error message in JSON response, got %q", tt.code, rw.Body.String())
+		}
+	}
+}
+
+func TestTaskHandler_ForwardsIdentity(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	req := httptest.NewRequest("GET", "/task/tasks/abc", nil)
+	req = req.WithContext(handlers.WithIdentity(req.Context(), handlers.Identity{UserID: "user-1", Role: "admin"}))
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+
+	if got := client.lastMD.Get("x-user-id"); len(got) == 0 || got[0] != "user-1" {
+		t.Errorf("expected x-user-id metadata, got %v", got)
+	}
+	if got := client.lastMD.Get("x-user-role"); len(got) == 0 || got[0] != "admin" {
+		t.Errorf("expected x-user-role metadata, got %v", got)
+	}
+}
+
+func TestTaskHandler_ArchiveAndTrash(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("POST", "/task/tasks/abc/archive", nil))
+	if rw.Code != http.StatusOK || !strings.Contains(rw.Body.String(), `"archived_at":"2026-01-01T00:00:00Z"`) {
+		t.Fatalf("expected 200 with archived task, got %d %s", rw.Code, rw.Body.String())
+	}
+	if in := client.lastReq.(*taskpb.ArchiveTaskRequest); in.TaskId != "abc" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("POST", "/task/tasks/abc/unarchive", nil))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*taskpb.UnarchiveTaskRequest); in.TaskId != "abc" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("POST", "/task/tasks/abc/restore", nil))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*taskpb.RestoreTaskRequest); in.TaskId != "abc" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/task/tasks?view=trash", nil))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*taskpb.ListTasksRequest); in.View != "trash" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+}
+
+func TestTaskHandler_BatchUpdate(t *testing.T) {
+	client := &fakeTaskClient{}
+	h := handlers.NewTaskHandler(client)
+
+	body := `{"task_ids":["a","b"],"status":"done","add_labels":["bulk"],"all_or_nothing":true}`
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("POST", "/task/tasks/batch", strings.NewReader(body)))
+	if rw.Code != http.StatusOK || !strings.Contains(rw.Body.String(), `"code":7`) {
+		t.Fatalf("expected 200 with per-task results, got %d %s", rw.Code, rw.Body.String())
+	}
+	in := client.lastReq.(*taskpb.BatchUpdateTasksRequest)
+	if len(in.TaskIds) != 2 || in.Status != "done" || in.AddLabels[0] != "bulk" || !in.AllOrNothing {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	client.err = status.Error(codes.InvalidArgument, "task_ids is required")
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("POST", "/task/tasks/batch", strings.NewReader(`{}`)))
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rw.Code)
+	}
+}
@@ -0,0 +1,338 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"api-gateway/handlers"
+	userpb "user-service/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeUserClient — заглушка UserServiceClient, запоминающая последний запрос
+type fakeUserClient struct {
+	lastReq interface{}
+	err     error
+}
+
+func (f *fakeUserClient) Register(ctx context.Context, in *userpb.RegisterRequest, _ ...grpc.CallOption) (*userpb.RegisterResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.RegisterResponse{UserId: "user-1"}, nil
+}
+
+func (f *fakeUserClient) Login(ctx context.Context, in *userpb.LoginRequest, _ ...grpc.CallOption) (*userpb.LoginResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.LoginResponse{Token: "jwt"}, nil
+}
+
+func (f *fakeUserClient) RefreshToken(ctx context.Context, in *userpb.RefreshTokenRequest, _ ...grpc.CallOption) (*userpb.RefreshTokenResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.RefreshTokenResponse{Token: "jwt2", RefreshToken: "refresh2", ExpiresIn: 900}, nil
+}
+
+func (f *fakeUserClient) Logout(ctx context.Context, in *userpb.LogoutRequest, _ ...grpc.CallOption) (*userpb.LogoutResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.LogoutResponse{Success: true}, nil
+}
+
+func (f *fakeUserClient) RevokeUserSessions(ctx context.Context, in *userpb.RevokeUserSessionsRequest, _ ...grpc.CallOption) (*userpb.RevokeUserSessionsResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.RevokeUserSessionsResponse{Success: true}, nil
+}
+
+func (f *fakeUserClient) SetUserRole(ctx context.Context, in *userpb.SetUserRoleRequest, _ ...grpc.CallOption) (*userpb.SetUserRoleResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.SetUserRoleResponse{UserId: in.UserId, Role: in.Role}, nil
+}
+
+func (f *fakeUserClient) ListRoles(ctx context.Context, in *userpb.ListRolesRequest, _ ...grpc.CallOption) (*userpb.ListRolesResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.ListRolesResponse{Roles: []*userpb.RoleInfo{{Name: "user", Permissions: []string{"task:create"}}}}, nil
+}
+
+func (f *fakeUserClient) UpsertRole(ctx context.Context, in *userpb.UpsertRoleRequest, _ ...grpc.CallOption) (*userpb.UpsertRoleResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.UpsertRoleResponse{Role: in.Role}, nil
+}
+
+func (f *fakeUserClient) DeleteRole(ctx context.Context, in *userpb.DeleteRoleRequest, _ ...grpc.CallOption) (*userpb.DeleteRoleResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.DeleteRoleResponse{Success: true}, nil
+}
+
+func (f *fakeUserClient) GetProfile(ctx context.Context, in *userpb.GetProfileRequest, _ ...grpc.CallOption) (*userpb.GetProfileResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.GetProfileResponse{UserId: in.UserId, Username: "test"}, nil
+}
+
+func (f *fakeUserClient) UpdateUser(ctx context.Context, in *userpb.UpdateUserRequest, _ ...grpc.CallOption) (*userpb.UpdateUserResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.UpdateUserResponse{UserId: in.UserId}, nil
+}
+
+func (f *fakeUserClient) DeleteUser(ctx context.Context, in *userpb.DeleteUserRequest, _ ...grpc.CallOption) (*userpb.DeleteUserResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.DeleteUserResponse{Success: true}, nil
+}
+
+func (f *fakeUserClient) ListUsers(ctx context.Context, in *userpb.ListUsersRequest, _ ...grpc.CallOption) (*userpb.ListUsersResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.ListUsersResponse{Users: []*userpb.UserInfo{{UserId: "user-1"}}, Total: 1}, nil
+}
+
+func (f *fakeUserClient) ConfirmEmail(ctx context.Context, in *userpb.ConfirmEmailRequest, _ ...grpc.CallOption) (*userpb.ConfirmEmailResponse, error) {
+	f.lastReq = in
+	if in.Token == "" {
+		return &userpb.ConfirmEmailResponse{Success: false, Message: "invalid token or email"}, nil
+	}
+	return &userpb.ConfirmEmailResponse{Success: true, Message: "email confirmed"}, nil
+}
+
+func (f *fakeUserClient) ResendConfirmation(ctx context.Context, in *userpb.ResendConfirmationRequest, _ ...grpc.CallOption) (*userpb.ResendConfirmationResponse, error) {
+	f.lastReq = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &userpb.ResendConfirmationResponse{Success: true, Message: "confirmation email sent"}, nil
+}
+
+func (f *fakeUserClient) RequestPasswordReset(ctx context.Context, in *userpb.RequestPasswordResetRequest, _ ...grpc.CallOption) (*userpb.RequestPasswordResetResponse, error) {
+	f.lastReq = in
+	return &userpb.RequestPasswordResetResponse{Success: true}, nil
+}
+
+func (f *fakeUserClient) ResetPassword(ctx context.Context, in *userpb.ResetPasswordRequest, _ ...grpc.CallOption) (*userpb.ResetPasswordResponse, error) {
+	f.lastReq = in
+	return &userpb.ResetPasswordResponse{Success: true}, nil
+}
+
+func TestUserHandler_RegisterAndLogin(t *testing.T) {
+	client := &fakeUserClient{}
+	h := handlers.NewUserHandler(client)
+
+	req := httptest.NewRequest("POST", "/user/register", strings.NewReader(`{"username":"u","email":"u@example.com","password":"secret1"}`))
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*userpb.RegisterRequest); in.Email != "u@example.com" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	req = httptest.NewRequest("POST", "/user/login", strings.NewReader(`{"email":"u@example.com","password":"secret1"}`))
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	var resp map[string]interface{}
+	_ = json.Unmarshal(rw.Body.Bytes(), &resp)
+	if resp["token"] != "jwt" {
+		t.Errorf("expected token in response, got %s", rw.Body.String())
+	}
+}
+
+func TestUserHandler_InvalidBody(t *testing.T) {
+	h := handlers.NewUserHandler(&fakeUserClient{})
+
+	req := httptest.NewRequest("POST", "/user/login", strings.NewReader(`{not json`))
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 Bad Request, got %d", rw.Code)
+	}
+}
+
+func TestUserHandler_ConfirmEmailFailure(t *testing.T) {
+	h := handlers.NewUserHandler(&fakeUserClient{})
+
+	req := httptest.NewRequest("POST", "/user/confirm-email", strings.NewReader(`{"email":"u@example.com"}`))
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 Bad Request, got %d", rw.Code)
+	}
+	var resp errorResp
+	_ = json.Unmarshal(rw.Body.Bytes(), &resp)
+	if resp.Error != "invalid token or email" {
+		t.Errorf("expected error message in JSON response, got %s", rw.Body.String())
+	}
+}
+
+func TestUserHandler_ResendConfirmation(t *testing.T) {
+	client := &fakeUserClient{}
+	h := handlers.NewUserHandler(client)
+
+	req := httptest.NewRequest("POST", "/user/confirm-email/resend", strings.NewReader(`{"email":"u@example.com"}`))
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*userpb.ResendConfirmationRequest); in.Email != "u@example.com" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+}
+
+func TestUserHandler_ProfileAndList(t *testing.T) {
+	client := &fakeUserClient{}
+	h := handlers.NewUserHandler(client)
+
+	req := httptest.NewRequest("GET", "/user/profile/abc", nil)
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*userpb.GetProfileRequest); in.UserId != "abc" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	req = httptest.NewRequest("GET", "/user/users?page=3", nil)
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*userpb.ListUsersRequest); in.Page != 3 || in.PageSize != 20 {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	req = httptest.NewRequest("GET", "/user/users?page_token=abc.def&include_total=true", nil)
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*userpb.ListUsersRequest); in.PageToken != "abc.def" || !in.IncludeTotal {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+}
+
+func TestUserHandler_LogoutAndRevokeSessions(t *testing.T) {
+	client := &fakeUserClient{}
+	h := handlers.NewUserHandler(client)
+
+	req := httptest.NewRequest("POST", "/user/logout", strings.NewReader(`{"refresh_token":"refresh"}`))
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 No Content, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*userpb.LogoutRequest); in.RefreshToken != "refresh" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	req = httptest.NewRequest("POST", "/user/users/abc/revoke-sessions", nil)
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 No Content, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*userpb.RevokeUserSessionsRequest); in.UserId != "abc" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+}
+
+func TestUserHandler_SetUserRole(t *testing.T) {
+	client := &fakeUserClient{}
+	h := handlers.NewUserHandler(client)
+
+	req := httptest.NewRequest("PUT", "/user/users/abc/role", strings.NewReader(`{"role":"admin"}`))
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*userpb.SetUserRoleRequest); in.UserId != "abc" || in.Role != "admin" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+}
+
+func TestUserHandler_Roles(t *testing.T) {
+	client := &fakeUserClient{}
+	h := handlers.NewUserHandler(client)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/user/roles", nil))
+	if rw.Code != http.StatusOK || !strings.Contains(rw.Body.String(), "task:create") {
+		t.Fatalf("expected role list, got %d %s", rw.Code, rw.Body.String())
+	}
+
+	req := httptest.NewRequest("PUT", "/user/roles/auditor", strings.NewReader(`{"description":"Аудит","permissions":["user:list"]}`))
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*userpb.UpsertRoleRequest); in.Role.Name != "auditor" || len(in.Role.Permissions) != 1 {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("DELETE", "/user/roles/auditor", nil))
+	if rw.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 No Content, got %d", rw.Code)
+	}
+	if in := client.lastReq.(*userpb.DeleteRoleRequest); in.Name != "auditor" {
+		t.Errorf("unexpected grpc request: %v", in)
+	}
+}
+
+func TestUserHandler_GRPCError(t *testing.T) {
+	h := handlers.NewUserHandler(&fakeUserClient{err: status.Error(codes.NotFound, "user not found")})
+
+	req := httptest.NewRequest("GET", "/user/profile/abc", nil)
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusNotFound {
+		t.Errorf("expected 404 Not Found, got %d", rw.Code)
+	}
+}
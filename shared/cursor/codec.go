@@ -0,0 +1,77 @@
+// Package cursor подписывает токены страниц (page_token) списков, чтобы клиент не мог их подделать.
+// Общий для user-service и task-service.
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+)
+
+// ErrInvalid — токен повреждён, изменён или выдан не этим сервисом
+var ErrInvalid = errors.New("invalid page token")
+
+// Codec кодирует произвольную позицию страницы (JSON) в подписанный HMAC-SHA256 токен:
+// клиент не может ни подделать токен, ни изменить значения в нём
+type Codec struct {
+	key []byte
+}
+
+// Secret возвращает ключ подписи токенов: configured (CURSOR_SECRET), а если он не задан — случайный ключ.
+// Токены, подписанные случайным ключом, не переживают перезапуск сервиса.
+func Secret(configured string) []byte {
+	if configured != "" {
+		return []byte(configured)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatalf("failed to generate cursor key: %v", err)
+	}
+	log.Printf("CURSOR_SECRET не задан: page_token подписываются случайным ключом")
+	return key
+}
+
+// NewCodec возвращает Codec, подписывающий токены ключом secret
+func NewCodec(secret []byte) *Codec {
+	return &Codec{key: secret}
+}
+
+// Encode возвращает токен для позиции v
+func (c *Codec) Encode(v interface{}) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode проверяет подпись токена и разбирает позицию в v; неверный токен — ErrInvalid
+func (c *Codec) Decode(token string, v interface{}) error {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, c.sign(payload)) {
+		return ErrInvalid
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalid
+	}
+	return nil
+}
+
+func (c *Codec) sign(payload []byte) []byte {
+	h := hmac.New(sha256.New, c.key)
+	h.Write(payload)
+	return h.Sum(nil)
+}
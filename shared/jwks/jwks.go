@@ -0,0 +1,173 @@
+// Package jwks проверяет подписи JWT публичными ключами user-service: загружает и кэширует его JWKS.
+// Общий для api-gateway и task-service.
+package jwks
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// KeySet — кэш публичных ключей user-service, загружаемых из JWKS
+type KeySet struct {
+	url        string
+	client     *http.Client
+	ttl        time.Duration // как долго набор ключей считается свежим
+	minRefresh time.Duration // загрузки не чаще этого интервала, в том числе неудачные
+
+	refreshMu   sync.Mutex // одна загрузка JWKS за раз
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time // последняя успешная загрузка
+	attemptedAt time.Time // последняя попытка загрузки
+	refreshing  bool      // идёт фоновая загрузка
+}
+
+func NewKeySet(url string) *KeySet {
+	return &KeySet{
+		url:        url,
+		client:     &http.Client{Timeout: 5 * time.Second},
+		ttl:        10 * time.Minute,
+		minRefresh: 30 * time.Second,
+		keys:       map[string]crypto.PublicKey{},
+	}
+}
+
+// Key возвращает публичный ключ по kid. Известный ключ отдаётся из кэша сразу, а по истечении TTL
+// JWKS перечитывается в фоне. Неизвестный kid (ротация ключей) перечитывает JWKS синхронно,
+// но не чаще minRefresh: пока user-service недоступен, запросы не ждут HTTP-таймаута.
+func (k *KeySet) Key(kid string) (crypto.PublicKey, error) {
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	stale := time.Since(k.fetchedAt) >= k.ttl
+	k.mu.RUnlock()
+	if ok {
+		if stale {
+			k.refreshInBackground()
+		}
+		return key, nil
+	}
+	if err := k.refreshThrottled(); err != nil {
+		return nil, err
+	}
+	k.mu.RLock()
+	key, ok = k.keys[kid]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, errors.New("unknown kid")
+	}
+	return key, nil
+}
+
+// refreshThrottled перечитывает JWKS, если с прошлой попытки прошло не меньше minRefresh;
+// параллельные вызовы ждут одной загрузки
+func (k *KeySet) refreshThrottled() error {
+	k.refreshMu.Lock()
+	defer k.refreshMu.Unlock()
+	k.mu.RLock()
+	recent := time.Since(k.attemptedAt) < k.minRefresh
+	k.mu.RUnlock()
+	if recent {
+		return nil
+	}
+	return k.Refresh()
+}
+
+// refreshInBackground запускает фоновую загрузку JWKS, если она ещё не идёт
+func (k *KeySet) refreshInBackground() {
+	k.mu.Lock()
+	if k.refreshing {
+		k.mu.Unlock()
+		return
+	}
+	k.refreshing = true
+	k.mu.Unlock()
+	go func() {
+		if err := k.refreshThrottled(); err != nil {
+			log.Printf("jwks refresh failed: %v", err)
+		}
+		k.mu.Lock()
+		k.refreshing = false
+		k.mu.Unlock()
+	}()
+}
+
+// Refresh загружает JWKS заново
+func (k *KeySet) Refresh() error {
+	k.mu.Lock()
+	k.attemptedAt = time.Now()
+	k.mu.Unlock()
+	resp, err := k.client.Get(k.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks: unexpected status %d", resp.StatusCode)
+	}
+	var doc struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return err
+	}
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, raw := range doc.Keys {
+		kid, key, err := ParseJWK(raw)
+		if err != nil {
+			continue // неизвестные типы ключей пропускаем
+		}
+		keys[kid] = key
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.fetchedAt = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+// ParseJWK разбирает RSA или Ed25519 (OKP) ключ из JWK
+func ParseJWK(data []byte) (string, crypto.PublicKey, error) {
+	var jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return "", nil, err
+	}
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return "", nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.Kid, &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return "", nil, errors.New("unsupported curve")
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return "", nil, errors.New("invalid ed25519 key")
+		}
+		return jwk.Kid, ed25519.PublicKey(x), nil
+	}
+	return "", nil, errors.New("unsupported key type")
+}
@@ -0,0 +1,154 @@
+// Package rbac — права и таблица «роль → права», общие для user-service и task-service.
+// user-service хранит таблицу в БД, task-service читает её из user-service (см. WithSource).
+package rbac
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Права доступа. Суффикс :own — право на собственные объекты (свой профиль, свои задачи),
+// :any — на любые.
+const (
+	PermUserReadOwn   = "user:read:own"
+	PermUserReadAny   = "user:read:any"
+	PermUserUpdateOwn = "user:update:own"
+	PermUserUpdateAny = "user:update:any"
+	PermUserDelete    = "user:delete"
+	PermUserList      = "user:list"
+	PermUserManage    = "user:manage" // роли, права и сессии пользователей
+
+	PermTaskCreate    = "task:create"
+	PermTaskRead      = "task:read"
+	PermTaskUpdateOwn = "task:update:own"
+	PermTaskUpdateAny = "task:update:any"
+	PermTaskDeleteOwn = "task:delete:own"
+	PermTaskDeleteAny = "task:delete:any"
+
+	PermProjectCreate    = "project:create"
+	PermProjectManageOwn = "project:manage:own" // участники своего проекта (владелец проекта)
+	PermProjectManageAny = "project:manage:any" // любые проекты и все задачи без членства
+)
+
+// AllPermissions — все известные права; роль не может получить право не из этого списка
+var AllPermissions = []string{
+	PermUserReadOwn, PermUserReadAny, PermUserUpdateOwn, PermUserUpdateAny, PermUserDelete, PermUserList, PermUserManage,
+	PermTaskCreate, PermTaskRead, PermTaskUpdateOwn, PermTaskUpdateAny, PermTaskDeleteOwn, PermTaskDeleteAny,
+	PermProjectCreate, PermProjectManageOwn, PermProjectManageAny,
+}
+
+// Встроенные роли
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// DefaultRoles — роли, которыми заполняется пустая таблица roles user-service;
+// в task-service действуют до первой загрузки таблицы
+func DefaultRoles() map[string][]string {
+	return map[string][]string{
+		RoleUser: {
+			PermUserReadOwn, PermUserUpdateOwn,
+			PermTaskCreate, PermTaskRead, PermTaskUpdateOwn, PermTaskDeleteOwn,
+			PermProjectCreate, PermProjectManageOwn,
+		},
+		RoleAdmin: append([]string(nil), AllPermissions...),
+	}
+}
+
+// IsKnownPermission сообщает, входит ли perm в AllPermissions
+func IsKnownPermission(perm string) bool {
+	for _, p := range AllPermissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy — таблица «роль → права», безопасная для конкурентного чтения и замены
+type Policy struct {
+	mu    sync.RWMutex
+	roles map[string]map[string]bool
+
+	source *policySource // если задан — таблица загружается из user-service (Refresh, StartRefresh)
+}
+
+func NewPolicy(roles map[string][]string) *Policy {
+	p := &Policy{}
+	p.Set(roles)
+	return p
+}
+
+// Set целиком заменяет таблицу ролей
+func (p *Policy) Set(roles map[string][]string) {
+	table := make(map[string]map[string]bool, len(roles))
+	for role, perms := range roles {
+		set := make(map[string]bool, len(perms))
+		for _, perm := range perms {
+			set[perm] = true
+		}
+		table[role] = set
+	}
+	p.mu.Lock()
+	p.roles = table
+	p.mu.Unlock()
+}
+
+// Roles возвращает копию таблицы ролей; права каждой роли отсортированы
+func (p *Policy) Roles() map[string][]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	roles := make(map[string][]string, len(p.roles))
+	for role, set := range p.roles {
+		perms := make([]string, 0, len(set))
+		for perm := range set {
+			perms = append(perms, perm)
+		}
+		sort.Strings(perms)
+		roles[role] = perms
+	}
+	return roles
+}
+
+// HasRole сообщает, существует ли роль
+func (p *Policy) HasRole(role string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.roles[role]
+	return ok
+}
+
+// Allows сообщает, есть ли у роли право perm
+func (p *Policy) Allows(role, perm string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.roles[role][perm]
+}
+
+// OwnerFunc сообщает, принадлежит ли объект запроса req пользователю userID
+type OwnerFunc func(ctx context.Context, userID string, req interface{}) (bool, error)
+
+// Rule — требования RPC к правам вызывающего. Доступ есть, если у роли есть право Any,
+// либо право Own и Owner подтверждает, что объект принадлежит пользователю.
+// Пустое правило пропускает любого аутентифицированного пользователя.
+type Rule struct {
+	Any   string
+	Own   string
+	Owner OwnerFunc
+}
+
+// Check проверяет правило для пользователя userID с ролью role
+func (p *Policy) Check(ctx context.Context, rule Rule, userID, role string, req interface{}) (bool, error) {
+	if rule.Any == "" && rule.Own == "" {
+		return true, nil
+	}
+	if rule.Any != "" && p.Allows(role, rule.Any) {
+		return true, nil
+	}
+	if rule.Own != "" && rule.Owner != nil && p.Allows(role, rule.Own) {
+		return rule.Owner(ctx, userID, req)
+	}
+	return false, nil
+}
@@ -0,0 +1,64 @@
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// policySource — адрес таблицы «роль → права» в user-service (GET /internal/policy)
+type policySource struct {
+	url    string
+	client *http.Client
+}
+
+// WithSource задаёт адрес, с которого Refresh и StartRefresh загружают таблицу прав
+func (p *Policy) WithSource(url string) *Policy {
+	p.source = &policySource{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+	return p
+}
+
+// StartRefresh перечитывает таблицу прав в фоне раз в interval. Проверки прав не ждут загрузки
+// и читают последнюю успешно загруженную таблицу; при недоступности user-service она не меняется.
+func (p *Policy) StartRefresh(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := p.Refresh(); err != nil {
+				log.Printf("policy refresh failed: %v", err)
+			}
+		}
+	}()
+}
+
+// Refresh загружает таблицу прав заново; без WithSource ничего не делает
+func (p *Policy) Refresh() error {
+	if p.source == nil {
+		return nil
+	}
+	resp, err := p.source.client.Get(p.source.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("policy: unexpected status %d", resp.StatusCode)
+	}
+	var doc struct {
+		Roles map[string][]string `json:"roles"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return err
+	}
+	if len(doc.Roles) == 0 {
+		return fmt.Errorf("policy: empty role table")
+	}
+	p.Set(doc.Roles)
+	return nil
+}
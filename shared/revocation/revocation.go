@@ -0,0 +1,140 @@
+// Package revocation — локальный кэш списка отозванных токенов user-service (GET /internal/revocations),
+// общий для api-gateway и task-service.
+package revocation
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// List — локальный кэш списка отозванных токенов user-service.
+// Список перечитывается не чаще interval; при недоступности user-service
+// используется последняя успешно загруженная версия.
+type List struct {
+	url      string
+	client   *http.Client
+	interval time.Duration
+
+	refreshMu sync.Mutex
+	mu        sync.RWMutex
+	tokens    map[string]time.Time // jti → время истечения токена
+	users     map[string]time.Time // user_id → отозваны токены, выданные раньше
+	fetchedAt time.Time
+}
+
+func NewList(url string) *List {
+	return &List{
+		url:      url,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		interval: 15 * time.Second,
+		tokens:   map[string]time.Time{},
+		users:    map[string]time.Time{},
+	}
+}
+
+// IsRevoked сообщает, отозван ли токен по jti или вместе со всеми сессиями пользователя.
+// issuedAt — iat токена (IssuedAt): токен, выданный в момент отзыва сессий или позже, действует.
+func (l *List) IsRevoked(jti, userID string, issuedAt time.Time) bool {
+	l.refreshIfStale()
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if jti != "" {
+		if _, ok := l.tokens[jti]; ok {
+			return true
+		}
+	}
+	if before, ok := l.users[userID]; ok && issuedAt.Before(before) {
+		return true
+	}
+	return false
+}
+
+func (l *List) refreshIfStale() {
+	l.mu.RLock()
+	fresh := time.Since(l.fetchedAt) < l.interval
+	l.mu.RUnlock()
+	if fresh {
+		return
+	}
+	l.refreshMu.Lock()
+	defer l.refreshMu.Unlock()
+	l.mu.RLock()
+	fresh = time.Since(l.fetchedAt) < l.interval
+	l.mu.RUnlock()
+	if fresh {
+		return
+	}
+	if err := l.Refresh(); err != nil {
+		log.Printf("revocation list refresh failed: %v", err)
+		// не повторяем запрос на каждом вызове, пока user-service недоступен
+		l.mu.Lock()
+		l.fetchedAt = time.Now()
+		l.mu.Unlock()
+	}
+}
+
+// Refresh загружает список отозванных токенов заново
+func (l *List) Refresh() error {
+	resp, err := l.client.Get(l.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("revocations: unexpected status %d", resp.StatusCode)
+	}
+	var doc struct {
+		Tokens []struct {
+			JTI       string `json:"jti"`
+			ExpiresAt int64  `json:"expires_at"`
+		} `json:"tokens"`
+		Users []struct {
+			UserID          string `json:"user_id"`
+			RevokedBefore   int64  `json:"revoked_before"`
+			RevokedBeforeMs int64  `json:"revoked_before_ms"`
+		} `json:"users"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return err
+	}
+	tokens := make(map[string]time.Time, len(doc.Tokens))
+	for _, t := range doc.Tokens {
+		tokens[t.JTI] = time.Unix(t.ExpiresAt, 0)
+	}
+	users := make(map[string]time.Time, len(doc.Users))
+	for _, u := range doc.Users {
+		// revoked_before_ms нет у старых версий user-service
+		users[u.UserID] = time.Unix(u.RevokedBefore, 0)
+		if u.RevokedBeforeMs != 0 {
+			users[u.UserID] = time.UnixMilli(u.RevokedBeforeMs)
+		}
+	}
+	l.mu.Lock()
+	l.tokens = tokens
+	l.users = users
+	l.fetchedAt = time.Now()
+	l.mu.Unlock()
+	return nil
+}
+
+// IssuedAt возвращает iat токена с долями секунды: user-service выдаёт iat с миллисекундами,
+// чтобы токен, полученный сразу после отзыва сессий, не попал под отзыв (GetIssuedAt из golang-jwt
+// округляет до секунд). Нет iat — нулевое время.
+func IssuedAt(claims map[string]interface{}) time.Time {
+	var seconds float64
+	switch iat := claims["iat"].(type) {
+	case float64:
+		seconds = iat
+	case json.Number:
+		seconds, _ = iat.Float64()
+	default:
+		return time.Time{}
+	}
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), 0).Add(time.Duration(frac * float64(time.Second))).Round(time.Millisecond)
+}
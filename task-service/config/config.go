@@ -1,21 +1,44 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
 )
 
 type Config struct {
-	DBUrl     string
-	JWTSecret string
-	Port      string
+	DBUrl              string
+	JWTSecret          string
+	JWKSUrl            string // если задан, токены проверяются по JWKS user-service вместо JWT_SECRET
+	JWTIssuer          string // ожидаемый iss токенов
+	JWTAudience        string // ожидаемый aud токенов
+	RevocationsURL     string // список отозванных токенов user-service; пусто — без проверки отзыва
+	PolicyURL          string // таблица «роль → права» user-service; пусто — роли по умолчанию
+	WorkflowFile       string // JSON со статусами и переходами задач; пусто — workflow по умолчанию
+	CursorSecret       string // ключ подписи page_token; пусто — случайный ключ при старте
+	TrashRetentionDays int    // TRASH_RETENTION_DAYS: через сколько дней удалённые задачи стираются окончательно; 0 — никогда
+	Port               string
 }
 
 func LoadConfig() *Config {
-	return &Config{
-		DBUrl:     getEnv("DB_URL", "host=db user=user password=password dbname=tasks_db port=5432 sslmode=disable"),
-		JWTSecret: getEnv("JWT_SECRET", "supersecretkey"),
-		Port:      getEnv("TASK_SERVICE_PORT", "50052"),
+	cfg := &Config{
+		DBUrl:          getEnv("DB_URL", "host=db user=user password=password dbname=tasks_db port=5432 sslmode=disable"),
+		JWTSecret:      getEnv("JWT_SECRET", "supersecretkey"),
+		JWKSUrl:        getEnv("JWKS_URL", ""),
+		JWTIssuer:      getEnv("JWT_ISSUER", "user-service"),
+		JWTAudience:    getEnv("JWT_AUDIENCE", "team-platform"),
+		RevocationsURL: getEnv("REVOCATIONS_URL", ""),
+		PolicyURL:      getEnv("POLICY_URL", ""),
+		WorkflowFile:   getEnv("WORKFLOW_FILE", ""),
+		Port:           getEnv("TASK_SERVICE_PORT", "50052"),
 	}
+	cfg.CursorSecret = getEnv("CURSOR_SECRET", "")
+	days, err := strconv.Atoi(getEnv("TRASH_RETENTION_DAYS", "30"))
+	if err != nil || days < 0 {
+		log.Fatalf("TRASH_RETENTION_DAYS: ожидается неотрицательное число дней")
+	}
+	cfg.TrashRetentionDays = days
+	return cfg
 }
 
 func getEnv(key, fallback string) string {
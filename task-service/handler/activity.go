@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"task-service/model"
+	pb "task-service/proto"
+	"task-service/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	defaultHistoryPageSize = 50
+	maxHistoryPageSize     = 200
+)
+
+// activityLog собирает записи журнала одной операции пользователя
+type activityLog struct {
+	actor   uuid.UUID
+	at      time.Time
+	entries []model.Activity
+}
+
+func newActivityLog(ctx context.Context) *activityLog {
+	log := &activityLog{at: time.Now().UTC()}
+	if auth := authContext(ctx); auth != nil {
+		log.actor, _ = uuid.Parse(auth.UserID)
+	}
+	return log
+}
+
+// add добавляет запись; id записей упорядочены по времени (UUIDv7), поэтому записи
+// одной операции читаются в порядке добавления
+func (l *activityLog) add(entityType string, entityID uuid.UUID, action, field, oldValue, newValue string) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		id = uuid.New()
+	}
+	l.entries = append(l.entries, model.Activity{
+		ID:         id,
+		EntityType: entityType,
+		EntityID:   entityID,
+		ActorID:    l.actor,
+		Action:     action,
+		Field:      field,
+		OldValue:   oldValue,
+		NewValue:   newValue,
+		CreatedAt:  l.at,
+	})
+}
+
+// change добавляет запись об изменении поля, если значение действительно изменилось
+func (l *activityLog) change(entityType string, entityID uuid.UUID, field, oldValue, newValue string) {
+	if oldValue != newValue {
+		l.add(entityType, entityID, "update", field, oldValue, newValue)
+	}
+}
+
+// taskChanges записывает отличия after от before по редактируемым полям задачи
+func (l *activityLog) taskChanges(before, after *model.Task) {
+	l.change(model.EntityTask, after.ID, "title", before.Title, after.Title)
+	l.change(model.EntityTask, after.ID, "description", before.Description, after.Description)
+	l.change(model.EntityTask, after.ID, "assignee_id", uuidValue(&before.AssigneeID), uuidValue(&after.AssigneeID))
+	l.change(model.EntityTask, after.ID, "due_date", timeValue(before.DueDate), timeValue(after.DueDate))
+	l.change(model.EntityTask, after.ID, "labels", strings.Join(before.Labels, ","), strings.Join(after.Labels, ","))
+	l.change(model.EntityTask, after.ID, "status", before.Status, after.Status)
+}
+
+// mutate выполняет изменение fn и записывает журнал log в одной транзакции: без записи
+// в журнале изменение не сохраняется. fn может дополнять log по ходу выполнения.
+func (s *TaskServer) mutate(log *activityLog, fn func(repo *repository.TaskRepository) error) error {
+	return s.Repo.Transaction(func(repo *repository.TaskRepository) error {
+		if err := fn(repo); err != nil {
+			return err
+		}
+		return repo.AddActivity(log.entries)
+	})
+}
+
+// GetTaskHistory возвращает журнал изменений задачи в хронологическом порядке (подписанный page_token)
+func (s *TaskServer) GetTaskHistory(ctx context.Context, req *pb.GetTaskHistoryRequest) (*pb.GetTaskHistoryResponse, error) {
+	task, err := s.visibleTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	afterAt, afterID, err := s.decodeEntryToken(req.PageToken, listHistory, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	limit := pageLimit(req.PageSize, defaultHistoryPageSize, maxHistoryPageSize)
+	entries, err := s.Repo.ListActivity(model.EntityTask, task.ID, afterAt, afterID, limit+1)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	var next string
+	if len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[limit-1]
+		if next, err = s.encodeEntryToken(listHistory, task.ID, last.CreatedAt, last.ID); err != nil {
+			return nil, err
+		}
+	}
+	protoEntries := make([]*pb.ActivityEntry, 0, len(entries))
+	for i := range entries {
+		protoEntries = append(protoEntries, toProtoActivity(&entries[i]))
+	}
+	return &pb.GetTaskHistoryResponse{Entries: protoEntries, NextPageToken: next}, nil
+}
+
+func toProtoActivity(a *model.Activity) *pb.ActivityEntry {
+	return &pb.ActivityEntry{
+		Id:        a.ID.String(),
+		TaskId:    a.EntityID.String(),
+		ActorId:   a.ActorID.String(),
+		Action:    a.Action,
+		Field:     a.Field,
+		OldValue:  a.OldValue,
+		NewValue:  a.NewValue,
+		CreatedAt: a.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
+
+func uuidValue(id *uuid.UUID) string {
+	if id == nil || *id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+func timeValue(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func intValue(n int) string {
+	return strconv.Itoa(n)
+}
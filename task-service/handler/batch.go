@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"task-service/model"
+	pb "task-service/proto"
+	"task-service/repository"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxBatchTasks — наибольшее число задач в одном BatchUpdateTasks
+const maxBatchTasks = 100
+
+// batchTaskFields — поля, которые можно указать в update_mask BatchUpdateTasks
+var batchTaskFields = map[string]bool{
+	"status":      true,
+	"assignee_id": true,
+	"due_date":    true,
+}
+
+// batchChange — проверенные изменения BatchUpdateTasks
+type batchChange struct {
+	fields       map[string]bool
+	update       *pb.UpdateTaskRequest // поля для applyTaskUpdate
+	status       string
+	addLabels    []string
+	removeLabels []string
+}
+
+// batchItem — задача, которую можно изменить: её индекс в запросе и состояние до и после изменения
+type batchItem struct {
+	index  int
+	before model.Task
+	task   *model.Task
+}
+
+// BatchUpdateTasks применяет одни и те же изменения (статус, исполнитель, срок, метки) к задачам task_ids
+// в одной транзакции. Права и переходы статуса проверяются для каждой задачи, как в UpdateTask, и
+// возвращаются в results; с all_or_nothing при любой ошибке не меняется ни одна задача.
+func (s *TaskServer) BatchUpdateTasks(ctx context.Context, req *pb.BatchUpdateTasksRequest) (*pb.BatchUpdateTasksResponse, error) {
+	change, err := parseBatchChange(req)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*pb.BatchUpdateTaskResult, len(req.TaskIds))
+	var items []batchItem
+	for i, id := range req.TaskIds {
+		results[i] = &pb.BatchUpdateTaskResult{TaskId: id}
+		item, err := s.batchItem(ctx, id, change)
+		if err != nil {
+			setBatchError(results[i], err)
+			continue
+		}
+		item.index = i
+		items = append(items, item)
+	}
+	if req.AllOrNothing && len(items) < len(req.TaskIds) {
+		abortBatch(results, items)
+		return &pb.BatchUpdateTasksResponse{Results: results}, nil
+	}
+
+	var updated []batchItem
+	log := newActivityLog(ctx)
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		for _, item := range items {
+			err := s.checkBlockers(repo, item.task.ID, item.before.Status, item.task.Status)
+			if err == nil {
+				err = repo.UpdateTask(item.task)
+			}
+			if failed := batchWriteError(err); failed != nil {
+				setBatchError(results[item.index], failed)
+				if !req.AllOrNothing {
+					continue
+				}
+			}
+			if err != nil {
+				return err
+			}
+			log.taskChanges(&item.before, item.task)
+			updated = append(updated, item)
+		}
+		return nil
+	})
+	if batchWriteError(err) != nil {
+		// all_or_nothing: транзакция откачена целиком
+		abortBatch(results, items)
+		return &pb.BatchUpdateTasksResponse{Results: results}, nil
+	}
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+
+	tasks := make([]model.Task, len(updated))
+	for i, item := range updated {
+		tasks[i] = *item.task
+	}
+	protoTasks, err := s.protoTasks(tasks...)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range updated {
+		results[item.index].Task = protoTasks[i]
+	}
+	return &pb.BatchUpdateTasksResponse{Results: results, Updated: int32(len(updated))}, nil
+}
+
+// parseBatchChange проверяет запрос целиком: число задач, маску и значения полей
+func parseBatchChange(req *pb.BatchUpdateTasksRequest) (*batchChange, error) {
+	if len(req.TaskIds) == 0 {
+		return nil, GRPCError("task_ids is required", codes.InvalidArgument)
+	}
+	if len(req.TaskIds) > maxBatchTasks {
+		return nil, GRPCError("too many task_ids", codes.InvalidArgument)
+	}
+	seen := make(map[string]bool, len(req.TaskIds))
+	for _, id := range req.TaskIds {
+		if seen[id] {
+			return nil, GRPCError("duplicate task_id: "+id, codes.InvalidArgument)
+		}
+		seen[id] = true
+	}
+	status, err := requestedStatus(req.Status, req.StatusCode)
+	if err != nil {
+		return nil, err
+	}
+	change := &batchChange{
+		fields:       map[string]bool{},
+		update:       &pb.UpdateTaskRequest{AssigneeId: req.AssigneeId, DueDate: req.DueDate},
+		status:       status,
+		addLabels:    req.AddLabels,
+		removeLabels: req.RemoveLabels,
+	}
+	if len(req.GetUpdateMask().GetPaths()) == 0 {
+		change.fields["status"] = status != ""
+		change.fields["assignee_id"] = req.AssigneeId != ""
+		change.fields["due_date"] = req.DueDate != ""
+	}
+	for _, path := range req.GetUpdateMask().GetPaths() {
+		if path == "status_code" {
+			path = "status"
+		}
+		if !batchTaskFields[path] {
+			return nil, GRPCError("unknown update_mask field: "+path, codes.InvalidArgument)
+		}
+		change.fields[path] = true
+	}
+	if change.fields["status"] && status == "" {
+		return nil, GRPCError("status is required", codes.InvalidArgument)
+	}
+	if change.fields["assignee_id"] && req.AssigneeId != "" {
+		if _, err := uuid.Parse(req.AssigneeId); err != nil {
+			return nil, GRPCError("invalid assignee_id", codes.InvalidArgument)
+		}
+	}
+	if change.fields["due_date"] && req.DueDate != "" {
+		if _, err := time.Parse(time.RFC3339, req.DueDate); err != nil {
+			return nil, GRPCError("invalid due_date", codes.InvalidArgument)
+		}
+	}
+	if !change.fields["status"] && !change.fields["assignee_id"] && !change.fields["due_date"] &&
+		len(req.AddLabels) == 0 && len(req.RemoveLabels) == 0 {
+		return nil, GRPCError("nothing to update", codes.InvalidArgument)
+	}
+	return change, nil
+}
+
+// batchItem проверяет видимость задачи, права на её изменение (как у UpdateTask) и переход статуса,
+// и возвращает задачу с применёнными изменениями
+func (s *TaskServer) batchItem(ctx context.Context, id string, change *batchChange) (batchItem, error) {
+	task, err := s.visibleTask(ctx, id)
+	if err != nil {
+		return batchItem{}, err
+	}
+	auth := authContext(ctx)
+	rule := s.methodRules()[pb.TaskService_UpdateTask_FullMethodName]
+	allowed, err := s.Policy.Check(ctx, rule, auth.UserID, auth.Role, &pb.UpdateTaskRequest{TaskId: id})
+	if err != nil {
+		return batchItem{}, err
+	}
+	if !allowed {
+		return batchItem{}, GRPCError("forbidden", codes.PermissionDenied)
+	}
+	item := batchItem{before: *task, task: task}
+	if err := applyTaskUpdate(task, change.update, change.fields); err != nil {
+		return batchItem{}, err
+	}
+	if len(change.addLabels) > 0 || len(change.removeLabels) > 0 {
+		task.Labels = editLabels(task.Labels, change.addLabels, change.removeLabels)
+	}
+	if change.fields["status"] {
+		if err := s.checkTransition(ctx, task, change.status); err != nil {
+			return batchItem{}, err
+		}
+		task.Status = change.status
+	}
+	task.UpdatedAt = time.Now()
+	return item, nil
+}
+
+// editLabels добавляет к меткам add (без повторов) и убирает remove
+func editLabels(labels model.Labels, add, remove []string) model.Labels {
+	result := model.Labels{}
+	for _, label := range labels {
+		if !slices.Contains(remove, label) {
+			result = append(result, label)
+		}
+	}
+	for _, label := range add {
+		if !slices.Contains(result, label) && !slices.Contains(remove, label) {
+			result = append(result, label)
+		}
+	}
+	return result
+}
+
+// batchWriteError переводит ошибку записи задачи, которая не прерывает остальной пакет, в ошибку результата;
+// nil — ошибки нет или она прерывает весь вызов
+func batchWriteError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrVersionConflict):
+		// задачу изменили после чтения
+		return GRPCError(err.Error(), codes.Aborted)
+	case errors.Is(err, repository.ErrTaskBlocked):
+		return GRPCError(err.Error(), codes.FailedPrecondition)
+	}
+	return nil
+}
+
+// setBatchError записывает в результат код и текст ошибки задачи
+func setBatchError(result *pb.BatchUpdateTaskResult, err error) {
+	st := status.Convert(err)
+	result.Code = int32(st.Code())
+	result.Message = st.Message()
+}
+
+// abortBatch отмечает задачи items, которые можно было изменить, как не изменённые из-за all_or_nothing
+func abortBatch(results []*pb.BatchUpdateTaskResult, items []batchItem) {
+	for _, item := range items {
+		if results[item.index].Code == int32(codes.OK) {
+			setBatchError(results[item.index], GRPCError("not applied: another task in the batch failed", codes.Aborted))
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"shared/rbac"
+	"task-service/model"
+	pb "task-service/proto"
+	"task-service/rank"
+	"task-service/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+// CreateColumn добавляет колонку в конец доски проекта (владелец проекта или project:manage:any)
+func (s *TaskServer) CreateColumn(ctx context.Context, req *pb.CreateColumnRequest) (*pb.CreateColumnResponse, error) {
+	if err := ValidateColumnInput(req.Name, req.WipLimit); err != nil {
+		return nil, GRPCError(err.Error(), codes.InvalidArgument)
+	}
+	projectID, err := uuid.Parse(req.ProjectId)
+	if err != nil {
+		return nil, GRPCError("invalid project_id", codes.InvalidArgument)
+	}
+	project, err := s.Repo.GetProjectByID(projectID)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if project == nil {
+		return nil, GRPCError("project not found", codes.NotFound)
+	}
+	last, err := s.Repo.LastColumnRank(projectID)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	pos, err := rank.After(last)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	column := &model.Column{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		Name:      req.Name,
+		Rank:      pos,
+		WIPLimit:  int(req.WipLimit),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	log := newActivityLog(ctx)
+	log.add(model.EntityColumn, column.ID, "create", "", "", column.Name)
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.CreateColumn(column)
+	})
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.CreateColumnResponse{Column: toProtoColumn(column)}, nil
+}
+
+// UpdateColumn меняет название и WIP-лимит колонки. Лимит ниже текущего числа задач допустим:
+// он запрещает только новые перемещения в колонку.
+func (s *TaskServer) UpdateColumn(ctx context.Context, req *pb.UpdateColumnRequest) (*pb.UpdateColumnResponse, error) {
+	if err := ValidateColumnInput(req.Name, req.WipLimit); err != nil {
+		return nil, GRPCError(err.Error(), codes.InvalidArgument)
+	}
+	column, err := s.requestColumn(req)
+	if err != nil {
+		return nil, err
+	}
+	log := newActivityLog(ctx)
+	log.change(model.EntityColumn, column.ID, "name", column.Name, req.Name)
+	log.change(model.EntityColumn, column.ID, "wip_limit", intValue(column.WIPLimit), intValue(int(req.WipLimit)))
+	column.Name = req.Name
+	column.WIPLimit = int(req.WipLimit)
+	column.UpdatedAt = time.Now()
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.UpdateColumn(column)
+	})
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.UpdateColumnResponse{Column: toProtoColumn(column)}, nil
+}
+
+// DeleteColumn удаляет колонку; колонку с задачами удалить нельзя
+func (s *TaskServer) DeleteColumn(ctx context.Context, req *pb.DeleteColumnRequest) (*pb.DeleteColumnResponse, error) {
+	column, err := s.requestColumn(req)
+	if err != nil {
+		return &pb.DeleteColumnResponse{Success: false}, err
+	}
+	count, err := s.Repo.CountColumnTasks(column.ID)
+	if err != nil {
+		return &pb.DeleteColumnResponse{Success: false}, GRPCError("internal error", codes.Internal)
+	}
+	if count > 0 {
+		return &pb.DeleteColumnResponse{Success: false}, GRPCError("column is not empty", codes.FailedPrecondition)
+	}
+	log := newActivityLog(ctx)
+	log.add(model.EntityColumn, column.ID, "delete", "", column.Name, "")
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.DeleteColumn(column.ID)
+	})
+	if err != nil {
+		return &pb.DeleteColumnResponse{Success: false}, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.DeleteColumnResponse{Success: true}, nil
+}
+
+// ListColumns возвращает колонки доски в порядке слева направо (участникам проекта)
+func (s *TaskServer) ListColumns(ctx context.Context, req *pb.ListColumnsRequest) (*pb.ListColumnsResponse, error) {
+	projectID, err := uuid.Parse(req.ProjectId)
+	if err != nil {
+		return nil, GRPCError("invalid project_id", codes.InvalidArgument)
+	}
+	if err := s.checkProjectRead(ctx, projectID); err != nil {
+		return nil, err
+	}
+	columns, err := s.Repo.ListColumns(projectID)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	var protoColumns []*pb.Column
+	for i := range columns {
+		protoColumns = append(protoColumns, toProtoColumn(&columns[i]))
+	}
+	return &pb.ListColumnsResponse{Columns: protoColumns}, nil
+}
+
+// MoveTask переносит задачу проекта в колонку его доски на место position.
+// Перенос в другую колонку, где уже wip_limit задач, отклоняется (FailedPrecondition).
+func (s *TaskServer) MoveTask(ctx context.Context, req *pb.MoveTaskRequest) (*pb.MoveTaskResponse, error) {
+	task, err := s.visibleTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	if task.ProjectID == nil {
+		return nil, GRPCError("personal task cannot be placed on a board", codes.FailedPrecondition)
+	}
+	columnID, err := uuid.Parse(req.ColumnId)
+	if err != nil {
+		return nil, GRPCError("invalid column_id", codes.InvalidArgument)
+	}
+	column, err := s.Repo.GetColumnByID(columnID)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if column == nil || column.ProjectID != *task.ProjectID {
+		return nil, GRPCError("column does not belong to task project", codes.InvalidArgument)
+	}
+	oldColumn, oldRank := uuidValue(task.ColumnID), task.Rank
+	log := newActivityLog(ctx)
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		if err := repo.MoveTask(task, column, int(req.Position)); err != nil {
+			return err
+		}
+		if newColumn := uuidValue(task.ColumnID); newColumn != oldColumn {
+			log.add(model.EntityTask, task.ID, "move", "column_id", oldColumn, newColumn)
+		}
+		log.add(model.EntityTask, task.ID, "move", "rank", oldRank, task.Rank)
+		return nil
+	})
+	if err != nil {
+		return nil, columnError(err)
+	}
+	tasks, err := s.protoTasks(*task)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.MoveTaskResponse{Task: tasks[0]}, nil
+}
+
+// createTask сохраняет новую задачу; задача проекта с доской попадает в конец первой колонки
+func (s *TaskServer) createTask(repo *repository.TaskRepository, log *activityLog, task *model.Task) error {
+	if task.ProjectID == nil {
+		return repo.CreateTask(task)
+	}
+	column, err := repo.FirstColumn(*task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if column == nil {
+		return repo.CreateTask(task)
+	}
+	if err := repo.CreateTaskInColumn(task, column); err != nil {
+		return err
+	}
+	log.add(model.EntityTask, task.ID, "move", "column_id", "", column.ID.String())
+	return nil
+}
+
+// checkProjectRead разрешает чтение доски участникам проекта и обладателям project:manage:any
+func (s *TaskServer) checkProjectRead(ctx context.Context, projectID uuid.UUID) error {
+	auth := authContext(ctx)
+	if s.Policy.Allows(auth.Role, rbac.PermProjectManageAny) {
+		return nil
+	}
+	member, err := s.projectMember(projectID, auth.UserID)
+	if err != nil {
+		return GRPCError("internal error", codes.Internal)
+	}
+	if member == nil {
+		return GRPCError("forbidden", codes.PermissionDenied)
+	}
+	return nil
+}
+
+// requestColumn загружает колонку, на которую ссылается запрос (поле column_id)
+func (s *TaskServer) requestColumn(req interface{}) (*model.Column, error) {
+	r, ok := req.(interface{ GetColumnId() string })
+	if !ok {
+		return nil, GRPCError("column_id is required", codes.InvalidArgument)
+	}
+	id, err := uuid.Parse(r.GetColumnId())
+	if err != nil {
+		return nil, GRPCError("invalid column_id", codes.InvalidArgument)
+	}
+	column, err := s.Repo.GetColumnByID(id)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if column == nil {
+		return nil, GRPCError("column not found", codes.NotFound)
+	}
+	return column, nil
+}
+
+func columnError(err error) error {
+	if errors.Is(err, repository.ErrWIPLimit) {
+		return GRPCError(err.Error(), codes.FailedPrecondition)
+	}
+	return GRPCError("internal error", codes.Internal)
+}
+
+func toProtoColumn(c *model.Column) *pb.Column {
+	return &pb.Column{
+		Id:        c.ID.String(),
+		ProjectId: c.ProjectID.String(),
+		Name:      c.Name,
+		WipLimit:  int32(c.WIPLimit),
+		Rank:      c.Rank,
+	}
+}
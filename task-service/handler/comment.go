@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"context"
+	"regexp"
+	"task-service/model"
+	pb "task-service/proto"
+	"task-service/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	defaultCommentsPageSize = 20
+	maxCommentsPageSize     = 100
+)
+
+// mentionPattern — упоминание пользователя в тексте комментария: @<user_id>
+var mentionPattern = regexp.MustCompile(`@([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})`)
+
+// AddComment добавляет комментарий к задаче; комментировать может любой, кому видна задача
+func (s *TaskServer) AddComment(ctx context.Context, req *pb.AddCommentRequest) (*pb.AddCommentResponse, error) {
+	if err := ValidateCommentInput(req.Body); err != nil {
+		return nil, GRPCError(err.Error(), codes.InvalidArgument)
+	}
+	task, err := s.visibleTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	authorID, err := uuid.Parse(authContext(ctx).UserID)
+	if err != nil {
+		return nil, GRPCError("invalid user_id in token", codes.Unauthenticated)
+	}
+	now := time.Now().UTC()
+	comment := &model.Comment{
+		ID:        uuid.New(),
+		TaskID:    task.ID,
+		AuthorID:  authorID,
+		Body:      req.Body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if comment.Mentions, err = s.parseMentions(task, comment.ID, req.Body); err != nil {
+		return nil, err
+	}
+	// текст комментария в журнал не пишется: после удаления он остаётся только в истории правок
+	log := newActivityLog(ctx)
+	log.add(model.EntityTask, task.ID, "comment_add", "comment:"+comment.ID.String(), "", "")
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.CreateComment(comment)
+	})
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.AddCommentResponse{Comment: toProtoComment(comment, nil)}, nil
+}
+
+// ListComments возвращает обсуждение задачи постранично (подписанный page_token, keyset по времени создания)
+func (s *TaskServer) ListComments(ctx context.Context, req *pb.ListCommentsRequest) (*pb.ListCommentsResponse, error) {
+	task, err := s.visibleTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	afterAt, afterID, err := s.decodeEntryToken(req.PageToken, listComments, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	limit := pageLimit(req.PageSize, defaultCommentsPageSize, maxCommentsPageSize)
+	// на один больше, чтобы узнать, есть ли следующая страница
+	comments, err := s.Repo.ListComments(task.ID, afterAt, afterID, limit+1)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	var next string
+	if len(comments) > limit {
+		comments = comments[:limit]
+		last := comments[limit-1]
+		if next, err = s.encodeEntryToken(listComments, task.ID, last.CreatedAt, last.ID); err != nil {
+			return nil, err
+		}
+	}
+	history := map[uuid.UUID][]model.CommentRevision{}
+	if req.IncludeHistory {
+		var ids []uuid.UUID
+		for _, c := range comments {
+			if c.DeletedAt == nil {
+				ids = append(ids, c.ID)
+			}
+		}
+		revisions, err := s.Repo.ListCommentRevisions(ids)
+		if err != nil {
+			return nil, GRPCError("internal error", codes.Internal)
+		}
+		for _, rev := range revisions {
+			history[rev.CommentID] = append(history[rev.CommentID], rev)
+		}
+	}
+	protoComments := make([]*pb.Comment, 0, len(comments))
+	for i := range comments {
+		protoComments = append(protoComments, toProtoComment(&comments[i], history[comments[i].ID]))
+	}
+	return &pb.ListCommentsResponse{Comments: protoComments, NextPageToken: next}, nil
+}
+
+// EditComment заменяет текст комментария (только автор); прежний текст сохраняется в истории
+func (s *TaskServer) EditComment(ctx context.Context, req *pb.EditCommentRequest) (*pb.EditCommentResponse, error) {
+	if err := ValidateCommentInput(req.Body); err != nil {
+		return nil, GRPCError(err.Error(), codes.InvalidArgument)
+	}
+	comment, task, err := s.visibleComment(ctx, req.CommentId)
+	if err != nil {
+		return nil, err
+	}
+	auth := authContext(ctx)
+	if comment.AuthorID.String() != auth.UserID {
+		return nil, GRPCError("only the author can edit a comment", codes.PermissionDenied)
+	}
+	mentions, err := s.parseMentions(task, comment.ID, req.Body)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	revision := &model.CommentRevision{
+		ID:        uuid.New(),
+		CommentID: comment.ID,
+		Body:      comment.Body,
+		EditorID:  comment.AuthorID,
+		CreatedAt: now,
+	}
+	log := newActivityLog(ctx)
+	log.add(model.EntityTask, task.ID, "comment_edit", "comment:"+comment.ID.String(), "", "")
+	comment.Body = req.Body
+	comment.Mentions = mentions
+	comment.EditedAt = &now
+	comment.UpdatedAt = now
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.EditComment(comment, revision)
+	})
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.EditCommentResponse{Comment: toProtoComment(comment, nil)}, nil
+}
+
+// DeleteComment удаляет текст комментария, оставляя в обсуждении отметку об удалении;
+// удалить может автор, владелец проекта задачи или пользователь с правом task:delete:any
+func (s *TaskServer) DeleteComment(ctx context.Context, req *pb.DeleteCommentRequest) (*pb.DeleteCommentResponse, error) {
+	comment, task, err := s.visibleComment(ctx, req.CommentId)
+	if err != nil {
+		return &pb.DeleteCommentResponse{Success: false}, err
+	}
+	editorID, err := uuid.Parse(authContext(ctx).UserID)
+	if err != nil {
+		return &pb.DeleteCommentResponse{Success: false}, GRPCError("invalid user_id in token", codes.Unauthenticated)
+	}
+	now := time.Now().UTC()
+	revision := &model.CommentRevision{
+		ID:        uuid.New(),
+		CommentID: comment.ID,
+		Body:      comment.Body,
+		EditorID:  editorID,
+		CreatedAt: now,
+	}
+	comment.DeletedAt = &now
+	comment.UpdatedAt = now
+	log := newActivityLog(ctx)
+	log.add(model.EntityTask, task.ID, "comment_delete", "comment:"+comment.ID.String(), "", "")
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.DeleteComment(comment, revision)
+	})
+	if err != nil {
+		return &pb.DeleteCommentResponse{Success: false}, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.DeleteCommentResponse{Success: true}, nil
+}
+
+// visibleComment загружает неудалённый комментарий и его задачу, если задача видна пользователю
+func (s *TaskServer) visibleComment(ctx context.Context, commentID string) (*model.Comment, *model.Task, error) {
+	comment, err := s.loadComment(commentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	task, err := s.visibleTask(ctx, comment.TaskID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return comment, task, nil
+}
+
+// requestComment загружает комментарий, на который ссылается запрос (поле comment_id)
+func (s *TaskServer) requestComment(req interface{}) (*model.Comment, error) {
+	r, ok := req.(interface{ GetCommentId() string })
+	if !ok {
+		return nil, GRPCError("comment_id is required", codes.InvalidArgument)
+	}
+	return s.loadComment(r.GetCommentId())
+}
+
+// loadComment возвращает неудалённый комментарий; удалённый неотличим от отсутствующего
+func (s *TaskServer) loadComment(commentID string) (*model.Comment, error) {
+	id, err := uuid.Parse(commentID)
+	if err != nil {
+		return nil, GRPCError("invalid comment_id", codes.InvalidArgument)
+	}
+	comment, err := s.Repo.GetCommentByID(id)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if comment == nil || comment.DeletedAt != nil {
+		return nil, GRPCError("comment not found", codes.NotFound)
+	}
+	return comment, nil
+}
+
+// parseMentions находит в тексте упоминания @<user_id>. Упоминания пользователей,
+// которым задача не видна, отбрасываются.
+func (s *TaskServer) parseMentions(task *model.Task, commentID uuid.UUID, body string) ([]model.CommentMention, error) {
+	var mentions []model.CommentMention
+	seen := map[uuid.UUID]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		userID, err := uuid.Parse(match[1])
+		if err != nil || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		visible, err := s.taskVisible(&AuthContext{UserID: userID.String()}, task)
+		if err != nil {
+			return nil, GRPCError("internal error", codes.Internal)
+		}
+		if visible {
+			mentions = append(mentions, model.CommentMention{CommentID: commentID, UserID: userID})
+		}
+	}
+	return mentions, nil
+}
+
+func toProtoComment(c *model.Comment, history []model.CommentRevision) *pb.Comment {
+	comment := &pb.Comment{
+		Id:        c.ID.String(),
+		TaskId:    c.TaskID.String(),
+		AuthorId:  c.AuthorID.String(),
+		Body:      c.Body,
+		CreatedAt: c.CreatedAt.Format(time.RFC3339),
+		Deleted:   c.DeletedAt != nil,
+	}
+	if c.EditedAt != nil {
+		comment.EditedAt = c.EditedAt.Format(time.RFC3339)
+	}
+	for _, m := range c.Mentions {
+		comment.Mentions = append(comment.Mentions, m.UserID.String())
+	}
+	for _, rev := range history {
+		comment.History = append(comment.History, &pb.CommentRevision{
+			Body:      rev.Body,
+			EditorId:  rev.EditorID.String(),
+			CreatedAt: rev.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return comment
+}
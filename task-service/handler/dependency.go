@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"task-service/model"
+	pb "task-service/proto"
+	"task-service/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+// SetTaskParent делает задачу подзадачей parent_id (пусто — задачей верхнего уровня).
+// Родитель должен быть в том же проекте и не может быть подзадачей самой задачи.
+func (s *TaskServer) SetTaskParent(ctx context.Context, req *pb.SetTaskParentRequest) (*pb.SetTaskParentResponse, error) {
+	task, err := s.visibleTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	var parentID *uuid.UUID
+	if req.ParentId != "" {
+		parent, err := s.parentTask(ctx, req.ParentId, task.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		parentID = &parent.ID
+	}
+	log := newActivityLog(ctx)
+	log.change(model.EntityTask, task.ID, "parent_id", uuidValue(task.ParentID), uuidValue(parentID))
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.SetParent(task.ID, parentID)
+	})
+	if errors.Is(err, repository.ErrParentCycle) {
+		return nil, GRPCError(err.Error(), codes.FailedPrecondition)
+	}
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	task.ParentID = parentID
+	task.Version++
+	tasks, err := s.protoTasks(*task)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SetTaskParentResponse{Task: tasks[0]}, nil
+}
+
+// AddDependency отмечает, что blocker_id блокирует task_id; связь, замыкающая цикл, отклоняется
+func (s *TaskServer) AddDependency(ctx context.Context, req *pb.AddDependencyRequest) (*pb.AddDependencyResponse, error) {
+	task, blocker, err := s.dependencyTasks(ctx, req.TaskId, req.BlockerId)
+	if err != nil {
+		return &pb.AddDependencyResponse{Success: false}, err
+	}
+	if task.ID == blocker.ID {
+		return &pb.AddDependencyResponse{Success: false}, GRPCError("task cannot block itself", codes.InvalidArgument)
+	}
+	log := newActivityLog(ctx)
+	log.add(model.EntityTask, task.ID, "dependency_add", "blocker:"+blocker.ID.String(), "", "")
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.AddDependency(&model.TaskDependency{BlockerID: blocker.ID, BlockedID: task.ID, CreatedAt: time.Now()})
+	})
+	if errors.Is(err, repository.ErrDependencyCycle) {
+		return &pb.AddDependencyResponse{Success: false}, GRPCError(err.Error(), codes.FailedPrecondition)
+	}
+	if err != nil {
+		return &pb.AddDependencyResponse{Success: false}, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.AddDependencyResponse{Success: true}, nil
+}
+
+// RemoveDependency удаляет связь «blocker_id блокирует task_id»
+func (s *TaskServer) RemoveDependency(ctx context.Context, req *pb.RemoveDependencyRequest) (*pb.RemoveDependencyResponse, error) {
+	task, err := s.visibleTask(ctx, req.TaskId)
+	if err != nil {
+		return &pb.RemoveDependencyResponse{Success: false}, err
+	}
+	blockerID, err := uuid.Parse(req.BlockerId)
+	if err != nil {
+		return &pb.RemoveDependencyResponse{Success: false}, GRPCError("invalid blocker_id", codes.InvalidArgument)
+	}
+	log := newActivityLog(ctx)
+	log.add(model.EntityTask, task.ID, "dependency_remove", "blocker:"+blockerID.String(), "", "")
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.RemoveDependency(blockerID, task.ID)
+	})
+	if errors.Is(err, repository.ErrDependencyNotFound) {
+		return &pb.RemoveDependencyResponse{Success: false}, GRPCError(err.Error(), codes.NotFound)
+	}
+	if err != nil {
+		return &pb.RemoveDependencyResponse{Success: false}, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.RemoveDependencyResponse{Success: true}, nil
+}
+
+// ListDependencies возвращает задачи, блокирующие task_id, и задачи, которые она блокирует
+// (только видимые пользователю)
+func (s *TaskServer) ListDependencies(ctx context.Context, req *pb.ListDependenciesRequest) (*pb.ListDependenciesResponse, error) {
+	task, err := s.visibleTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	blockers, err := s.Repo.ListBlockers(task.ID)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	blocked, err := s.Repo.ListBlocked(task.ID)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	resp := &pb.ListDependenciesResponse{}
+	if resp.BlockedBy, err = s.visibleProtoTasks(ctx, blockers); err != nil {
+		return nil, err
+	}
+	if resp.Blocks, err = s.visibleProtoTasks(ctx, blocked); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// checkBlockers запрещает переводить задачу из статуса from в выполненный статус to, пока блокирующие
+// задачи не выполнены (repository.ErrTaskBlocked). Вызывается внутри mutate, до записи статуса.
+func (s *TaskServer) checkBlockers(repo *repository.TaskRepository, taskID uuid.UUID, from, to string) error {
+	if !s.Workflow.IsDone(to) || s.Workflow.IsDone(from) {
+		return nil
+	}
+	return repo.CheckBlockers(taskID, s.Workflow.Done)
+}
+
+// parentTask загружает будущую родительскую задачу: она должна быть видна пользователю
+// и принадлежать проекту projectID (nil — личные задачи)
+func (s *TaskServer) parentTask(ctx context.Context, parentID string, projectID *uuid.UUID) (*model.Task, error) {
+	parent, err := s.visibleTask(ctx, parentID)
+	if err != nil {
+		return nil, GRPCError("parent task not found", codes.NotFound)
+	}
+	if uuidValue(parent.ProjectID) != uuidValue(projectID) {
+		return nil, GRPCError("subtask must belong to the parent's project", codes.InvalidArgument)
+	}
+	return parent, nil
+}
+
+// dependencyTasks загружает задачу и блокирующую её задачу; обе должны быть видны пользователю
+func (s *TaskServer) dependencyTasks(ctx context.Context, taskID, blockerID string) (*model.Task, *model.Task, error) {
+	task, err := s.visibleTask(ctx, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	blocker, err := s.visibleTask(ctx, blockerID)
+	if err != nil {
+		return nil, nil, GRPCError("blocker task not found", codes.NotFound)
+	}
+	return task, blocker, nil
+}
+
+// protoTasks переводит задачи в protobuf вместе с прогрессом их подзадач
+func (s *TaskServer) protoTasks(tasks ...model.Task) ([]*pb.Task, error) {
+	ids := make([]uuid.UUID, 0, len(tasks))
+	for _, t := range tasks {
+		ids = append(ids, t.ID)
+	}
+	progress, err := s.Repo.SubtaskProgress(ids, s.Workflow.Done)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	result := make([]*pb.Task, 0, len(tasks))
+	for i := range tasks {
+		t := toProtoTask(&tasks[i])
+		p := progress[tasks[i].ID]
+		t.SubtasksTotal, t.SubtasksDone = int32(p.Total), int32(p.Done)
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// visibleProtoTasks отбрасывает задачи, не видимые пользователю, и переводит остальные в protobuf
+func (s *TaskServer) visibleProtoTasks(ctx context.Context, tasks []model.Task) ([]*pb.Task, error) {
+	auth := authContext(ctx)
+	var visible []model.Task
+	for i := range tasks {
+		ok, err := s.taskVisible(auth, &tasks[i])
+		if err != nil {
+			return nil, GRPCError("internal error", codes.Internal)
+		}
+		if ok {
+			visible = append(visible, tasks[i])
+		}
+	}
+	return s.protoTasks(visible...)
+}
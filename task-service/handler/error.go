@@ -3,10 +3,15 @@ package handler
 import (
 	"encoding/json"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// PreconditionVersion — тип нарушения PreconditionFailure, когда версия объекта не совпала
+// с expected_version: api-gateway отвечает на такую ошибку 412, на прочие FailedPrecondition — 400
+const PreconditionVersion = "VERSION"
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
@@ -15,6 +20,17 @@ func GRPCError(msg string, code codes.Code) error {
 	return status.Error(code, msg)
 }
 
+// VersionMismatchError — FailedPrecondition с деталью PreconditionFailure типа PreconditionVersion
+func VersionMismatchError(subject, msg string) error {
+	st, err := status.New(codes.FailedPrecondition, msg).WithDetails(&errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{Type: PreconditionVersion, Subject: subject, Description: msg}},
+	})
+	if err != nil {
+		return status.Error(codes.FailedPrecondition, msg)
+	}
+	return st.Err()
+}
+
 func WriteJSONError(w interface{ Write([]byte) (int, error) }, code int, msg string) {
 	resp, _ := json.Marshal(ErrorResponse{Error: msg})
 	w.Write(resp)
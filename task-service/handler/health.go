@@ -5,14 +5,15 @@ import (
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // HealthCheck реализует gRPC healthcheck endpoint
-func (s *TaskServer) HealthCheck(ctx context.Context, _ *struct{}) (*struct{}, error) {
-	return &struct{}{}, nil
+func (s *TaskServer) HealthCheck(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
 }
 
 // HealthCheckError возвращает ошибку для проверки мониторинга
-func (s *TaskServer) HealthCheckError(ctx context.Context, _ *struct{}) (*struct{}, error) {
+func (s *TaskServer) HealthCheckError(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
 	return nil, status.Error(codes.Unavailable, "service unavailable")
 }
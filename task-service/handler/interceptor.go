@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"shared/rbac"
+	"task-service/model"
+	pb "task-service/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// PublicMethods — RPC, доступные без access token
+var PublicMethods = map[string]bool{
+	pb.TaskService_HealthCheck_FullMethodName: true,
+}
+
+// AuthContext — пользователь и роль из проверенного access token
+type AuthContext struct {
+	UserID string
+	Role   string
+}
+
+type authContextKey struct{}
+
+// methodRules — права, необходимые для вызова RPC. Непубличный метод без правила запрещён всем.
+func (s *TaskServer) methodRules() map[string]rbac.Rule {
+	return map[string]rbac.Rule{
+		pb.TaskService_CreateTask_FullMethodName:   {Any: rbac.PermTaskCreate},
+		pb.TaskService_GetTask_FullMethodName:      {Any: rbac.PermTaskRead},
+		pb.TaskService_ListTasks_FullMethodName:    {Any: rbac.PermTaskRead},
+		pb.TaskService_UpdateTask_FullMethodName:   {Any: rbac.PermTaskUpdateAny, Own: rbac.PermTaskUpdateOwn, Owner: s.canEditTask},
+		pb.TaskService_ChangeStatus_FullMethodName: {Any: rbac.PermTaskUpdateAny, Own: rbac.PermTaskUpdateOwn, Owner: s.canEditTask},
+		pb.TaskService_DeleteTask_FullMethodName:   {Any: rbac.PermTaskDeleteAny, Own: rbac.PermTaskDeleteOwn, Owner: s.canDeleteTask},
+
+		pb.TaskService_CreateProject_FullMethodName: {Any: rbac.PermProjectCreate},
+		pb.TaskService_ListProjects_FullMethodName:  {},
+		pb.TaskService_AddMember_FullMethodName:     {Any: rbac.PermProjectManageAny, Own: rbac.PermProjectManageOwn, Owner: s.isProjectOwner},
+
+		pb.TaskService_CreateColumn_FullMethodName: {Any: rbac.PermProjectManageAny, Own: rbac.PermProjectManageOwn, Owner: s.isProjectOwner},
+		pb.TaskService_UpdateColumn_FullMethodName: {Any: rbac.PermProjectManageAny, Own: rbac.PermProjectManageOwn, Owner: s.isColumnOwner},
+		pb.TaskService_DeleteColumn_FullMethodName: {Any: rbac.PermProjectManageAny, Own: rbac.PermProjectManageOwn, Owner: s.isColumnOwner},
+		pb.TaskService_ListColumns_FullMethodName:  {Any: rbac.PermTaskRead},
+		pb.TaskService_MoveTask_FullMethodName:     {Any: rbac.PermTaskUpdateAny, Own: rbac.PermTaskUpdateOwn, Owner: s.canEditTask},
+
+		// видимость задачи и авторство проверяют обработчики
+		pb.TaskService_AddComment_FullMethodName:    {Any: rbac.PermTaskRead},
+		pb.TaskService_ListComments_FullMethodName:  {Any: rbac.PermTaskRead},
+		pb.TaskService_EditComment_FullMethodName:   {Any: rbac.PermTaskRead},
+		pb.TaskService_DeleteComment_FullMethodName: {Any: rbac.PermTaskDeleteAny, Own: rbac.PermTaskRead, Owner: s.canDeleteComment},
+
+		pb.TaskService_GetTaskHistory_FullMethodName: {Any: rbac.PermTaskRead},
+
+		pb.TaskService_SetTaskParent_FullMethodName:    {Any: rbac.PermTaskUpdateAny, Own: rbac.PermTaskUpdateOwn, Owner: s.canEditTask},
+		pb.TaskService_AddDependency_FullMethodName:    {Any: rbac.PermTaskUpdateAny, Own: rbac.PermTaskUpdateOwn, Owner: s.canEditTask},
+		pb.TaskService_RemoveDependency_FullMethodName: {Any: rbac.PermTaskUpdateAny, Own: rbac.PermTaskUpdateOwn, Owner: s.canEditTask},
+		pb.TaskService_ListDependencies_FullMethodName: {Any: rbac.PermTaskRead},
+
+		pb.TaskService_ArchiveTask_FullMethodName:   {Any: rbac.PermTaskUpdateAny, Own: rbac.PermTaskUpdateOwn, Owner: s.canEditTask},
+		pb.TaskService_UnarchiveTask_FullMethodName: {Any: rbac.PermTaskUpdateAny, Own: rbac.PermTaskUpdateOwn, Owner: s.canEditTask},
+		pb.TaskService_RestoreTask_FullMethodName:   {Any: rbac.PermTaskDeleteAny, Own: rbac.PermTaskDeleteOwn, Owner: s.canDeleteTask},
+
+		// права на каждую задачу (как у UpdateTask) проверяет обработчик
+		pb.TaskService_BatchUpdateTasks_FullMethodName: {Any: rbac.PermTaskRead},
+	}
+}
+
+// AuthInterceptor проверяет bearer-токен для всех RPC, кроме PublicMethods, и права роли
+// по methodRules (таблица прав — s.Policy), после чего кладёт AuthContext в контекст обработчика
+func (s *TaskServer) AuthInterceptor() grpc.UnaryServerInterceptor {
+	rules := s.methodRules()
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if PublicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		userID, role, err := GetAuthContext(ctx, s.JwtService)
+		if err != nil || userID == "" {
+			return nil, GRPCError("unauthorized", codes.Unauthenticated)
+		}
+		rule, ok := rules[info.FullMethod]
+		if !ok {
+			return nil, GRPCError("forbidden", codes.PermissionDenied)
+		}
+		allowed, err := s.Policy.Check(ctx, rule, userID, role, req)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, GRPCError("forbidden", codes.PermissionDenied)
+		}
+		return handler(context.WithValue(ctx, authContextKey{}, &AuthContext{UserID: userID, Role: role}), req)
+	}
+}
+
+// authContext возвращает пользователя, проверенного интерцептором, или nil
+func authContext(ctx context.Context) *AuthContext {
+	auth, _ := ctx.Value(authContextKey{}).(*AuthContext)
+	return auth
+}
+
+// canEditTask — «своя» задача для изменения: личная задача, где пользователь исполнитель или создатель;
+// задача проекта — для его владельцев и редакторов, а также для исполнителя и создателя, если они участники
+func (s *TaskServer) canEditTask(ctx context.Context, userID string, req interface{}) (bool, error) {
+	task, err := s.requestTask(req)
+	if err != nil {
+		return false, err
+	}
+	own := task.AssigneeID.String() == userID || task.CreatorID.String() == userID
+	if task.ProjectID == nil {
+		return own, nil
+	}
+	member, err := s.projectMember(*task.ProjectID, userID)
+	if err != nil {
+		return false, GRPCError("internal error", codes.Internal)
+	}
+	return member != nil && (own || member.CanEdit()), nil
+}
+
+// canDeleteTask — «своя» задача для удаления: личная задача создателя;
+// задача проекта — для её создателя-участника и владельцев проекта
+func (s *TaskServer) canDeleteTask(ctx context.Context, userID string, req interface{}) (bool, error) {
+	task, err := s.requestTask(req)
+	if err != nil {
+		return false, err
+	}
+	own := task.CreatorID.String() == userID
+	if task.ProjectID == nil {
+		return own, nil
+	}
+	member, err := s.projectMember(*task.ProjectID, userID)
+	if err != nil {
+		return false, GRPCError("internal error", codes.Internal)
+	}
+	return member != nil && (own || member.Role == model.ProjectRoleOwner), nil
+}
+
+// isProjectOwner — «свой» проект: пользователь — его владелец
+func (s *TaskServer) isProjectOwner(ctx context.Context, userID string, req interface{}) (bool, error) {
+	r, ok := req.(interface{ GetProjectId() string })
+	if !ok {
+		return false, GRPCError("project_id is required", codes.InvalidArgument)
+	}
+	projectID, err := uuid.Parse(r.GetProjectId())
+	if err != nil {
+		return false, GRPCError("invalid project_id", codes.InvalidArgument)
+	}
+	member, err := s.projectMember(projectID, userID)
+	if err != nil {
+		return false, GRPCError("internal error", codes.Internal)
+	}
+	return member != nil && member.Role == model.ProjectRoleOwner, nil
+}
+
+// isColumnOwner — «своя» колонка: пользователь — владелец её проекта
+func (s *TaskServer) isColumnOwner(ctx context.Context, userID string, req interface{}) (bool, error) {
+	column, err := s.requestColumn(req)
+	if err != nil {
+		return false, err
+	}
+	member, err := s.projectMember(column.ProjectID, userID)
+	if err != nil {
+		return false, GRPCError("internal error", codes.Internal)
+	}
+	return member != nil && member.Role == model.ProjectRoleOwner, nil
+}
+
+// canDeleteComment — «свой» комментарий для удаления: пользователь — его автор
+// или владелец проекта, к задаче которого оставлен комментарий
+func (s *TaskServer) canDeleteComment(ctx context.Context, userID string, req interface{}) (bool, error) {
+	comment, err := s.requestComment(req)
+	if err != nil {
+		return false, err
+	}
+	if comment.AuthorID.String() == userID {
+		return true, nil
+	}
+	task, err := s.Repo.GetTaskByID(comment.TaskID.String())
+	if err != nil || task == nil || task.ProjectID == nil {
+		return false, nil
+	}
+	member, err := s.projectMember(*task.ProjectID, userID)
+	if err != nil {
+		return false, GRPCError("internal error", codes.Internal)
+	}
+	return member != nil && member.Role == model.ProjectRoleOwner, nil
+}
+
+// requestTask загружает задачу, на которую ссылается запрос (поле task_id)
+func (s *TaskServer) requestTask(req interface{}) (*model.Task, error) {
+	r, ok := req.(interface{ GetTaskId() string })
+	if !ok {
+		return nil, GRPCError("task_id is required", codes.InvalidArgument)
+	}
+	// задача из корзины тоже: права на RestoreTask проверяются по ней
+	task, err := s.Repo.GetTaskIncludingDeleted(r.GetTaskId())
+	if err != nil || task == nil {
+		return nil, GRPCError("task not found", codes.NotFound)
+	}
+	return task, nil
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	pb "task-service/proto"
+	"task-service/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultTasksPageSize = 20
+	maxTasksPageSize     = 100
+)
+
+// taskPageToken — содержимое page_token ListTasks (подписывается s.Cursors)
+type taskPageToken struct {
+	Query string              `json:"q"` // отпечаток условий выборки, для которых выдан токен
+	After *repository.TaskKey `json:"a"` // последняя задача предыдущей страницы
+}
+
+// Списки задачи, постраничные по времени создания записи (entryPageToken.List)
+const (
+	listComments = "comments"
+	listHistory  = "history"
+)
+
+// entryPageToken — содержимое page_token ListComments и GetTaskHistory (подписывается s.Cursors)
+type entryPageToken struct {
+	List   string    `json:"l"` // список, для которого выдан токен
+	TaskID uuid.UUID `json:"t"`
+	At     time.Time `json:"at"` // последняя запись предыдущей страницы
+	ID     uuid.UUID `json:"id"`
+}
+
+// decodeEntryToken возвращает последнюю запись предыдущей страницы списка list задачи taskID;
+// пустой токен — начало списка (нулевые значения)
+func (s *TaskServer) decodeEntryToken(pageToken, list string, taskID uuid.UUID) (time.Time, uuid.UUID, error) {
+	if pageToken == "" {
+		return time.Time{}, uuid.Nil, nil
+	}
+	var token entryPageToken
+	if err := s.Cursors.Decode(pageToken, &token); err != nil || token.List != list || token.TaskID != taskID {
+		return time.Time{}, uuid.Nil, GRPCError("invalid page_token", codes.InvalidArgument)
+	}
+	return token.At, token.ID, nil
+}
+
+// encodeEntryToken возвращает page_token страницы списка list задачи taskID после записи (at, id)
+func (s *TaskServer) encodeEntryToken(list string, taskID uuid.UUID, at time.Time, id uuid.UUID) (string, error) {
+	next, err := s.Cursors.Encode(entryPageToken{List: list, TaskID: taskID, At: at, ID: id})
+	if err != nil {
+		return "", GRPCError("internal error", codes.Internal)
+	}
+	return next, nil
+}
+
+// pageLimit возвращает размер страницы: 0 и меньше — def, не больше max
+func pageLimit(requested int32, def, max int) int {
+	switch {
+	case requested <= 0:
+		return def
+	case int(requested) > max:
+		return max
+	}
+	return int(requested)
+}
+
+// queryFingerprint возвращает отпечаток условий ListTasks без полей страницы:
+// токен, выданный для одной выборки, не принимается для другой
+func queryFingerprint(req *pb.ListTasksRequest) (string, error) {
+	q := proto.Clone(req).(*pb.ListTasksRequest)
+	q.Page, q.PageSize, q.PageToken, q.IncludeTotal = 0, 0, "", false
+	raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(q)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:16]), nil
+}
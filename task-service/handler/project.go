@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"shared/rbac"
+	"task-service/model"
+	pb "task-service/proto"
+	"task-service/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+func (s *TaskServer) CreateProject(ctx context.Context, req *pb.CreateProjectRequest) (*pb.CreateProjectResponse, error) {
+	if err := ValidateCreateProjectInput(req.Name); err != nil {
+		return nil, GRPCError(err.Error(), codes.InvalidArgument)
+	}
+	ownerID, err := uuid.Parse(authContext(ctx).UserID)
+	if err != nil {
+		return nil, GRPCError("invalid user_id in token", codes.Unauthenticated)
+	}
+	project := &model.Project{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Description: req.Description,
+		OwnerID:     ownerID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	log := newActivityLog(ctx)
+	log.add(model.EntityProject, project.ID, "create", "", "", project.Name)
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.CreateProject(project)
+	})
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.CreateProjectResponse{Project: toProtoProject(project)}, nil
+}
+
+const (
+	defaultProjectsPageSize = 20
+	maxProjectsPageSize     = 100
+)
+
+// ListProjects возвращает страницу проектов, где пользователь — участник (с правом project:manage:any — все);
+// total — число всех таких проектов
+func (s *TaskServer) ListProjects(ctx context.Context, req *pb.ListProjectsRequest) (*pb.ListProjectsResponse, error) {
+	auth := authContext(ctx)
+	memberID := uuid.Nil
+	if !s.Policy.Allows(auth.Role, rbac.PermProjectManageAny) {
+		id, err := uuid.Parse(auth.UserID)
+		if err != nil {
+			return nil, GRPCError("invalid user_id in token", codes.Unauthenticated)
+		}
+		memberID = id
+	}
+	limit := pageLimit(req.PageSize, defaultProjectsPageSize, maxProjectsPageSize)
+	offset := 0
+	if req.Page > 1 {
+		offset = int(req.Page-1) * limit
+	}
+	projects, err := s.Repo.ListProjects(memberID, offset, limit)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	total, err := s.Repo.CountProjects(memberID)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	var protoProjects []*pb.Project
+	for i := range projects {
+		protoProjects = append(protoProjects, toProtoProject(&projects[i]))
+	}
+	return &pb.ListProjectsResponse{Projects: protoProjects, Total: int32(total)}, nil
+}
+
+// AddMember добавляет пользователя в проект или меняет его роль (владелец проекта или project:manage:any)
+func (s *TaskServer) AddMember(ctx context.Context, req *pb.AddMemberRequest) (*pb.AddMemberResponse, error) {
+	projectID, err := uuid.Parse(req.ProjectId)
+	if err != nil {
+		return nil, GRPCError("invalid project_id", codes.InvalidArgument)
+	}
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, GRPCError("invalid user_id", codes.InvalidArgument)
+	}
+	role := req.Role
+	if role == "" {
+		role = model.ProjectRoleEditor
+	}
+	if !ValidProjectRoles[role] {
+		return nil, GRPCError("invalid role", codes.InvalidArgument)
+	}
+	project, err := s.Repo.GetProjectByID(projectID)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if project == nil {
+		return nil, GRPCError("project not found", codes.NotFound)
+	}
+	// создатель проекта всегда остаётся его владельцем
+	if userID == project.OwnerID && role != model.ProjectRoleOwner {
+		return nil, GRPCError("cannot change role of project creator", codes.FailedPrecondition)
+	}
+	previous, err := s.Repo.GetProjectMember(projectID, userID)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	oldRole := ""
+	if previous != nil {
+		oldRole = previous.Role
+	}
+	log := newActivityLog(ctx)
+	log.add(model.EntityProject, projectID, "member", "member:"+userID.String(), oldRole, role)
+	member := &model.ProjectMember{ProjectID: projectID, UserID: userID, Role: role, CreatedAt: time.Now()}
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.SaveProjectMember(member)
+	})
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.AddMemberResponse{ProjectId: req.ProjectId, UserId: req.UserId, Role: role}, nil
+}
+
+// projectMember возвращает участника проекта projectID с id userID или nil
+func (s *TaskServer) projectMember(projectID uuid.UUID, userID string) (*model.ProjectMember, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, nil
+	}
+	return s.Repo.GetProjectMember(projectID, id)
+}
+
+// taskVisible сообщает, видна ли задача пользователю: задача проекта — его участникам,
+// личная задача — создателю и исполнителю, любая — с правом project:manage:any
+func (s *TaskServer) taskVisible(auth *AuthContext, task *model.Task) (bool, error) {
+	if auth == nil {
+		return false, nil
+	}
+	if s.Policy.Allows(auth.Role, rbac.PermProjectManageAny) {
+		return true, nil
+	}
+	if task.ProjectID == nil {
+		return task.CreatorID.String() == auth.UserID || task.AssigneeID.String() == auth.UserID, nil
+	}
+	member, err := s.projectMember(*task.ProjectID, auth.UserID)
+	return member != nil, err
+}
+
+// visibleTask загружает задачу и проверяет, что она видна пользователю; чужая задача неотличима от отсутствующей
+func (s *TaskServer) visibleTask(ctx context.Context, taskID string) (*model.Task, error) {
+	task, err := s.Repo.GetTaskByID(taskID)
+	if err != nil || task == nil {
+		return nil, GRPCError("task not found", codes.NotFound)
+	}
+	visible, err := s.taskVisible(authContext(ctx), task)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if !visible {
+		return nil, GRPCError("task not found", codes.NotFound)
+	}
+	return task, nil
+}
+
+func toProtoProject(p *model.Project) *pb.Project {
+	return &pb.Project{
+		Id:          p.ID.String(),
+		Name:        p.Name,
+		Description: p.Description,
+		OwnerId:     p.OwnerID.String(),
+		CreatedAt:   p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   p.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -1,14 +1,20 @@
 package handler
 
 import (
+	"shared/cursor"
+	"shared/rbac"
 	pb "task-service/proto"
 	"task-service/repository"
 	"task-service/security"
+	"task-service/workflow"
 )
 
 type TaskServer struct {
 	pb.UnimplementedTaskServiceServer
 	Repo        *repository.TaskRepository
 	JwtService  *security.JWTService
+	Policy      *rbac.Policy       // роли и права; проверяются в AuthInterceptor
+	Workflow    *workflow.Workflow // статусы задач и допустимые переходы
+	Cursors     *cursor.Codec      // подпись page_token в ListTasks, ListComments и GetTaskHistory
 	RateLimiter *rateLimiter
 }
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"task-service/model"
+	pb "task-service/proto"
+	"task-service/workflow"
+
+	"google.golang.org/grpc/codes"
+)
+
+const statusEnumPrefix = "TASK_STATUS_"
+
+// statusFromProto переводит enum TaskStatus в строковый статус ("in_progress"); UNSPECIFIED — пустая строка
+func statusFromProto(code pb.TaskStatus) string {
+	if code == pb.TaskStatus_TASK_STATUS_UNSPECIFIED {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(code.String(), statusEnumPrefix))
+}
+
+// statusToProto переводит строковый статус в enum; статус не из встроенных — UNSPECIFIED
+func statusToProto(status string) pb.TaskStatus {
+	return pb.TaskStatus(pb.TaskStatus_value[statusEnumPrefix+strings.ToUpper(status)])
+}
+
+// requestedStatus возвращает статус из запроса, заданный строкой или enum; пусто — статус не указан
+func requestedStatus(status string, code pb.TaskStatus) (string, error) {
+	fromCode := statusFromProto(code)
+	switch {
+	case status == "":
+		return fromCode, nil
+	case fromCode != "" && fromCode != status:
+		return "", GRPCError("status and status_code do not match", codes.InvalidArgument)
+	}
+	return status, nil
+}
+
+// checkTransition проверяет переход задачи в статус to по workflow сервиса:
+// неизвестный статус — InvalidArgument, запрещённый переход — FailedPrecondition.
+// Открытые блокирующие задачи проверяет checkBlockers в транзакции записи.
+func (s *TaskServer) checkTransition(ctx context.Context, task *model.Task, to string) error {
+	role := ""
+	if auth := authContext(ctx); auth != nil {
+		role = auth.Role
+	}
+	err := s.Workflow.CheckTransition(task.Status, to, role)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, workflow.ErrUnknownStatus):
+		return GRPCError("unknown status: "+to, codes.InvalidArgument)
+	case errors.Is(err, workflow.ErrTransitionNotAllowed):
+		return GRPCError("status transition not allowed: "+task.Status+" -> "+to, codes.FailedPrecondition)
+	}
+	return GRPCError("internal error", codes.Internal)
+}
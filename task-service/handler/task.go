@@ -3,8 +3,11 @@ package handler
 import (
 	"context"
 	"errors"
+	"shared/rbac"
+	"strings"
 	"task-service/model"
 	pb "task-service/proto"
+	"task-service/repository"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,24 +18,47 @@ func (s *TaskServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest)
 	if err := ValidateCreateTaskInput(req.Title); err != nil {
 		return nil, GRPCError(err.Error(), codes.InvalidArgument)
 	}
-	userID, _, _ := GetAuthContext(ctx, s.JwtService)
+	var err error
 	creatorUUID := uuid.Nil
-	if userID != "" {
-		if id, err := uuid.Parse(userID); err == nil {
+	if auth := authContext(ctx); auth != nil {
+		if id, err := uuid.Parse(auth.UserID); err == nil {
 			creatorUUID = id
 		}
 	}
+	// подзадача по умолчанию создаётся в проекте родителя
+	projectField := req.ProjectId
+	var parent *model.Task
+	if req.ParentId != "" {
+		if parent, err = s.visibleTask(ctx, req.ParentId); err != nil {
+			return nil, GRPCError("parent task not found", codes.NotFound)
+		}
+		if projectField == "" {
+			projectField = uuidValue(parent.ProjectID)
+		}
+	}
+	projectID, err := s.projectForNewTask(ctx, projectField)
+	if err != nil {
+		return nil, err
+	}
+	if parent != nil && uuidValue(parent.ProjectID) != uuidValue(projectID) {
+		return nil, GRPCError("subtask must belong to the parent's project", codes.InvalidArgument)
+	}
 	task := &model.Task{
 		ID:          uuid.New(),
+		ProjectID:   projectID,
 		Title:       req.Title,
 		Description: req.Description,
-		Status:      "todo",
+		Status:      s.Workflow.Initial,
+		Version:     1,
 		AssigneeID:  uuid.Nil,
 		CreatorID:   creatorUUID, // Теперь CreatorID берётся из JWT
 		Labels:      req.Labels,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	if parent != nil {
+		task.ParentID = &parent.ID
+	}
 	if req.AssigneeId != "" {
 		if id, err := uuid.Parse(req.AssigneeId); err == nil {
 			task.AssigneeID = id
@@ -43,125 +69,387 @@ func (s *TaskServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest)
 			task.DueDate = &due
 		}
 	}
-	if err := s.Repo.CreateTask(task); err != nil {
-		return nil, err
+	log := newActivityLog(ctx)
+	log.add(model.EntityTask, task.ID, "create", "", "", task.Title)
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return s.createTask(repo, log, task)
+	})
+	if err != nil {
+		return nil, columnError(err)
 	}
 	return &pb.CreateTaskResponse{TaskId: task.ID.String()}, nil
 }
 
 func (s *TaskServer) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.GetTaskResponse, error) {
-	task, err := s.Repo.GetTaskByID(req.TaskId)
+	task, err := s.visibleTask(ctx, req.TaskId)
 	if err != nil {
 		return nil, err
 	}
-	if task == nil {
-		return nil, errors.New("task not found")
+	tasks, err := s.protoTasks(*task)
+	if err != nil {
+		return nil, err
 	}
-	return &pb.GetTaskResponse{Task: toProtoTask(task)}, nil
+	return &pb.GetTaskResponse{Task: tasks[0]}, nil
 }
 
+// UpdateTask изменяет поля задачи из update_mask (без маски — title, description, labels и переданные
+// assignee_id, due_date, status)
 func (s *TaskServer) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*pb.UpdateTaskResponse, error) {
-	if err := ValidateUpdateTaskInput(req.Title); err != nil {
-		return nil, GRPCError(err.Error(), codes.InvalidArgument)
+	fields, err := taskUpdateFields(req)
+	if err != nil {
+		return nil, err
 	}
-	userID, role, err := GetAuthContext(ctx, s.JwtService)
+	status, err := requestedStatus(req.Status, req.StatusCode)
 	if err != nil {
-		return nil, GRPCError("unauthorized", codes.Unauthenticated)
+		return nil, err
 	}
-	task, err := s.Repo.GetTaskByID(req.TaskId)
-	if err != nil || task == nil {
-		return nil, GRPCError("task not found", codes.NotFound)
+	if fields["status"] && status == "" {
+		return nil, GRPCError("status is required", codes.InvalidArgument)
 	}
-	// Только исполнитель, создатель или админ может обновлять задачу
-	if userID != task.AssigneeID.String() && userID != task.CreatorID.String() && role != "admin" {
-		return nil, GRPCError("forbidden", codes.PermissionDenied)
+	task, err := s.visibleTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, err
 	}
-	task.Title = req.Title
-	task.Description = req.Description
-	if req.AssigneeId != "" {
-		if id, err := uuid.Parse(req.AssigneeId); err == nil {
-			task.AssigneeID = id
-		}
+	if req.ExpectedVersion != 0 && req.ExpectedVersion != task.Version {
+		return nil, VersionMismatchError("task/"+task.ID.String(), "task version mismatch")
 	}
-	if req.DueDate != "" {
-		if due, err := time.Parse(time.RFC3339, req.DueDate); err == nil {
-			task.DueDate = &due
+	before := *task
+	if err := applyTaskUpdate(task, req, fields); err != nil {
+		return nil, err
+	}
+	if fields["status"] {
+		if err := s.checkTransition(ctx, task, status); err != nil {
+			return nil, err
 		}
+		task.Status = status
 	}
-	task.Labels = req.Labels
 	task.UpdatedAt = time.Now()
-	if err := s.Repo.UpdateTask(task); err != nil {
-		return nil, err
+	log := newActivityLog(ctx)
+	log.taskChanges(&before, task)
+	// задачу могли изменить между чтением и записью: тогда изменения не сохраняются
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		if err := s.checkBlockers(repo, task.ID, before.Status, task.Status); err != nil {
+			return err
+		}
+		return repo.UpdateTask(task)
+	})
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return nil, GRPCError(err.Error(), codes.Aborted)
+	}
+	if errors.Is(err, repository.ErrTaskBlocked) {
+		return nil, GRPCError(err.Error(), codes.FailedPrecondition)
+	}
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
 	}
-	return &pb.UpdateTaskResponse{TaskId: task.ID.String()}, nil
+	return &pb.UpdateTaskResponse{TaskId: task.ID.String(), Version: task.Version}, nil
 }
 
+// DeleteTask переносит задачу в корзину; вернуть её можно через RestoreTask
 func (s *TaskServer) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*pb.DeleteTaskResponse, error) {
-	userID, role, err := GetAuthContext(ctx, s.JwtService)
+	task, err := s.visibleTask(ctx, req.TaskId)
 	if err != nil {
-		return &pb.DeleteTaskResponse{Success: false}, GRPCError("unauthorized", codes.Unauthenticated)
+		return &pb.DeleteTaskResponse{Success: false}, err
 	}
-	task, err := s.Repo.GetTaskByID(req.TaskId)
-	if err != nil || task == nil {
-		return &pb.DeleteTaskResponse{Success: false}, GRPCError("task not found", codes.NotFound)
-	}
-	if userID != task.CreatorID.String() && role != "admin" {
-		return &pb.DeleteTaskResponse{Success: false}, GRPCError("forbidden", codes.PermissionDenied)
-	}
-	if err := s.Repo.DeleteTask(req.TaskId); err != nil {
+	log := newActivityLog(ctx)
+	log.add(model.EntityTask, task.ID, "delete", "", task.Title, "")
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.DeleteTask(req.TaskId)
+	})
+	if err != nil {
 		return &pb.DeleteTaskResponse{Success: false}, GRPCError("internal error", codes.Internal)
 	}
 	return &pb.DeleteTaskResponse{Success: true}, nil
 }
 
+// ListTasks возвращает страницу видимых пользователю задач по фильтру. Следующая страница —
+// по next_page_token (keyset по полям сортировки и id); page — устаревшая страница по смещению.
 func (s *TaskServer) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.ListTasksResponse, error) {
-	offset := int(req.Page-1) * int(req.PageSize)
-	limit := int(req.PageSize)
-	tasks, err := s.Repo.ListTasks(req.Status, offset, limit)
+	filter, err := s.taskFilter(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	var protoTasks []*pb.Task
-	for _, t := range tasks {
-		protoTasks = append(protoTasks, toProtoTask(&t))
+	limit := pageLimit(req.PageSize, defaultTasksPageSize, maxTasksPageSize)
+	// на один больше, чтобы узнать, есть ли следующая страница
+	page := repository.TaskPage{Limit: limit + 1, Total: req.IncludeTotal}
+	query, err := queryFingerprint(req)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if req.PageToken != "" {
+		var token taskPageToken
+		if err := s.Cursors.Decode(req.PageToken, &token); err != nil || token.Query != query || token.After == nil {
+			return nil, GRPCError("invalid page_token", codes.InvalidArgument)
+		}
+		page.After = token.After
+	} else if req.Page > 1 {
+		page.Offset = int(req.Page-1) * limit
+	}
+	tasks, total, err := s.Repo.ListTasks(filter, page)
+	if errors.Is(err, repository.ErrInvalidTaskKey) {
+		return nil, GRPCError("invalid page_token", codes.InvalidArgument)
+	}
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	var next string
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+		after := repository.TaskKeyOf(repository.TaskSortOrder(filter), &tasks[limit-1])
+		if next, err = s.Cursors.Encode(taskPageToken{Query: query, After: after}); err != nil {
+			return nil, GRPCError("internal error", codes.Internal)
+		}
+	}
+	protoTasks, err := s.protoTasks(tasks...)
+	if err != nil {
+		return nil, err
 	}
-	return &pb.ListTasksResponse{Tasks: protoTasks, Total: int32(len(protoTasks))}, nil
+	return &pb.ListTasksResponse{Tasks: protoTasks, Total: int32(total), NextPageToken: next}, nil
 }
 
+// ChangeStatus переводит задачу в новый статус, если переход разрешён workflow
 func (s *TaskServer) ChangeStatus(ctx context.Context, req *pb.ChangeStatusRequest) (*pb.ChangeStatusResponse, error) {
-	userID, role, err := GetAuthContext(ctx, s.JwtService)
+	status, err := requestedStatus(req.Status, req.StatusCode)
 	if err != nil {
-		return &pb.ChangeStatusResponse{Success: false}, GRPCError("unauthorized", codes.Unauthenticated)
+		return &pb.ChangeStatusResponse{Success: false}, err
 	}
-	task, err := s.Repo.GetTaskByID(req.TaskId)
-	if err != nil || task == nil {
-		return &pb.ChangeStatusResponse{Success: false}, GRPCError("task not found", codes.NotFound)
+	if status == "" {
+		return &pb.ChangeStatusResponse{Success: false}, GRPCError("status is required", codes.InvalidArgument)
 	}
-	// Только исполнитель, создатель или админ может менять статус
-	if userID != task.AssigneeID.String() && userID != task.CreatorID.String() && role != "admin" {
-		return &pb.ChangeStatusResponse{Success: false}, GRPCError("forbidden", codes.PermissionDenied)
+	task, err := s.visibleTask(ctx, req.TaskId)
+	if err != nil {
+		return &pb.ChangeStatusResponse{Success: false}, err
+	}
+	if err := s.checkTransition(ctx, task, status); err != nil {
+		return &pb.ChangeStatusResponse{Success: false}, err
 	}
-	if err := s.Repo.ChangeStatus(req.TaskId, req.Status); err != nil {
+	log := newActivityLog(ctx)
+	log.change(model.EntityTask, task.ID, "status", task.Status, status)
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		if err := s.checkBlockers(repo, task.ID, task.Status, status); err != nil {
+			return err
+		}
+		return repo.ChangeStatus(task.ID, task.Status, status)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return &pb.ChangeStatusResponse{Success: false}, GRPCError(err.Error(), codes.Aborted)
+		}
+		if errors.Is(err, repository.ErrTaskBlocked) {
+			return &pb.ChangeStatusResponse{Success: false}, GRPCError(err.Error(), codes.FailedPrecondition)
+		}
 		return &pb.ChangeStatusResponse{Success: false}, GRPCError("internal error", codes.Internal)
 	}
 	return &pb.ChangeStatusResponse{Success: true}, nil
 }
 
 func toProtoTask(t *model.Task) *pb.Task {
-	var due string
+	var due, projectID, columnID, parentID string
 	if t.DueDate != nil {
 		due = t.DueDate.Format(time.RFC3339)
 	}
-	return &pb.Task{
+	if t.ProjectID != nil {
+		projectID = t.ProjectID.String()
+	}
+	if t.ColumnID != nil {
+		columnID = t.ColumnID.String()
+	}
+	if t.ParentID != nil {
+		parentID = t.ParentID.String()
+	}
+	task := &pb.Task{
 		Id:          t.ID.String(),
 		Title:       t.Title,
 		Description: t.Description,
 		Status:      t.Status,
+		StatusCode:  statusToProto(t.Status),
 		AssigneeId:  t.AssigneeID.String(),
 		CreatorId:   t.CreatorID.String(),
 		DueDate:     due,
 		Labels:      t.Labels,
 		CreatedAt:   t.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   t.UpdatedAt.Format(time.RFC3339),
+		ProjectId:   projectID,
+		ColumnId:    columnID,
+		Rank:        t.Rank,
+		ParentId:    parentID,
+		Version:     t.Version,
+		ArchivedAt:  timeValue(t.ArchivedAt),
+	}
+	if t.DeletedAt.Valid {
+		task.DeletedAt = timeValue(&t.DeletedAt.Time)
+	}
+	return task
+}
+
+// projectForNewTask проверяет проект новой задачи: создавать задачи могут владельцы и редакторы
+// проекта (или пользователи с правом project:manage:any); пустой projectID — личная задача
+func (s *TaskServer) projectForNewTask(ctx context.Context, projectID string) (*uuid.UUID, error) {
+	if projectID == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(projectID)
+	if err != nil {
+		return nil, GRPCError("invalid project_id", codes.InvalidArgument)
+	}
+	project, err := s.Repo.GetProjectByID(id)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if project == nil {
+		return nil, GRPCError("project not found", codes.NotFound)
+	}
+	auth := authContext(ctx)
+	if s.Policy.Allows(auth.Role, rbac.PermProjectManageAny) {
+		return &id, nil
+	}
+	member, err := s.projectMember(id, auth.UserID)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if member == nil || !member.CanEdit() {
+		return nil, GRPCError("forbidden", codes.PermissionDenied)
+	}
+	return &id, nil
+}
+
+// searchFilter разбирает условия поиска ListTasks: статус, участники, метки, сроки, текст и сортировку
+func (s *TaskServer) searchFilter(req *pb.ListTasksRequest) (repository.TaskFilter, error) {
+	filter := repository.TaskFilter{
+		Status:  req.Status,
+		Labels:  req.Labels,
+		Overdue: req.Overdue,
+		Search:  strings.TrimSpace(req.Search),
+	}
+	if req.Status != "" && !s.Workflow.IsStatus(req.Status) {
+		return filter, GRPCError("unknown status: "+req.Status, codes.InvalidArgument)
+	}
+	var err error
+	if filter.AssigneeID, err = optionalUUID(req.AssigneeId); err != nil {
+		return filter, GRPCError("invalid assignee_id", codes.InvalidArgument)
+	}
+	if filter.CreatorID, err = optionalUUID(req.CreatorId); err != nil {
+		return filter, GRPCError("invalid creator_id", codes.InvalidArgument)
+	}
+	switch req.LabelsMatch {
+	case "", "any":
+	case "all":
+		filter.AllLabels = true
+	default:
+		return filter, GRPCError("labels_match must be any or all", codes.InvalidArgument)
+	}
+	switch req.View {
+	case "", repository.TaskViewActive, repository.TaskViewArchived, repository.TaskViewTrash:
+		filter.View = req.View
+	default:
+		return filter, GRPCError("view must be active, archived or trash", codes.InvalidArgument)
+	}
+	if filter.DueFrom, err = optionalTime(req.DueFrom); err != nil {
+		return filter, GRPCError("invalid due_from", codes.InvalidArgument)
+	}
+	if filter.DueTo, err = optionalTime(req.DueTo); err != nil {
+		return filter, GRPCError("invalid due_to", codes.InvalidArgument)
+	}
+	if req.Overdue {
+		filter.DoneStatuses = s.Workflow.Done
+	}
+	if filter.Sort, err = parseTaskSort(req.Sort); err != nil {
+		return filter, GRPCError(err.Error(), codes.InvalidArgument)
+	}
+	return filter, nil
+}
+
+// parseTaskSort разбирает сортировку вида "-due_date,title": "-" — по убыванию
+func parseTaskSort(sort string) ([]repository.TaskSort, error) {
+	var result []repository.TaskSort
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+		if !repository.IsTaskSortField(field) {
+			return nil, errors.New("unknown sort field: " + field)
+		}
+		result = append(result, repository.TaskSort{Field: field, Desc: desc})
+	}
+	return result, nil
+}
+
+// optionalUUID разбирает необязательный идентификатор; пустая строка — uuid.Nil
+func optionalUUID(s string) (uuid.UUID, error) {
+	if s == "" {
+		return uuid.Nil, nil
+	}
+	return uuid.Parse(s)
+}
+
+// optionalTime разбирает необязательное время в RFC3339; пустая строка — nil
+func optionalTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// taskFilter переводит условия запроса ListTasks в фильтр репозитория
+// и ограничивает выборку задачами, видимыми пользователю
+func (s *TaskServer) taskFilter(ctx context.Context, req *pb.ListTasksRequest) (repository.TaskFilter, error) {
+	filter, err := s.searchFilter(req)
+	if err != nil {
+		return filter, err
+	}
+	if req.ParentId != "" {
+		parent, err := s.visibleTask(ctx, req.ParentId)
+		if err != nil {
+			return filter, err
+		}
+		filter.ParentID = parent.ID
+	}
+	if req.ColumnId != "" {
+		id, err := uuid.Parse(req.ColumnId)
+		if err != nil {
+			return filter, GRPCError("invalid column_id", codes.InvalidArgument)
+		}
+		column, err := s.Repo.GetColumnByID(id)
+		if err != nil {
+			return filter, GRPCError("internal error", codes.Internal)
+		}
+		if column == nil {
+			return filter, GRPCError("column not found", codes.NotFound)
+		}
+		if req.ProjectId != "" && req.ProjectId != column.ProjectID.String() {
+			return filter, GRPCError("column does not belong to project", codes.InvalidArgument)
+		}
+		if err := s.checkProjectRead(ctx, column.ProjectID); err != nil {
+			return filter, err
+		}
+		filter.ProjectID = column.ProjectID
+		filter.ColumnID = id
+		return filter, nil
+	}
+	if req.ProjectId != "" {
+		id, err := uuid.Parse(req.ProjectId)
+		if err != nil {
+			return filter, GRPCError("invalid project_id", codes.InvalidArgument)
+		}
+		if err := s.checkProjectRead(ctx, id); err != nil {
+			return filter, err
+		}
+		filter.ProjectID = id
+		return filter, nil
+	}
+	if !s.Policy.Allows(authContext(ctx).Role, rbac.PermProjectManageAny) {
+		id, err := uuid.Parse(authContext(ctx).UserID)
+		if err != nil {
+			return filter, GRPCError("invalid user_id in token", codes.Unauthenticated)
+		}
+		filter.VisibleTo = id
 	}
+	return filter, nil
 }
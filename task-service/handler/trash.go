@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"task-service/model"
+	pb "task-service/proto"
+	"task-service/repository"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ArchiveTask переносит задачу в архив: она пропадает из ListTasks по умолчанию и с доски
+// (не занимает место в WIP-лимите), но остаётся доступной по id
+func (s *TaskServer) ArchiveTask(ctx context.Context, req *pb.ArchiveTaskRequest) (*pb.ArchiveTaskResponse, error) {
+	task, err := s.visibleTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	if task.ArchivedAt != nil {
+		return nil, GRPCError("task is already archived", codes.FailedPrecondition)
+	}
+	now := time.Now()
+	log := newActivityLog(ctx)
+	log.add(model.EntityTask, task.ID, "archive", "", "", "")
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.SetArchived(task.ID, &now)
+	})
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	protoTask, err := s.reloadTask(task.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ArchiveTaskResponse{Task: protoTask}, nil
+}
+
+// UnarchiveTask возвращает задачу из архива
+func (s *TaskServer) UnarchiveTask(ctx context.Context, req *pb.UnarchiveTaskRequest) (*pb.UnarchiveTaskResponse, error) {
+	task, err := s.visibleTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	if task.ArchivedAt == nil {
+		return nil, GRPCError("task is not archived", codes.FailedPrecondition)
+	}
+	log := newActivityLog(ctx)
+	log.add(model.EntityTask, task.ID, "unarchive", "", "", "")
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.SetArchived(task.ID, nil)
+	})
+	if err != nil {
+		return nil, columnError(err)
+	}
+	protoTask, err := s.reloadTask(task.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UnarchiveTaskResponse{Task: protoTask}, nil
+}
+
+// RestoreTask возвращает задачу из корзины (в архив, если она была архивной)
+func (s *TaskServer) RestoreTask(ctx context.Context, req *pb.RestoreTaskRequest) (*pb.RestoreTaskResponse, error) {
+	task, err := s.Repo.GetTaskIncludingDeleted(req.TaskId)
+	if err != nil || task == nil {
+		return nil, GRPCError("task not found", codes.NotFound)
+	}
+	visible, err := s.taskVisible(authContext(ctx), task)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if !visible {
+		return nil, GRPCError("task not found", codes.NotFound)
+	}
+	if !task.DeletedAt.Valid {
+		return nil, GRPCError("task is not deleted", codes.FailedPrecondition)
+	}
+	log := newActivityLog(ctx)
+	log.add(model.EntityTask, task.ID, "restore", "", "", task.Title)
+	err = s.mutate(log, func(repo *repository.TaskRepository) error {
+		return repo.RestoreTask(task.ID)
+	})
+	if err != nil {
+		return nil, columnError(err)
+	}
+	protoTask, err := s.reloadTask(task.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RestoreTaskResponse{Task: protoTask}, nil
+}
+
+// reloadTask перечитывает задачу после изменения
+func (s *TaskServer) reloadTask(id string) (*pb.Task, error) {
+	task, err := s.Repo.GetTaskByID(id)
+	if err != nil || task == nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	tasks, err := s.protoTasks(*task)
+	if err != nil {
+		return nil, err
+	}
+	return tasks[0], nil
+}
+
+// StartTrashPurge раз в interval окончательно удаляет задачи, пролежавшие в корзине дольше retention
+func (s *TaskServer) StartTrashPurge(retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			purged, err := s.Repo.PurgeDeletedTasks(time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("trash purge failed: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("trash purge: %d tasks deleted permanently", purged)
+			}
+		}
+	}()
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"task-service/model"
+	pb "task-service/proto"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+// updatableTaskFields — поля, которые можно указать в update_mask UpdateTask
+var updatableTaskFields = map[string]bool{
+	"title":       true,
+	"description": true,
+	"assignee_id": true,
+	"due_date":    true,
+	"labels":      true,
+	"status":      true,
+}
+
+// taskUpdateFields возвращает поля задачи, которые меняет запрос. С update_mask — ровно перечисленные
+// (status_code — то же, что status); без него — как раньше: title, description и labels всегда,
+// assignee_id, due_date и status — только если переданы и корректны.
+func taskUpdateFields(req *pb.UpdateTaskRequest) (map[string]bool, error) {
+	fields := map[string]bool{}
+	if len(req.GetUpdateMask().GetPaths()) == 0 {
+		fields["title"], fields["description"], fields["labels"] = true, true, true
+		if _, err := uuid.Parse(req.AssigneeId); err == nil {
+			fields["assignee_id"] = true
+		}
+		if _, err := time.Parse(time.RFC3339, req.DueDate); err == nil {
+			fields["due_date"] = true
+		}
+		fields["status"] = req.Status != "" || req.StatusCode != pb.TaskStatus_TASK_STATUS_UNSPECIFIED
+		return fields, nil
+	}
+	for _, path := range req.UpdateMask.Paths {
+		if path == "status_code" {
+			path = "status"
+		}
+		if !updatableTaskFields[path] {
+			return nil, GRPCError("unknown update_mask field: "+path, codes.InvalidArgument)
+		}
+		fields[path] = true
+	}
+	return fields, nil
+}
+
+// applyTaskUpdate переносит в задачу поля fields из запроса (кроме статуса — он проверяется
+// по workflow отдельно). Пустые assignee_id и due_date в маске снимают исполнителя и срок.
+func applyTaskUpdate(task *model.Task, req *pb.UpdateTaskRequest, fields map[string]bool) error {
+	if fields["title"] {
+		if err := ValidateUpdateTaskInput(req.Title); err != nil {
+			return GRPCError(err.Error(), codes.InvalidArgument)
+		}
+		task.Title = req.Title
+	}
+	if fields["description"] {
+		task.Description = req.Description
+	}
+	if fields["assignee_id"] {
+		task.AssigneeID = uuid.Nil
+		if req.AssigneeId != "" {
+			id, err := uuid.Parse(req.AssigneeId)
+			if err != nil {
+				return GRPCError("invalid assignee_id", codes.InvalidArgument)
+			}
+			task.AssigneeID = id
+		}
+	}
+	if fields["due_date"] {
+		task.DueDate = nil
+		if req.DueDate != "" {
+			due, err := time.Parse(time.RFC3339, req.DueDate)
+			if err != nil {
+				return GRPCError("invalid due_date", codes.InvalidArgument)
+			}
+			task.DueDate = &due
+		}
+	}
+	if fields["labels"] {
+		task.Labels = req.Labels
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"task-service/security"
 
@@ -24,7 +25,14 @@ func GetAuthContext(ctx context.Context, jwtService *security.JWTService) (userI
 	if err != nil {
 		return "", "", err
 	}
+	// токен, выданный до подтверждения email, годится только для user-service
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		return "", "", errors.New("email not confirmed")
+	}
 	uid, _ := claims["user_id"].(string)
+	if uid == "" {
+		uid, _ = claims["sub"].(string)
+	}
 	role, _ = claims["role"].(string)
 	return uid, role, nil
 }
@@ -3,6 +3,8 @@ package handler
 import (
 	"errors"
 	"strings"
+	"task-service/model"
+	"unicode/utf8"
 )
 
 func ValidateCreateTaskInput(title string) error {
@@ -18,3 +20,40 @@ func ValidateUpdateTaskInput(title string) error {
 	}
 	return nil
 }
+
+// ValidProjectRoles — допустимые роли участника проекта
+var ValidProjectRoles = map[string]bool{
+	model.ProjectRoleOwner:  true,
+	model.ProjectRoleEditor: true,
+	model.ProjectRoleViewer: true,
+}
+
+func ValidateCreateProjectInput(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func ValidateColumnInput(name string, wipLimit int32) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("name is required")
+	}
+	if wipLimit < 0 {
+		return errors.New("wip_limit must not be negative")
+	}
+	return nil
+}
+
+// MaxCommentLength — максимальная длина текста комментария в символах
+const MaxCommentLength = 10000
+
+func ValidateCommentInput(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return errors.New("body is required")
+	}
+	if utf8.RuneCountInString(body) > MaxCommentLength {
+		return errors.New("body is too long")
+	}
+	return nil
+}
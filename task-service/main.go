@@ -3,12 +3,18 @@ package main
 import (
 	"log"
 	"net"
+	"time"
 
+	"shared/cursor"
+	"shared/jwks"
+	"shared/rbac"
+	"shared/revocation"
 	"task-service/config"
 	"task-service/handler"
 	"task-service/proto"
 	"task-service/repository"
 	"task-service/security"
+	"task-service/workflow"
 
 	"google.golang.org/grpc"
 	"gorm.io/driver/postgres"
@@ -25,16 +31,46 @@ func main() {
 
 	repo := repository.NewTaskRepository(db)
 	jwtService := security.NewJWTService(cfg.JWTSecret)
+	if cfg.JWKSUrl != "" {
+		jwtService = security.NewJWKSService(jwks.NewKeySet(cfg.JWKSUrl))
+	}
+	jwtService.WithIssuer(cfg.JWTIssuer, cfg.JWTAudience)
+	if cfg.RevocationsURL != "" {
+		jwtService.WithRevocations(revocation.NewList(cfg.RevocationsURL))
+	}
+	policy := rbac.NewPolicy(rbac.DefaultRoles())
+	if cfg.PolicyURL != "" {
+		// до первой успешной загрузки действуют роли по умолчанию
+		if err := policy.WithSource(cfg.PolicyURL).Refresh(); err != nil {
+			log.Printf("policy load failed, using default roles: %v", err)
+		}
+		policy.StartRefresh(30 * time.Second)
+	}
+
+	taskWorkflow := workflow.Default()
+	if cfg.WorkflowFile != "" {
+		taskWorkflow, err = workflow.Load(cfg.WorkflowFile)
+		if err != nil {
+			log.Fatalf("failed to load workflow: %v", err)
+		}
+	}
 
 	lis, err := net.Listen("tcp", ":"+cfg.Port)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	s := grpc.NewServer()
-	proto.RegisterTaskServiceServer(s, &handler.TaskServer{
+	taskServer := &handler.TaskServer{
 		Repo:       repo,
 		JwtService: jwtService,
-	})
+		Policy:     policy,
+		Workflow:   taskWorkflow,
+		Cursors:    cursor.NewCodec(cursor.Secret(cfg.CursorSecret)),
+	}
+	if cfg.TrashRetentionDays > 0 {
+		taskServer.StartTrashPurge(time.Duration(cfg.TrashRetentionDays)*24*time.Hour, time.Hour)
+	}
+	s := grpc.NewServer(grpc.UnaryInterceptor(taskServer.AuthInterceptor()))
+	proto.RegisterTaskServiceServer(s, taskServer)
 
 	log.Printf("task-service started on :%s", cfg.Port)
 	if err := s.Serve(lis); err != nil {
@@ -0,0 +1,3 @@
+-- +migrate Down
+-- статус archived больше не используется: перенесённые задачи остаются в архиве
+SELECT 1;
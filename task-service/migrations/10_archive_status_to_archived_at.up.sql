@@ -0,0 +1,4 @@
+-- +migrate Up
+-- архив — только archived_at: задачи со статусом archived переносятся в архив со статусом backlog
+-- (если в WORKFLOW_FILE его нет, задачу можно перевести в начальный статус, как из любого статуса вне workflow)
+UPDATE tasks SET archived_at = COALESCE(archived_at, updated_at, NOW()), status = 'backlog' WHERE status = 'archived';
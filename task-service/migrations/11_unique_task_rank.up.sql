@@ -0,0 +1,16 @@
+-- +migrate Up
+-- позиции задач на доске уникальны в колонке. Колонки, где параллельные вставки успели дать одинаковые
+-- позиции, перенумеровываются в текущем порядке: позиции одной длины из цифр, не оканчиваются на '0'
+UPDATE tasks t SET rank = lpad(r.n::text, 6, '0') || 'i'
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY column_id ORDER BY rank, id) AS n
+    FROM tasks
+    WHERE archived_at IS NULL AND deleted_at IS NULL AND column_id IN (
+        SELECT column_id FROM tasks
+        WHERE column_id IS NOT NULL AND archived_at IS NULL AND deleted_at IS NULL
+        GROUP BY column_id, rank HAVING COUNT(*) > 1
+    )
+) r
+WHERE t.id = r.id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_column_rank_unique ON tasks(column_id, rank)
+    WHERE archived_at IS NULL AND deleted_at IS NULL;
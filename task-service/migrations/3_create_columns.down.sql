@@ -0,0 +1,5 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_tasks_column_rank;
+ALTER TABLE tasks DROP COLUMN IF EXISTS rank;
+ALTER TABLE tasks DROP COLUMN IF EXISTS column_id;
+DROP TABLE IF EXISTS columns;
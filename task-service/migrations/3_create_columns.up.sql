@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS columns (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    rank TEXT COLLATE "C" NOT NULL,
+    wip_limit INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_columns_project_id ON columns(project_id, rank);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS column_id UUID REFERENCES columns(id);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS rank TEXT COLLATE "C" NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_tasks_column_rank ON tasks(column_id, rank);
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP TABLE IF EXISTS comment_revisions;
+DROP TABLE IF EXISTS comment_mentions;
+DROP TABLE IF EXISTS comments;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- журнал действий не ссылается на объекты внешними ключами: записи переживают удаление задачи
+CREATE TABLE IF NOT EXISTS activities (
+    id UUID PRIMARY KEY,
+    entity_type VARCHAR(16) NOT NULL,
+    entity_id UUID NOT NULL,
+    actor_id UUID NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    field VARCHAR(32) NOT NULL DEFAULT '',
+    old_value TEXT NOT NULL DEFAULT '',
+    new_value TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_activities_entity ON activities(entity_type, entity_id, created_at, id);
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- поисковый вектор пересчитывается самим Postgres при изменении названия или описания
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(description, ''))) STORED;
+CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON tasks USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_tasks_labels ON tasks USING GIN (labels);
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee_id ON tasks(assignee_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_creator_id ON tasks(creator_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks(due_date);
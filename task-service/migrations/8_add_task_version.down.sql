@@ -0,0 +1,2 @@
+-- +migrate Down
+ALTER TABLE tasks DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+-- +migrate Up
+-- версия задачи для оптимистичной блокировки: UpdateTask сохраняет изменения, только если версия не изменилась
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
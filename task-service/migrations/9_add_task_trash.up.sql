@@ -0,0 +1,6 @@
+-- +migrate Up
+-- архив и корзина: удалённые задачи хранятся до окончательного удаления (TRASH_RETENTION_DAYS)
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_tasks_archived_at ON tasks(archived_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at);
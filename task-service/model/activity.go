@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Типы объектов журнала действий
+const (
+	EntityTask    = "task"
+	EntityProject = "project"
+	EntityColumn  = "column"
+)
+
+// Activity — запись журнала действий: кто (ActorID) и когда изменил поле Field объекта
+// EntityType/EntityID с OldValue на NewValue. Записи только добавляются, вместе с самим изменением.
+type Activity struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	EntityType string    `gorm:"index:idx_activities_entity"`
+	EntityID   uuid.UUID `gorm:"type:uuid;index:idx_activities_entity"`
+	ActorID    uuid.UUID `gorm:"type:uuid"`
+	Action     string    // create, update, delete, move, comment_add, ...
+	Field      string    // изменённое поле; пусто — действие над объектом целиком
+	OldValue   string
+	NewValue   string
+	CreatedAt  time.Time
+}
+
+func (a *Activity) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
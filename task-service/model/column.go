@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Column — колонка Kanban-доски проекта. Колонки и задачи внутри колонки
+// упорядочены по Rank (см. пакет rank).
+type Column struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ProjectID uuid.UUID `gorm:"type:uuid;index"`
+	Name      string
+	Rank      string // позиция колонки на доске
+	WIPLimit  int    // максимум задач в колонке; 0 — без ограничения
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (c *Column) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
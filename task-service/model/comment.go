@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Comment — комментарий к задаче. Body — markdown. Удалённый комментарий остаётся
+// в обсуждении без текста (DeletedAt != nil); прежние тексты хранятся в CommentRevision.
+type Comment struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TaskID    uuid.UUID `gorm:"type:uuid;index"`
+	AuthorID  uuid.UUID `gorm:"type:uuid"`
+	Body      string
+	Mentions  []CommentMention `gorm:"foreignKey:CommentID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	EditedAt  *time.Time // время последнего изменения текста; nil — не редактировался
+	DeletedAt *time.Time
+}
+
+func (c *Comment) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// CommentMention — пользователь, упомянутый в комментарии (@<user_id>)
+type CommentMention struct {
+	CommentID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+}
+
+// CommentRevision — прежний текст комментария, сохранённый при правке или удалении
+type CommentRevision struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CommentID uuid.UUID `gorm:"type:uuid;index"`
+	Body      string
+	EditorID  uuid.UUID `gorm:"type:uuid"` // кто изменил или удалил комментарий
+	CreatedAt time.Time
+}
+
+func (r *CommentRevision) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskDependency — связь «BlockerID блокирует BlockedID»: заблокированную задачу нельзя
+// перевести в выполненный статус, пока блокирующая не выполнена
+type TaskDependency struct {
+	BlockerID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	BlockedID uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Labels — метки задачи. В БД хранятся литералом массива PostgreSQL ({"a","b"}):
+// колонка text[] в Postgres и текст в SQLite тестов.
+type Labels []string
+
+// Value кодирует метки литералом массива; каждый элемент в кавычках
+func (l Labels) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	quoted := make([]string, len(l))
+	for i, label := range l {
+		quoted[i] = QuoteLabel(label)
+	}
+	return "{" + strings.Join(quoted, ",") + "}", nil
+}
+
+// Scan разбирает литерал массива PostgreSQL
+func (l *Labels) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("labels: unsupported type %T", src)
+	}
+	labels, err := parseArray(s)
+	if err != nil {
+		return err
+	}
+	*l = labels
+	return nil
+}
+
+// QuoteLabel возвращает метку элементом литерала массива: в кавычках, с экранированными \ и "
+func QuoteLabel(label string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(label) + `"`
+}
+
+// parseArray разбирает одномерный литерал массива: {a,"b c",NULL}; NULL пропускается
+func parseArray(s string) (Labels, error) {
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, fmt.Errorf("labels: invalid array literal %q", s)
+	}
+	body := s[1 : len(s)-1]
+	labels := Labels{}
+	if body == "" {
+		return labels, nil
+	}
+	var cur strings.Builder
+	quoted, inQuotes, escaped := false, false, false
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case escaped:
+			cur.WriteByte(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+			quoted = true
+		case c == ',' && !inQuotes:
+			if quoted || cur.String() != "NULL" {
+				labels = append(labels, cur.String())
+			}
+			cur.Reset()
+			quoted = false
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if inQuotes || escaped {
+		return nil, fmt.Errorf("labels: invalid array literal %q", s)
+	}
+	if quoted || cur.String() != "NULL" {
+		labels = append(labels, cur.String())
+	}
+	return labels, nil
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Роли участника проекта
+const (
+	ProjectRoleOwner  = "owner"  // управляет участниками, удаляет любые задачи проекта
+	ProjectRoleEditor = "editor" // создаёт и изменяет задачи
+	ProjectRoleViewer = "viewer" // только просмотр
+)
+
+// Project — проект (доска), контейнер задач. Задачи проекта видны только его участникам.
+type Project struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name        string
+	Description string
+	OwnerID     uuid.UUID `gorm:"type:uuid"` // создатель проекта
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (p *Project) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// ProjectMember — участник проекта и его роль в нём
+type ProjectMember struct {
+	ProjectID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	Role      string    // owner, editor, viewer
+	CreatedAt time.Time
+}
+
+// CanEdit сообщает, может ли участник создавать и изменять задачи проекта
+func (m *ProjectMember) CanEdit() bool {
+	return m.Role == ProjectRoleOwner || m.Role == ProjectRoleEditor
+}
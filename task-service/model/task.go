@@ -8,14 +8,22 @@ import (
 )
 
 type Task struct {
-	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	ProjectID *uuid.UUID `gorm:"type:uuid;index"` // проект; nil — личная задача (видна создателю и исполнителю)
+	// колонка доски; nil — задача не на доске. Пара (column_id, rank) уникальна среди задач на доске
+	ColumnID    *uuid.UUID `gorm:"type:uuid;index;uniqueIndex:idx_tasks_column_rank_unique,where:archived_at IS NULL AND deleted_at IS NULL"`
+	ParentID    *uuid.UUID `gorm:"type:uuid;index"`                          // родительская задача; nil — задача верхнего уровня
+	Rank        string     `gorm:"uniqueIndex:idx_tasks_column_rank_unique"` // позиция в колонке
 	Title       string
 	Description string
-	Status      string    // backlog, todo, in_progress, done, archived
+	Status      string    // статус из workflow (по умолчанию backlog, todo, in_progress, done)
 	AssigneeID  uuid.UUID // исполнитель (user_id)
 	CreatorID   uuid.UUID // создатель задачи
 	DueDate     *time.Time
-	Labels      []string `gorm:"type:text[]"`
+	Labels      Labels         `gorm:"type:text[]"`
+	Version     int64          `gorm:"not null;default:1"` // растёт при каждом изменении задачи (оптимистичная блокировка)
+	ArchivedAt  *time.Time     `gorm:"index"`              // время архивации; архивные задачи не показываются в ListTasks по умолчанию
+	DeletedAt   gorm.DeletedAt `gorm:"index"`              // время удаления: задача в корзине до окончательного удаления
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
@@ -0,0 +1,69 @@
+// Package rank реализует дробный порядок (lexorank): позиция элемента — строка,
+// и между любыми двумя позициями можно вставить новую, не трогая соседей.
+package rank
+
+import "errors"
+
+// digits — алфавит позиций; порядок символов совпадает с побайтовым сравнением строк
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// ErrInvalidRange — prev не меньше next или позиция содержит недопустимые символы
+var ErrInvalidRange = errors.New("rank: invalid range")
+
+// Between возвращает позицию строго между prev и next.
+// Пустой prev — начало списка, пустой next — конец списка.
+// Результат никогда не оканчивается на '0', поэтому между позициями всегда есть место.
+func Between(prev, next string) (string, error) {
+	if !valid(prev) || !valid(next) || (prev != "" && next != "" && prev >= next) {
+		return "", ErrInvalidRange
+	}
+	var out []byte
+	upperOpen := next == "" // верхняя граница — конец списка
+	for i := 0; ; i++ {
+		p := 0
+		if i < len(prev) {
+			p = indexOf(prev[i])
+		}
+		n := base
+		if !upperOpen {
+			if i >= len(next) {
+				// next — префикс prev, т.е. next <= prev; исключено проверкой выше
+				return "", ErrInvalidRange
+			}
+			n = indexOf(next[i])
+		}
+		if n-p > 1 {
+			return string(append(out, digits[(p+n)/2])), nil
+		}
+		out = append(out, digits[p])
+		if p < n {
+			upperOpen = true
+		}
+	}
+}
+
+// After возвращает позицию после prev (в конец списка)
+func After(prev string) (string, error) {
+	return Between(prev, "")
+}
+
+func valid(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if indexOf(s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func indexOf(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 10
+	}
+	return -1
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"task-service/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Transaction выполняет fn в транзакции; репозиторий, переданный в fn, работает внутри неё
+func (r *TaskRepository) Transaction(fn func(repo *TaskRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&TaskRepository{db: tx})
+	})
+}
+
+// AddActivity добавляет записи в журнал действий
+func (r *TaskRepository) AddActivity(entries []model.Activity) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.Create(&entries).Error
+}
+
+// ListActivity возвращает до limit записей журнала объекта в хронологическом порядке, начиная
+// после записи (afterAt, afterID); нулевые значения — с начала
+func (r *TaskRepository) ListActivity(entityType string, entityID uuid.UUID, afterAt time.Time, afterID uuid.UUID, limit int) ([]model.Activity, error) {
+	var entries []model.Activity
+	query := r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID)
+	if afterID != uuid.Nil {
+		query = query.Where("(created_at > ? OR (created_at = ? AND id > ?))", afterAt, afterAt, afterID)
+	}
+	err := query.Order("created_at, id").Limit(limit).Find(&entries).Error
+	return entries, err
+}
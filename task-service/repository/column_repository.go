@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"errors"
+	"task-service/model"
+	"task-service/rank"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrWIPLimit — в колонке уже максимальное число задач
+var ErrWIPLimit = errors.New("column WIP limit exceeded")
+
+func (r *TaskRepository) CreateColumn(column *model.Column) error {
+	return r.db.Create(column).Error
+}
+
+// GetColumnByID возвращает колонку или nil, если её нет
+func (r *TaskRepository) GetColumnByID(id uuid.UUID) (*model.Column, error) {
+	var column model.Column
+	if err := r.db.Where("id = ?", id).First(&column).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &column, nil
+}
+
+// ListColumns возвращает колонки проекта в порядке на доске
+func (r *TaskRepository) ListColumns(projectID uuid.UUID) ([]model.Column, error) {
+	var columns []model.Column
+	err := r.db.Where("project_id = ?", projectID).Order("rank, id").Find(&columns).Error
+	return columns, err
+}
+
+func (r *TaskRepository) UpdateColumn(column *model.Column) error {
+	return r.db.Save(column).Error
+}
+
+// DeleteColumn удаляет колонку без активных задач; архивные и удалённые задачи снимаются с доски
+func (r *TaskRepository) DeleteColumn(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&model.Task{}).Where("column_id = ?", id).
+			Updates(map[string]interface{}{"column_id": nil, "rank": ""}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&model.Column{}, "id = ?", id).Error
+	})
+}
+
+// CountColumnTasks возвращает число активных (неархивных) задач в колонке
+func (r *TaskRepository) CountColumnTasks(columnID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Task{}).Where("column_id = ? AND archived_at IS NULL", columnID).Count(&count).Error
+	return count, err
+}
+
+// LastColumnRank возвращает позицию последней колонки проекта или пустую строку
+func (r *TaskRepository) LastColumnRank(projectID uuid.UUID) (string, error) {
+	var ranks []string
+	err := r.db.Model(&model.Column{}).Where("project_id = ?", projectID).
+		Order("rank DESC").Limit(1).Pluck("rank", &ranks).Error
+	if err != nil || len(ranks) == 0 {
+		return "", err
+	}
+	return ranks[0], nil
+}
+
+// FirstColumn возвращает первую колонку доски проекта или nil, если колонок нет
+func (r *TaskRepository) FirstColumn(projectID uuid.UUID) (*model.Column, error) {
+	var column model.Column
+	if err := r.db.Where("project_id = ?", projectID).Order("rank, id").First(&column).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &column, nil
+}
+
+// CreateTaskInColumn создаёт задачу в конце колонки; если колонка заполнена, возвращается ErrWIPLimit
+func (r *TaskRepository) CreateTaskInColumn(task *model.Task, column *model.Column) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		column, err := lockColumn(tx, column.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkWIPLimit(tx, column); err != nil {
+			return err
+		}
+		prev, _, err := rankNeighbors(tx, column.ID, task.ID, -1)
+		if err != nil {
+			return err
+		}
+		pos, err := rank.After(prev)
+		if err != nil {
+			return err
+		}
+		task.ColumnID = &column.ID
+		task.Rank = pos
+		return tx.Create(task).Error
+	})
+}
+
+// MoveTask ставит задачу в колонку на место position (с нуля; отрицательное или больше
+// числа задач — в конец). Меняется только строка самой задачи. Если задача переходит
+// в другую колонку с WIP-лимитом, а колонка заполнена, возвращается ErrWIPLimit.
+func (r *TaskRepository) MoveTask(task *model.Task, column *model.Column, position int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		column, err := lockColumn(tx, column.ID)
+		if err != nil {
+			return err
+		}
+		if task.ColumnID == nil || *task.ColumnID != column.ID {
+			if err := checkWIPLimit(tx, column); err != nil {
+				return err
+			}
+		}
+		prev, next, err := rankNeighbors(tx, column.ID, task.ID, position)
+		if err != nil {
+			return err
+		}
+		pos, err := rank.Between(prev, next)
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&model.Task{}).Where("id = ?", task.ID).
+			Updates(map[string]interface{}{"column_id": column.ID, "rank": pos, "version": versionBump}).Error
+		if err != nil {
+			return err
+		}
+		task.ColumnID = &column.ID
+		task.Rank = pos
+		task.Version++
+		return nil
+	})
+}
+
+// lockColumn перечитывает колонку и на PostgreSQL блокирует её строку до конца транзакции:
+// вставки и перемещения в одну колонку выполняются по очереди, поэтому подсчёт задач для WIP-лимита
+// и выбор позиции не гонятся с параллельными запросами (SQLite и так допускает одну пишущую транзакцию)
+func lockColumn(tx *gorm.DB, id uuid.UUID) (*model.Column, error) {
+	query := tx
+	if tx.Dialector.Name() == "postgres" {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var column model.Column
+	if err := query.Where("id = ?", id).First(&column).Error; err != nil {
+		return nil, err
+	}
+	return &column, nil
+}
+
+func checkWIPLimit(tx *gorm.DB, column *model.Column) error {
+	if column.WIPLimit <= 0 {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&model.Task{}).Where("column_id = ? AND archived_at IS NULL", column.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count >= int64(column.WIPLimit) {
+		return ErrWIPLimit
+	}
+	return nil
+}
+
+// rankNeighbors возвращает позиции задач колонки, между которыми встанет задача на месте position;
+// excludeID — перемещаемая задача, она не учитывается. Пустая строка — начало или конец колонки.
+func rankNeighbors(tx *gorm.DB, columnID, excludeID uuid.UUID, position int) (prev, next string, err error) {
+	ranks := func(order string, offset, limit int) ([]string, error) {
+		var out []string
+		err := tx.Model(&model.Task{}).Where("column_id = ? AND id <> ? AND archived_at IS NULL", columnID, excludeID).
+			Order(order).Offset(offset).Limit(limit).Pluck("rank", &out).Error
+		return out, err
+	}
+	if position >= 0 {
+		offset, limit := position-1, 2
+		if position == 0 {
+			offset, limit = 0, 1
+		}
+		found, err := ranks("rank, id", offset, limit)
+		if err != nil {
+			return "", "", err
+		}
+		switch {
+		case position == 0 && len(found) > 0:
+			return "", found[0], nil
+		case position == 0:
+			return "", "", nil
+		case len(found) == 2:
+			return found[0], found[1], nil
+		case len(found) == 1:
+			return found[0], "", nil
+		}
+		// position за концом колонки — ставим в конец
+	}
+	last, err := ranks("rank DESC, id DESC", 0, 1)
+	if err != nil || len(last) == 0 {
+		return "", "", err
+	}
+	return last[0], "", nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"task-service/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateComment сохраняет комментарий вместе с упоминаниями
+func (r *TaskRepository) CreateComment(comment *model.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+// GetCommentByID возвращает комментарий с упоминаниями или nil, если его нет
+func (r *TaskRepository) GetCommentByID(id uuid.UUID) (*model.Comment, error) {
+	var comment model.Comment
+	if err := r.db.Preload("Mentions").Where("id = ?", id).First(&comment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListComments возвращает до limit комментариев задачи в порядке создания, начиная после
+// комментария (afterAt, afterID); нулевые значения — с начала обсуждения
+func (r *TaskRepository) ListComments(taskID uuid.UUID, afterAt time.Time, afterID uuid.UUID, limit int) ([]model.Comment, error) {
+	var comments []model.Comment
+	query := r.db.Preload("Mentions").Where("task_id = ?", taskID)
+	if afterID != uuid.Nil {
+		query = query.Where("(created_at > ? OR (created_at = ? AND id > ?))", afterAt, afterAt, afterID)
+	}
+	err := query.Order("created_at, id").Limit(limit).Find(&comments).Error
+	return comments, err
+}
+
+// ListCommentRevisions возвращает прежние тексты комментариев, от старых к новым
+func (r *TaskRepository) ListCommentRevisions(commentIDs []uuid.UUID) ([]model.CommentRevision, error) {
+	var revisions []model.CommentRevision
+	if len(commentIDs) == 0 {
+		return revisions, nil
+	}
+	err := r.db.Where("comment_id IN ?", commentIDs).Order("created_at, id").Find(&revisions).Error
+	return revisions, err
+}
+
+// EditComment сохраняет прежний текст в истории и заменяет текст и упоминания комментария
+func (r *TaskRepository) EditComment(comment *model.Comment, revision *model.CommentRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&model.CommentMention{}).Error; err != nil {
+			return err
+		}
+		if len(comment.Mentions) > 0 {
+			if err := tx.Create(&comment.Mentions).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.Comment{}).Where("id = ?", comment.ID).Updates(map[string]interface{}{
+			"body":       comment.Body,
+			"edited_at":  comment.EditedAt,
+			"updated_at": comment.UpdatedAt,
+		}).Error
+	})
+}
+
+// DeleteComment сохраняет текст комментария в истории, очищает его и помечает удалённым
+func (r *TaskRepository) DeleteComment(comment *model.Comment, revision *model.CommentRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&model.CommentMention{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Comment{}).Where("id = ?", comment.ID).Updates(map[string]interface{}{
+			"body":       "",
+			"deleted_at": comment.DeletedAt,
+			"updated_at": comment.UpdatedAt,
+		}).Error
+	})
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"errors"
+	"task-service/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrDependencyNotFound — удаляемой связи между задачами нет
+var ErrDependencyNotFound = errors.New("dependency not found")
+
+// ErrDependencyCycle — новая связь «блокирует» замкнула бы цикл
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// ErrTaskBlocked — у задачи есть невыполненные блокирующие задачи
+var ErrTaskBlocked = errors.New("task is blocked by open tasks")
+
+// ErrParentCycle — задача стала бы подзадачей самой себя или своей подзадачи
+var ErrParentCycle = errors.New("task cannot be a subtask of itself or its subtasks")
+
+// maxTaskDepth ограничивает обход цепочек задач на случай повреждённых данных
+const maxTaskDepth = 1000
+
+// SubtaskProgress — число подзадач и выполненных из них
+type SubtaskProgress struct {
+	Total int
+	Done  int
+}
+
+// SetParent делает parentID родителем задачи; nil — задача верхнего уровня. Если задача оказалась бы
+// подзадачей самой себя — ErrParentCycle. Вызывается в транзакции (Transaction): проверка и запись
+// идут под блокировкой задач, поэтому два встречных вызова не замкнут цикл.
+func (r *TaskRepository) SetParent(taskID uuid.UUID, parentID *uuid.UUID) error {
+	if parentID != nil {
+		if err := r.lockTasks(taskID, *parentID); err != nil {
+			return err
+		}
+		cycle, err := r.IsAncestor(taskID, *parentID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrParentCycle
+		}
+	}
+	return r.db.Model(&model.Task{}).Where("id = ?", taskID).
+		Updates(map[string]interface{}{"parent_id": parentID, "version": versionBump}).Error
+}
+
+// IsAncestor сообщает, является ли ancestorID предком задачи taskID (или ею самой).
+// В транзакции на postgres пройденные задачи блокируются.
+func (r *TaskRepository) IsAncestor(ancestorID, taskID uuid.UUID) (bool, error) {
+	current := &taskID
+	for depth := 0; current != nil && depth < maxTaskDepth; depth++ {
+		if *current == ancestorID {
+			return true, nil
+		}
+		var parents []*uuid.UUID
+		if err := r.forUpdate().Model(&model.Task{}).Where("id = ?", *current).Pluck("parent_id", &parents).Error; err != nil {
+			return false, err
+		}
+		if len(parents) == 0 {
+			return false, nil
+		}
+		current = parents[0]
+	}
+	return false, nil
+}
+
+// SubtaskProgress возвращает прогресс подзадач для каждой из задач parentIDs;
+// выполненными считаются подзадачи в статусах doneStatuses
+func (r *TaskRepository) SubtaskProgress(parentIDs []uuid.UUID, doneStatuses []string) (map[uuid.UUID]SubtaskProgress, error) {
+	progress := make(map[uuid.UUID]SubtaskProgress, len(parentIDs))
+	if len(parentIDs) == 0 {
+		return progress, nil
+	}
+	var rows []struct {
+		ParentID uuid.UUID
+		Total    int
+		Done     int
+	}
+	err := r.db.Model(&model.Task{}).
+		Select("parent_id, COUNT(*) AS total, SUM(CASE WHEN status IN ? THEN 1 ELSE 0 END) AS done", doneStatuses).
+		Where("parent_id IN ?", parentIDs).Group("parent_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		progress[row.ParentID] = SubtaskProgress{Total: row.Total, Done: row.Done}
+	}
+	return progress, nil
+}
+
+// AddDependency добавляет связь «блокирует»; повторное добавление ничего не меняет. Связь, замыкающая
+// цикл, отклоняется с ErrDependencyCycle. Вызывается в транзакции (Transaction), как и SetParent.
+func (r *TaskRepository) AddDependency(dep *model.TaskDependency) error {
+	if err := r.lockTasks(dep.BlockerID, dep.BlockedID); err != nil {
+		return err
+	}
+	// blocker → blocked замыкает цикл, если blocked уже (через цепочку) блокирует blocker
+	cycle, err := r.Blocks(dep.BlockedID, dep.BlockerID)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return ErrDependencyCycle
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(dep).Error
+}
+
+// RemoveDependency удаляет связь; если её нет — ErrDependencyNotFound
+func (r *TaskRepository) RemoveDependency(blockerID, blockedID uuid.UUID) error {
+	res := r.db.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&model.TaskDependency{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrDependencyNotFound
+	}
+	return nil
+}
+
+// ListBlockers возвращает задачи, блокирующие taskID
+func (r *TaskRepository) ListBlockers(taskID uuid.UUID) ([]model.Task, error) {
+	var tasks []model.Task
+	err := r.db.Where("id IN (?)", r.db.Model(&model.TaskDependency{}).Select("blocker_id").Where("blocked_id = ?", taskID)).
+		Order("created_at, id").Find(&tasks).Error
+	return tasks, err
+}
+
+// ListBlocked возвращает задачи, которые блокирует taskID
+func (r *TaskRepository) ListBlocked(taskID uuid.UUID) ([]model.Task, error) {
+	var tasks []model.Task
+	err := r.db.Where("id IN (?)", r.db.Model(&model.TaskDependency{}).Select("blocked_id").Where("blocker_id = ?", taskID)).
+		Order("created_at, id").Find(&tasks).Error
+	return tasks, err
+}
+
+// Blocks сообщает, блокирует ли from задачу to напрямую или через цепочку зависимостей.
+// В транзакции на postgres пройденные задачи блокируются.
+func (r *TaskRepository) Blocks(from, to uuid.UUID) (bool, error) {
+	seen := map[uuid.UUID]bool{from: true}
+	frontier := []uuid.UUID{from}
+	for depth := 0; len(frontier) > 0 && depth < maxTaskDepth; depth++ {
+		if err := r.lockTasks(frontier...); err != nil {
+			return false, err
+		}
+		var next []uuid.UUID
+		err := r.db.Model(&model.TaskDependency{}).Where("blocker_id IN ?", frontier).Pluck("blocked_id", &next).Error
+		if err != nil {
+			return false, err
+		}
+		frontier = frontier[:0]
+		for _, id := range next {
+			if id == to {
+				return true, nil
+			}
+			if !seen[id] {
+				seen[id] = true
+				frontier = append(frontier, id)
+			}
+		}
+	}
+	return false, nil
+}
+
+// CheckBlockers возвращает ErrTaskBlocked, если у задачи есть невыполненные (не в doneStatuses) блокирующие
+// задачи. Вызывается в транзакции перед сменой статуса: задача и её блокирующие задачи блокируются,
+// поэтому новая зависимость или возврат блокирующей задачи в работу не проходят между проверкой и записью.
+func (r *TaskRepository) CheckBlockers(taskID uuid.UUID, doneStatuses []string) error {
+	if err := r.lockTasks(taskID); err != nil {
+		return err
+	}
+	var blockerIDs []uuid.UUID
+	if err := r.db.Model(&model.TaskDependency{}).Where("blocked_id = ?", taskID).Pluck("blocker_id", &blockerIDs).Error; err != nil {
+		return err
+	}
+	if len(blockerIDs) == 0 {
+		return nil
+	}
+	if err := r.lockTasks(blockerIDs...); err != nil {
+		return err
+	}
+	open, err := r.CountOpenBlockers(taskID, doneStatuses)
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return ErrTaskBlocked
+	}
+	return nil
+}
+
+// CountOpenBlockers возвращает число невыполненных (не в doneStatuses) задач, блокирующих taskID
+func (r *TaskRepository) CountOpenBlockers(taskID uuid.UUID, doneStatuses []string) (int64, error) {
+	var count int64
+	blockers := r.db.Model(&model.TaskDependency{}).Select("blocker_id").Where("blocked_id = ?", taskID)
+	query := r.db.Model(&model.Task{}).Where("id IN (?)", blockers)
+	if len(doneStatuses) > 0 {
+		query = query.Where("status NOT IN ?", doneStatuses)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// lockTasks блокирует строки задач до конца транзакции (только postgres; SQLite и так
+// выполняет пишущие транзакции по одной). Задачи блокируются в порядке id.
+func (r *TaskRepository) lockTasks(ids ...uuid.UUID) error {
+	if !r.postgres() {
+		return nil
+	}
+	var locked []uuid.UUID
+	return r.forUpdate().Model(&model.Task{}).Where("id IN ?", ids).Order("id").Pluck("id", &locked).Error
+}
+
+// forUpdate добавляет к запросу SELECT ... FOR UPDATE на postgres
+func (r *TaskRepository) forUpdate() *gorm.DB {
+	if !r.postgres() {
+		return r.db
+	}
+	return r.db.Clauses(clause.Locking{Strength: "UPDATE"})
+}
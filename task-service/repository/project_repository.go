@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"task-service/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateProject создаёт проект и добавляет его владельца участником с ролью owner в одной транзакции
+func (r *TaskRepository) CreateProject(project *model.Project) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.ProjectMember{
+			ProjectID: project.ID,
+			UserID:    project.OwnerID,
+			Role:      model.ProjectRoleOwner,
+		}).Error
+	})
+}
+
+// GetProjectByID возвращает проект или nil, если его нет
+func (r *TaskRepository) GetProjectByID(id uuid.UUID) (*model.Project, error) {
+	var project model.Project
+	if err := r.db.Where("id = ?", id).First(&project).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+// ListProjects возвращает проекты, где userID — участник; uuid.Nil — все проекты
+func (r *TaskRepository) ListProjects(userID uuid.UUID, offset, limit int) ([]model.Project, error) {
+	var projects []model.Project
+	if err := r.projectsQuery(userID).Order("created_at").Offset(offset).Limit(limit).Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// CountProjects возвращает число проектов, которые вернул бы ListProjects без страниц
+func (r *TaskRepository) CountProjects(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.projectsQuery(userID).Count(&count).Error
+	return count, err
+}
+
+// projectsQuery — проекты, где userID участник; uuid.Nil — все проекты
+func (r *TaskRepository) projectsQuery(userID uuid.UUID) *gorm.DB {
+	query := r.db.Model(&model.Project{})
+	if userID != uuid.Nil {
+		query = query.Where("id IN (?)", r.db.Model(&model.ProjectMember{}).Select("project_id").Where("user_id = ?", userID))
+	}
+	return query
+}
+
+// GetProjectMember возвращает участника проекта или nil, если пользователь в проекте не состоит
+func (r *TaskRepository) GetProjectMember(projectID, userID uuid.UUID) (*model.ProjectMember, error) {
+	var member model.ProjectMember
+	if err := r.db.Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// SaveProjectMember добавляет участника или меняет роль существующего
+func (r *TaskRepository) SaveProjectMember(member *model.ProjectMember) error {
+	return r.db.Save(member).Error
+}
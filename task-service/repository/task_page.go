@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"task-service/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidTaskKey — позиция страницы не соответствует сортировке выборки
+var ErrInvalidTaskKey = errors.New("invalid page token")
+
+// TaskSort — поле сортировки ListTasks
+type TaskSort struct {
+	Field string `json:"f"`
+	Desc  bool   `json:"d,omitempty"`
+}
+
+// TaskPage — страница ListTasks: Limit задач после задачи After (keyset) или, без After, со смещения Offset
+type TaskPage struct {
+	After  *TaskKey
+	Offset int
+	Limit  int
+	Total  bool // посчитать общее число подходящих задач
+}
+
+// TaskKey — позиция задачи в выборке: значения полей сортировки (nil — NULL) и id
+type TaskKey struct {
+	Values []*string `json:"v"`
+	ID     uuid.UUID `json:"id"`
+}
+
+// taskSortColumn — поле, по которому можно сортировать задачи
+type taskSortColumn struct {
+	column   string
+	nullable bool // NULL всегда в конце выборки, при любом направлении
+	isTime   bool
+	value    func(t *model.Task) *string
+}
+
+var taskSortColumns = map[string]taskSortColumn{
+	"created_at": {column: "created_at", isTime: true, value: func(t *model.Task) *string { return timeKey(&t.CreatedAt) }},
+	"updated_at": {column: "updated_at", isTime: true, value: func(t *model.Task) *string { return timeKey(&t.UpdatedAt) }},
+	"due_date":   {column: "due_date", nullable: true, isTime: true, value: func(t *model.Task) *string { return timeKey(t.DueDate) }},
+	"title":      {column: "title", value: func(t *model.Task) *string { return &t.Title }},
+	"status":     {column: "status", value: func(t *model.Task) *string { return &t.Status }},
+	"rank":       {column: "rank", value: func(t *model.Task) *string { return &t.Rank }},
+}
+
+// IsTaskSortField сообщает, можно ли сортировать задачи по полю field
+func IsTaskSortField(field string) bool {
+	_, ok := taskSortColumns[field]
+	return ok
+}
+
+// TaskSortOrder возвращает сортировку выборки: Sort фильтра или порядок по умолчанию
+// (задачи колонки — по позиции на доске, остальные — по времени создания). Последний ключ всегда id.
+func TaskSortOrder(filter TaskFilter) []TaskSort {
+	switch {
+	case len(filter.Sort) > 0:
+		return filter.Sort
+	case filter.ColumnID != uuid.Nil:
+		return []TaskSort{{Field: "rank"}}
+	}
+	return []TaskSort{{Field: "created_at"}}
+}
+
+// TaskKeyOf возвращает позицию задачи t в выборке с сортировкой sort
+func TaskKeyOf(sort []TaskSort, t *model.Task) *TaskKey {
+	key := &TaskKey{ID: t.ID}
+	for _, s := range sort {
+		key.Values = append(key.Values, taskSortColumns[s.Field].value(t))
+	}
+	return key
+}
+
+// taskOrder возвращает выражения ORDER BY для сортировки
+func taskOrder(sort []TaskSort) []string {
+	var orders []string
+	for _, s := range sort {
+		col := taskSortColumns[s.Field]
+		if col.nullable {
+			orders = append(orders, col.column+" IS NULL")
+		}
+		if s.Desc {
+			orders = append(orders, col.column+" DESC")
+		} else {
+			orders = append(orders, col.column)
+		}
+	}
+	return orders
+}
+
+// keysetCondition возвращает условие «задача идёт после key» для сортировки sort и id:
+// (a > x) OR (a = x AND b > y) OR ... OR (a = x AND b = y AND id > key.ID).
+// NULL в nullable-поле стоит после всех значений.
+func keysetCondition(sort []TaskSort, key *TaskKey) (string, []interface{}, error) {
+	if len(key.Values) != len(sort) {
+		return "", nil, ErrInvalidTaskKey
+	}
+	var (
+		terms  []string
+		args   []interface{}
+		eq     []string
+		eqArgs []interface{}
+	)
+	for i, s := range sort {
+		col := taskSortColumns[s.Field]
+		value, err := keyValue(col, key.Values[i])
+		if err != nil {
+			return "", nil, err
+		}
+		op := " > ?"
+		if s.Desc {
+			op = " < ?"
+		}
+		switch {
+		case value == nil:
+			// после NULL в этом поле идут только задачи с тем же NULL: условие «больше» не нужно
+			eq = append(eq, col.column+" IS NULL")
+		case col.nullable:
+			terms = append(terms, andTerms(eq, "("+col.column+op+" OR "+col.column+" IS NULL)"))
+			args = append(append(args, eqArgs...), value)
+			eq = append(eq, col.column+" = ?")
+			eqArgs = append(eqArgs, value)
+		default:
+			terms = append(terms, andTerms(eq, col.column+op))
+			args = append(append(args, eqArgs...), value)
+			eq = append(eq, col.column+" = ?")
+			eqArgs = append(eqArgs, value)
+		}
+	}
+	terms = append(terms, andTerms(eq, "id > ?"))
+	args = append(append(args, eqArgs...), key.ID)
+	return "(" + strings.Join(terms, " OR ") + ")", args, nil
+}
+
+func andTerms(eq []string, last string) string {
+	return "(" + strings.Join(append(append([]string{}, eq...), last), " AND ") + ")"
+}
+
+// keyValue переводит значение ключа в параметр запроса; nil — NULL
+func keyValue(col taskSortColumn, v *string) (interface{}, error) {
+	if v == nil {
+		if !col.nullable {
+			return nil, ErrInvalidTaskKey
+		}
+		return nil, nil
+	}
+	if !col.isTime {
+		return *v, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, *v)
+	if err != nil {
+		return nil, ErrInvalidTaskKey
+	}
+	return t, nil
+}
+
+// timeKey записывает время ключа вместе с исходным смещением, чтобы параметр запроса
+// совпал с сохранённым значением
+func timeKey(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339Nano)
+	return &s
+}
@@ -1,12 +1,24 @@
 package repository
 
 import (
+	"errors"
+	"strings"
 	"task-service/model"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// ErrStatusChanged — статус задачи изменился конкурентно
+var ErrStatusChanged = errors.New("task status was changed concurrently")
+
+// ErrVersionConflict — задачу изменили после того, как она была прочитана
+var ErrVersionConflict = errors.New("task was modified concurrently")
+
+// versionBump увеличивает версию задачи в UPDATE
+var versionBump = gorm.Expr("version + 1")
+
 type TaskRepository struct {
 	db *gorm.DB
 }
@@ -34,34 +46,201 @@ func (r *TaskRepository) GetTaskByID(id string) (*model.Task, error) {
 	return &task, nil
 }
 
+// UpdateTask сохраняет задачу, если её версия в БД всё ещё task.Version, и увеличивает версию.
+// Если задачу успели изменить, возвращается ErrVersionConflict.
 func (r *TaskRepository) UpdateTask(task *model.Task) error {
-	return r.db.Save(task).Error
+	read := task.Version
+	task.Version++
+	res := r.db.Model(task).Where("version = ?", read).Select("*").Updates(task)
+	if res.Error == nil && res.RowsAffected == 0 {
+		res.Error = ErrVersionConflict
+	}
+	if res.Error != nil {
+		task.Version = read
+	}
+	return res.Error
 }
 
+// DeleteTask переносит задачу в корзину; подзадачи (в том числе удалённые) становятся задачами
+// верхнего уровня. Зависимости сохраняются до окончательного удаления (PurgeDeletedTasks).
 func (r *TaskRepository) DeleteTask(id string) error {
 	taskID, err := uuid.Parse(id)
 	if err != nil {
 		return err
 	}
-	return r.db.Delete(&model.Task{}, "id = ?", taskID).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&model.Task{}).Where("parent_id = ?", taskID).Update("parent_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Task{}).Where("id = ?", taskID).
+			Updates(map[string]interface{}{"deleted_at": time.Now(), "version": versionBump}).Error
+	})
 }
 
-func (r *TaskRepository) ListTasks(status string, offset, limit int) ([]model.Task, error) {
+// TaskFilter — условия выборки ListTasks; пустые поля не ограничивают выборку
+type TaskFilter struct {
+	Status       string
+	ProjectID    uuid.UUID // только задачи проекта
+	ColumnID     uuid.UUID // только задачи колонки, в порядке на доске
+	ParentID     uuid.UUID // только подзадачи задачи
+	VisibleTo    uuid.UUID // только задачи, видимые пользователю: проекты, где он участник, и его личные задачи
+	AssigneeID   uuid.UUID
+	CreatorID    uuid.UUID
+	Labels       []string // задачи хотя бы с одной из меток (с AllLabels — со всеми)
+	AllLabels    bool
+	DueFrom      *time.Time
+	DueTo        *time.Time
+	Overdue      bool     // только просроченные: срок прошёл, статус не из DoneStatuses
+	DoneStatuses []string // статусы выполненной задачи (для Overdue)
+	Search       string   // полнотекстовый поиск по названию и описанию
+	Sort         []TaskSort
+	View         string // TaskViewActive (по умолчанию), TaskViewArchived или TaskViewTrash
+}
+
+// Представления ListTasks
+const (
+	TaskViewActive   = "active"   // неархивные задачи
+	TaskViewArchived = "archived" // архив
+	TaskViewTrash    = "trash"    // корзина: удалённые задачи
+)
+
+// ListTasks возвращает страницу задач по фильтру и, если запрошено (page.Total), общее число подходящих задач.
+// Без Sort задачи колонки упорядочены по позиции на доске, остальные — по времени создания.
+func (r *TaskRepository) ListTasks(filter TaskFilter, page TaskPage) ([]model.Task, int64, error) {
+	var total int64
+	if page.Total {
+		if err := r.db.Model(&model.Task{}).Scopes(r.taskFilter(filter)).Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+	sort := TaskSortOrder(filter)
+	query := r.db.Scopes(r.taskFilter(filter))
+	if page.After != nil {
+		cond, args, err := keysetCondition(sort, page.After)
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where(cond, args...)
+	} else if page.Offset > 0 {
+		query = query.Offset(page.Offset)
+	}
+	for _, order := range taskOrder(sort) {
+		query = query.Order(order)
+	}
 	var tasks []model.Task
-	query := r.db.Model(&model.Task{})
-	if status != "" {
-		query = query.Where("status = ?", status)
+	if err := query.Order("id").Limit(page.Limit).Find(&tasks).Error; err != nil {
+		return nil, 0, err
 	}
-	if err := query.Offset(offset).Limit(limit).Find(&tasks).Error; err != nil {
-		return nil, err
+	return tasks, total, nil
+}
+
+// taskFilter применяет условия фильтра к запросу
+func (r *TaskRepository) taskFilter(filter TaskFilter) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		switch filter.View {
+		case TaskViewTrash:
+			query = query.Unscoped().Where("deleted_at IS NOT NULL")
+		case TaskViewArchived:
+			query = query.Where("archived_at IS NOT NULL")
+		default:
+			query = query.Where("archived_at IS NULL")
+		}
+		if filter.Status != "" {
+			query = query.Where("status = ?", filter.Status)
+		}
+		if filter.ProjectID != uuid.Nil {
+			query = query.Where("project_id = ?", filter.ProjectID)
+		}
+		if filter.ColumnID != uuid.Nil {
+			query = query.Where("column_id = ?", filter.ColumnID)
+		}
+		if filter.ParentID != uuid.Nil {
+			query = query.Where("parent_id = ?", filter.ParentID)
+		}
+		if filter.AssigneeID != uuid.Nil {
+			query = query.Where("assignee_id = ?", filter.AssigneeID)
+		}
+		if filter.CreatorID != uuid.Nil {
+			query = query.Where("creator_id = ?", filter.CreatorID)
+		}
+		if len(filter.Labels) > 0 {
+			query = r.labelsFilter(query, filter.Labels, filter.AllLabels)
+		}
+		if filter.DueFrom != nil {
+			query = query.Where("due_date >= ?", *filter.DueFrom)
+		}
+		if filter.DueTo != nil {
+			query = query.Where("due_date <= ?", *filter.DueTo)
+		}
+		if filter.Overdue {
+			query = query.Where("due_date < ?", time.Now())
+			if len(filter.DoneStatuses) > 0 {
+				query = query.Where("status NOT IN ?", filter.DoneStatuses)
+			}
+		}
+		if filter.Search != "" {
+			query = r.searchFilter(query, filter.Search)
+		}
+		if filter.VisibleTo != uuid.Nil {
+			memberOf := r.db.Model(&model.ProjectMember{}).Select("project_id").Where("user_id = ?", filter.VisibleTo)
+			query = query.Where("(project_id IN (?) OR (project_id IS NULL AND (creator_id = ? OR assignee_id = ?)))",
+				memberOf, filter.VisibleTo, filter.VisibleTo)
+		}
+		return query
 	}
-	return tasks, nil
 }
 
-func (r *TaskRepository) ChangeStatus(id, status string) error {
-	taskID, err := uuid.Parse(id)
-	if err != nil {
-		return err
+// labelsFilter отбирает задачи с любой (all — со всеми) из меток. В Postgres — операторы массивов
+// (индекс GIN), в SQLite метки хранятся текстом литерала, и каждая ищется по подстроке "метка".
+func (r *TaskRepository) labelsFilter(query *gorm.DB, labels []string, all bool) *gorm.DB {
+	if r.postgres() {
+		if all {
+			return query.Where("labels @> ?", model.Labels(labels))
+		}
+		return query.Where("labels && ?", model.Labels(labels))
+	}
+	conds := make([]string, len(labels))
+	args := make([]interface{}, len(labels))
+	for i, label := range labels {
+		conds[i] = `labels LIKE ? ESCAPE '!'`
+		args[i] = "%" + likeEscape(model.QuoteLabel(label)) + "%"
+	}
+	sep := " OR "
+	if all {
+		sep = " AND "
+	}
+	return query.Where("("+strings.Join(conds, sep)+")", args...)
+}
+
+// searchFilter — полнотекстовый поиск: в Postgres по колонке search_vector (миграция 7),
+// в SQLite — поиск подстроки в названии и описании
+func (r *TaskRepository) searchFilter(query *gorm.DB, search string) *gorm.DB {
+	if r.postgres() {
+		return query.Where("search_vector @@ plainto_tsquery('simple', ?)", search)
+	}
+	pattern := "%" + likeEscape(search) + "%"
+	return query.Where(`(title LIKE ? ESCAPE '!' OR description LIKE ? ESCAPE '!')`, pattern, pattern)
+}
+
+func (r *TaskRepository) postgres() bool {
+	return r.db.Dialector.Name() == "postgres"
+}
+
+// likeEscape экранирует спецсимволы LIKE символом '!'
+func likeEscape(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// ChangeStatus переводит задачу из статуса from в to. Если статус успели изменить
+// после проверки перехода, возвращается ErrStatusChanged.
+func (r *TaskRepository) ChangeStatus(id uuid.UUID, from, to string) error {
+	res := r.db.Model(&model.Task{}).Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{"status": to, "updated_at": time.Now(), "version": versionBump})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrStatusChanged
 	}
-	return r.db.Model(&model.Task{}).Where("id = ?", taskID).Update("status", status).Error
+	return nil
 }
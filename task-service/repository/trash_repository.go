@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"task-service/model"
+	"task-service/rank"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetTaskIncludingDeleted возвращает задачу, даже если она в корзине; nil — задачи нет
+func (r *TaskRepository) GetTaskIncludingDeleted(id string) (*model.Task, error) {
+	taskID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	var task model.Task
+	if err := r.db.Unscoped().Where("id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// SetArchived переносит задачу в архив (at — время архивации) или возвращает из него (nil)
+func (r *TaskRepository) SetArchived(id uuid.UUID, at *time.Time) error {
+	updates := map[string]interface{}{"archived_at": at, "updated_at": time.Now(), "version": versionBump}
+	if at == nil {
+		if err := r.returnToBoard(id, updates); err != nil {
+			return err
+		}
+	}
+	return r.db.Model(&model.Task{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// RestoreTask возвращает задачу из корзины
+func (r *TaskRepository) RestoreTask(id uuid.UUID) error {
+	updates := map[string]interface{}{"deleted_at": nil, "updated_at": time.Now(), "version": versionBump}
+	if err := r.returnToBoard(id, updates); err != nil {
+		return err
+	}
+	return r.db.Unscoped().Model(&model.Task{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// returnToBoard проверяет прежнее место задачи, которая возвращается на доску из архива или корзины:
+// в колонке должно хватать WIP-лимита (иначе ErrWIPLimit); пока задачи не было, её позицию могла
+// занять другая задача, тогда задача встаёт в конец колонки
+func (r *TaskRepository) returnToBoard(id uuid.UUID, updates map[string]interface{}) error {
+	var task model.Task
+	if err := r.db.Unscoped().Where("id = ?", id).First(&task).Error; err != nil {
+		return err
+	}
+	_, unarchive := updates["archived_at"]
+	_, restore := updates["deleted_at"]
+	if task.ColumnID == nil || (!unarchive && task.ArchivedAt != nil) || (!restore && task.DeletedAt.Valid) {
+		// задача остаётся вне доски
+		return nil
+	}
+	column, err := lockColumn(r.db, *task.ColumnID)
+	if err != nil {
+		return err
+	}
+	if err := checkWIPLimit(r.db, column); err != nil {
+		return err
+	}
+	var taken int64
+	err = r.db.Model(&model.Task{}).Where("column_id = ? AND rank = ? AND id <> ? AND archived_at IS NULL", task.ColumnID, task.Rank, id).
+		Count(&taken).Error
+	if err != nil || taken == 0 {
+		return err
+	}
+	prev, _, err := rankNeighbors(r.db, *task.ColumnID, id, -1)
+	if err != nil {
+		return err
+	}
+	pos, err := rank.After(prev)
+	if err != nil {
+		return err
+	}
+	updates["rank"] = pos
+	return nil
+}
+
+// PurgeDeletedTasks окончательно удаляет задачи, попавшие в корзину раньше before, вместе
+// с их зависимостями, и возвращает их число
+func (r *TaskRepository) PurgeDeletedTasks(before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		if err := tx.Unscoped().Model(&model.Task{}).Where("deleted_at < ?", before).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Where("blocker_id IN ? OR blocked_id IN ?", ids, ids).Delete(&model.TaskDependency{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Task{}).Where("parent_id IN ?", ids).Update("parent_id", nil).Error; err != nil {
+			return err
+		}
+		res := tx.Unscoped().Where("id IN ?", ids).Delete(&model.Task{})
+		purged = res.RowsAffected
+		return res.Error
+	})
+	return purged, err
+}
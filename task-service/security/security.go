@@ -3,22 +3,65 @@ package security
 import (
 	"errors"
 
+	"shared/jwks"
+	"shared/revocation"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type JWTService struct {
-	secret string
+	secret   string
+	keys     *jwks.KeySet // если задан — токены проверяются публичными ключами из JWKS
+	issuer   string       // ожидаемый iss; пустая строка — без проверки
+	audience string       // ожидаемый aud; пустая строка — без проверки
+
+	revocations *revocation.List // если задан — отклоняются отозванные токены
 }
 
+// NewJWTService проверяет токены с подписью HS256 общим секретом
 func NewJWTService(secret string) *JWTService {
 	return &JWTService{secret: secret}
 }
 
-// ValidateToken проверяет подпись и возвращает claims
+// NewJWKSService проверяет токены с подписью RS256/EdDSA ключами из JWKS user-service
+func NewJWKSService(keys *jwks.KeySet) *JWTService {
+	return &JWTService{keys: keys}
+}
+
+// WithIssuer включает проверку claims iss и aud
+func (j *JWTService) WithIssuer(issuer, audience string) *JWTService {
+	j.issuer = issuer
+	j.audience = audience
+	return j
+}
+
+// WithRevocations включает проверку токенов по списку отзыва user-service
+func (j *JWTService) WithRevocations(list *revocation.List) *JWTService {
+	j.revocations = list
+	return j
+}
+
+// ValidateToken проверяет подпись (и iss/aud, если заданы) и возвращает claims
 func (j *JWTService) ValidateToken(tokenStr string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
 		return []byte(j.secret), nil
-	})
+	}
+	methods := []string{jwt.SigningMethodHS256.Alg()}
+	if j.keys != nil {
+		keyFunc = func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return j.keys.Key(kid)
+		}
+		methods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+	}
+	opts := []jwt.ParserOption{jwt.WithValidMethods(methods)}
+	if j.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(j.issuer))
+	}
+	if j.audience != "" {
+		opts = append(opts, jwt.WithAudience(j.audience))
+	}
+	token, err := jwt.Parse(tokenStr, keyFunc, opts...)
 	if err != nil || !token.Valid {
 		return nil, errors.New("invalid token")
 	}
@@ -26,5 +69,15 @@ func (j *JWTService) ValidateToken(tokenStr string) (jwt.MapClaims, error) {
 	if !ok {
 		return nil, errors.New("invalid claims")
 	}
+	if j.revocations != nil {
+		jti, _ := claims["jti"].(string)
+		uid, _ := claims["user_id"].(string)
+		if uid == "" {
+			uid, _ = claims["sub"].(string)
+		}
+		if j.revocations.IsRevoked(jti, uid, revocation.IssuedAt(claims)) {
+			return nil, errors.New("token revoked")
+		}
+	}
 	return claims, nil
 }
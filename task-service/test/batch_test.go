@@ -0,0 +1,186 @@
+package test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"task-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// unassigned — assignee_id задачи без исполнителя
+const unassigned = "00000000-0000-0000-0000-000000000000"
+
+// resultCodes возвращает коды результатов BatchUpdateTasks по порядку
+func resultCodes(resp *proto.BatchUpdateTasksResponse) []codes.Code {
+	var result []codes.Code
+	for _, r := range resp.Results {
+		result = append(result, codes.Code(r.Code))
+	}
+	return result
+}
+
+func TestBatchUpdateTasks_PerTaskResults(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	editor := ctxWithJWT(makeJWT(t, "testsecret", editorID, "user"))
+	stray := ctxWithJWT(makeJWT(t, "testsecret", strayID, "user"))
+	projectID := newBoard(t, ts, owner)
+
+	a, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "A", ProjectId: projectID, Labels: []string{"old", "keep"}})
+	b, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "B", ProjectId: projectID})
+	ts.ChangeStatus(owner, &proto.ChangeStatusRequest{TaskId: b.TaskId, Status: "backlog"})
+	hidden, _ := ts.CreateTask(stray, &proto.CreateTaskRequest{Title: "Hidden"})
+
+	resp, err := ts.BatchUpdateTasks(editor, &proto.BatchUpdateTasksRequest{
+		TaskIds:      []string{a.TaskId, b.TaskId, hidden.TaskId},
+		Status:       "in_progress",
+		AssigneeId:   editorID,
+		AddLabels:    []string{"bulk", "keep"},
+		RemoveLabels: []string{"old"},
+	})
+	if err != nil {
+		t.Fatalf("batch update failed: %v", err)
+	}
+	want := []codes.Code{codes.OK, codes.FailedPrecondition, codes.NotFound}
+	if got := resultCodes(resp); !slices.Equal(got, want) || resp.Updated != 1 {
+		t.Fatalf("expected codes %v and 1 updated, got %v and %d", want, got, resp.Updated)
+	}
+	if resp.Results[0].TaskId != a.TaskId || resp.Results[1].Task != nil {
+		t.Errorf("results must follow task_ids order and carry only updated tasks, got %v", resp.Results)
+	}
+	updated := resp.Results[0].Task
+	if updated.Status != "in_progress" || updated.AssigneeId != editorID || updated.Version != 2 ||
+		!slices.Equal(updated.Labels, []string{"keep", "bulk"}) {
+		t.Errorf("unexpected updated task: %v", updated)
+	}
+	got, _ := ts.GetTask(owner, &proto.GetTaskRequest{TaskId: b.TaskId})
+	if got.Task.Status != "backlog" || got.Task.AssigneeId != unassigned {
+		t.Errorf("failed task must stay unchanged, got %v", got.Task)
+	}
+	fields, _ := historyFields(t, ts, a.TaskId)
+	for _, field := range []string{"update/status", "update/assignee_id", "update/labels"} {
+		if !slices.Contains(fields, field) {
+			t.Errorf("expected %s in history, got %v", field, fields)
+		}
+	}
+}
+
+func TestBatchUpdateTasks_Permissions(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	viewer := ctxWithJWT(makeJWT(t, "testsecret", viewerID, "user"))
+	projectID := newBoard(t, ts, owner)
+
+	shared, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Shared", ProjectId: projectID})
+	own, _ := ts.CreateTask(viewer, &proto.CreateTaskRequest{Title: "Own"})
+
+	resp, err := ts.BatchUpdateTasks(viewer, &proto.BatchUpdateTasksRequest{
+		TaskIds: []string{shared.TaskId, own.TaskId},
+		Status:  "done",
+	})
+	if err != nil {
+		t.Fatalf("batch update failed: %v", err)
+	}
+	want := []codes.Code{codes.PermissionDenied, codes.OK}
+	if got := resultCodes(resp); !slices.Equal(got, want) || resp.Updated != 1 {
+		t.Errorf("expected codes %v and 1 updated, got %v and %d", want, got, resp.Updated)
+	}
+}
+
+func TestBatchUpdateTasks_AllOrNothing(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	projectID := newBoard(t, ts, owner)
+
+	a, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "A", ProjectId: projectID})
+	b, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "B", ProjectId: projectID})
+	ts.ChangeStatus(owner, &proto.ChangeStatusRequest{TaskId: b.TaskId, Status: "backlog"})
+
+	resp, err := ts.BatchUpdateTasks(owner, &proto.BatchUpdateTasksRequest{
+		TaskIds:      []string{a.TaskId, b.TaskId},
+		Status:       "done",
+		AllOrNothing: true,
+	})
+	if err != nil {
+		t.Fatalf("batch update failed: %v", err)
+	}
+	want := []codes.Code{codes.Aborted, codes.FailedPrecondition}
+	if got := resultCodes(resp); !slices.Equal(got, want) || resp.Updated != 0 {
+		t.Fatalf("expected codes %v and nothing updated, got %v and %d", want, got, resp.Updated)
+	}
+	got, _ := ts.GetTask(owner, &proto.GetTaskRequest{TaskId: a.TaskId})
+	if got.Task.Status != "todo" || got.Task.Version != 1 {
+		t.Errorf("expected A untouched, got %v", got.Task)
+	}
+
+	ts.ChangeStatus(owner, &proto.ChangeStatusRequest{TaskId: b.TaskId, Status: "todo"})
+	resp, err = ts.BatchUpdateTasks(owner, &proto.BatchUpdateTasksRequest{
+		TaskIds:      []string{a.TaskId, b.TaskId},
+		Status:       "done",
+		AllOrNothing: true,
+	})
+	if err != nil || resp.Updated != 2 {
+		t.Errorf("expected both tasks updated, got %v, %v", resp, err)
+	}
+}
+
+func TestBatchUpdateTasks_UpdateMask(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	projectID := newBoard(t, ts, owner)
+
+	a, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "A", ProjectId: projectID, AssigneeId: editorID})
+	b, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "B", ProjectId: projectID})
+	ids := []string{a.TaskId, b.TaskId}
+
+	// без маски пустые поля не меняются
+	resp, err := ts.BatchUpdateTasks(owner, &proto.BatchUpdateTasksRequest{TaskIds: ids, DueDate: "2030-01-02T15:04:05Z"})
+	if err != nil || resp.Updated != 2 || resp.Results[0].Task.AssigneeId != editorID || resp.Results[1].Task.DueDate == "" {
+		t.Fatalf("expected due date set and assignee kept, got %v, %v", resp, err)
+	}
+
+	resp, err = ts.BatchUpdateTasks(owner, &proto.BatchUpdateTasksRequest{
+		TaskIds:    ids,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"assignee_id", "due_date"}},
+	})
+	if err != nil || resp.Updated != 2 {
+		t.Fatalf("batch clear failed: %v, %v", resp, err)
+	}
+	for _, r := range resp.Results {
+		if r.Task.AssigneeId != unassigned || r.Task.DueDate != "" {
+			t.Errorf("expected assignee and due date cleared, got %v", r.Task)
+		}
+	}
+}
+
+func TestBatchUpdateTasks_Validation(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	task, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "A"})
+	tooMany := make([]string, 101)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("x", i+1)
+	}
+
+	cases := map[string]*proto.BatchUpdateTasksRequest{
+		"no ids":          {Status: "done"},
+		"too many ids":    {TaskIds: tooMany, Status: "done"},
+		"duplicate ids":   {TaskIds: []string{task.TaskId, task.TaskId}, Status: "done"},
+		"nothing":         {TaskIds: []string{task.TaskId}},
+		"unknown path":    {TaskIds: []string{task.TaskId}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}}},
+		"empty status":    {TaskIds: []string{task.TaskId}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}}},
+		"bad assignee":    {TaskIds: []string{task.TaskId}, AssigneeId: "nope"},
+		"bad due date":    {TaskIds: []string{task.TaskId}, DueDate: "tomorrow"},
+		"status mismatch": {TaskIds: []string{task.TaskId}, Status: "done", StatusCode: proto.TaskStatus_TASK_STATUS_TODO},
+	}
+	for name, req := range cases {
+		if _, err := ts.BatchUpdateTasks(owner, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, got %v", name, err)
+		}
+	}
+}
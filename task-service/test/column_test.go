@@ -0,0 +1,157 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"task-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newBoard создаёт проект с редактором и зрителем и возвращает его id
+func newBoard(t *testing.T, ts proto.TaskServiceClient, owner context.Context) string {
+	project, err := ts.CreateProject(owner, &proto.CreateProjectRequest{Name: "Board"})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+	ts.AddMember(owner, &proto.AddMemberRequest{ProjectId: project.Project.Id, UserId: editorID, Role: "editor"})
+	ts.AddMember(owner, &proto.AddMemberRequest{ProjectId: project.Project.Id, UserId: viewerID, Role: "viewer"})
+	return project.Project.Id
+}
+
+// columnTitles возвращает названия задач колонки в порядке на доске
+func columnTitles(t *testing.T, ts proto.TaskServiceClient, ctx context.Context, columnID string) []string {
+	list, err := ts.ListTasks(ctx, &proto.ListTasksRequest{ColumnId: columnID, Page: 1, PageSize: 50})
+	if err != nil {
+		t.Fatalf("list column tasks failed: %v", err)
+	}
+	var titles []string
+	for _, task := range list.Tasks {
+		titles = append(titles, task.Title)
+	}
+	return titles
+}
+
+func TestColumns_Manage(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	editor := ctxWithJWT(makeJWT(t, "testsecret", editorID, "user"))
+	stray := ctxWithJWT(makeJWT(t, "testsecret", strayID, "user"))
+	projectID := newBoard(t, ts, owner)
+
+	if _, err := ts.CreateColumn(editor, &proto.CreateColumnRequest{ProjectId: projectID, Name: "Todo"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for editor, got %v", err)
+	}
+	if _, err := ts.CreateColumn(owner, &proto.CreateColumnRequest{ProjectId: projectID, Name: "Todo", WipLimit: -1}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for negative limit, got %v", err)
+	}
+	var ids []string
+	for _, name := range []string{"Todo", "Doing", "Done"} {
+		resp, err := ts.CreateColumn(owner, &proto.CreateColumnRequest{ProjectId: projectID, Name: name})
+		if err != nil {
+			t.Fatalf("create column failed: %v", err)
+		}
+		ids = append(ids, resp.Column.Id)
+	}
+
+	list, err := ts.ListColumns(editor, &proto.ListColumnsRequest{ProjectId: projectID})
+	if err != nil || len(list.Columns) != 3 || list.Columns[0].Name != "Todo" || list.Columns[2].Name != "Done" {
+		t.Fatalf("expected columns in creation order, got %v, %v", list, err)
+	}
+	if _, err := ts.ListColumns(stray, &proto.ListColumnsRequest{ProjectId: projectID}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for non-member, got %v", err)
+	}
+
+	updated, err := ts.UpdateColumn(owner, &proto.UpdateColumnRequest{ColumnId: ids[1], Name: "In progress", WipLimit: 2})
+	if err != nil || updated.Column.WipLimit != 2 || updated.Column.Name != "In progress" {
+		t.Errorf("expected column updated, got %v, %v", updated, err)
+	}
+	if _, err := ts.UpdateColumn(editor, &proto.UpdateColumnRequest{ColumnId: ids[1], Name: "Hacked"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for editor update, got %v", err)
+	}
+
+	// новая задача проекта с доской попадает в первую колонку
+	task, err := ts.CreateTask(editor, &proto.CreateTaskRequest{Title: "Card", ProjectId: projectID})
+	if err != nil {
+		t.Fatalf("create task failed: %v", err)
+	}
+	got, _ := ts.GetTask(editor, &proto.GetTaskRequest{TaskId: task.TaskId})
+	if got.Task.ColumnId != ids[0] || got.Task.Rank == "" {
+		t.Errorf("expected task in first column, got %v", got.Task)
+	}
+	if _, err := ts.DeleteColumn(owner, &proto.DeleteColumnRequest{ColumnId: ids[0]}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition deleting non-empty column, got %v", err)
+	}
+	if _, err := ts.DeleteColumn(owner, &proto.DeleteColumnRequest{ColumnId: ids[2]}); err != nil {
+		t.Errorf("expected empty column deleted, got %v", err)
+	}
+	list, _ = ts.ListColumns(owner, &proto.ListColumnsRequest{ProjectId: projectID})
+	if len(list.Columns) != 2 {
+		t.Errorf("expected 2 columns after delete, got %d", len(list.Columns))
+	}
+}
+
+func TestColumns_MoveTaskOrderAndWIP(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	editor := ctxWithJWT(makeJWT(t, "testsecret", editorID, "user"))
+	viewer := ctxWithJWT(makeJWT(t, "testsecret", viewerID, "user"))
+	projectID := newBoard(t, ts, owner)
+
+	todo, _ := ts.CreateColumn(owner, &proto.CreateColumnRequest{ProjectId: projectID, Name: "Todo"})
+	doing, _ := ts.CreateColumn(owner, &proto.CreateColumnRequest{ProjectId: projectID, Name: "Doing", WipLimit: 2})
+	taskIDs := map[string]string{}
+	for _, title := range []string{"A", "B", "C", "D"} {
+		resp, err := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: title, ProjectId: projectID})
+		if err != nil {
+			t.Fatalf("create task failed: %v", err)
+		}
+		taskIDs[title] = resp.TaskId
+	}
+	if got := columnTitles(t, ts, owner, todo.Column.Id); len(got) != 4 || got[0] != "A" || got[3] != "D" {
+		t.Fatalf("expected A..D in creation order, got %v", got)
+	}
+
+	// D в начало, A между B и C
+	if _, err := ts.MoveTask(editor, &proto.MoveTaskRequest{TaskId: taskIDs["D"], ColumnId: todo.Column.Id, Position: 0}); err != nil {
+		t.Fatalf("move to top failed: %v", err)
+	}
+	if _, err := ts.MoveTask(editor, &proto.MoveTaskRequest{TaskId: taskIDs["A"], ColumnId: todo.Column.Id, Position: 2}); err != nil {
+		t.Fatalf("move inside column failed: %v", err)
+	}
+	if got := columnTitles(t, ts, owner, todo.Column.Id); len(got) != 4 || got[0] != "D" || got[1] != "B" || got[2] != "A" || got[3] != "C" {
+		t.Errorf("expected order D B A C, got %v", got)
+	}
+
+	for _, title := range []string{"B", "C"} {
+		if _, err := ts.MoveTask(editor, &proto.MoveTaskRequest{TaskId: taskIDs[title], ColumnId: doing.Column.Id, Position: 99}); err != nil {
+			t.Fatalf("move to doing failed: %v", err)
+		}
+	}
+	if _, err := ts.MoveTask(editor, &proto.MoveTaskRequest{TaskId: taskIDs["A"], ColumnId: doing.Column.Id}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for full column, got %v", err)
+	}
+	// перестановка внутри заполненной колонки не упирается в лимит
+	moved, err := ts.MoveTask(editor, &proto.MoveTaskRequest{TaskId: taskIDs["C"], ColumnId: doing.Column.Id, Position: 0})
+	if err != nil || moved.Task.ColumnId != doing.Column.Id {
+		t.Fatalf("reorder inside full column failed: %v, %v", moved, err)
+	}
+	if got := columnTitles(t, ts, owner, doing.Column.Id); len(got) != 2 || got[0] != "C" || got[1] != "B" {
+		t.Errorf("expected order C B, got %v", got)
+	}
+
+	if _, err := ts.MoveTask(viewer, &proto.MoveTaskRequest{TaskId: taskIDs["D"], ColumnId: doing.Column.Id}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for viewer, got %v", err)
+	}
+	other := newBoard(t, ts, owner)
+	foreign, _ := ts.CreateColumn(owner, &proto.CreateColumnRequest{ProjectId: other, Name: "Elsewhere"})
+	if _, err := ts.MoveTask(editor, &proto.MoveTaskRequest{TaskId: taskIDs["D"], ColumnId: foreign.Column.Id}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for column of another project, got %v", err)
+	}
+	personal, _ := ts.CreateTask(editor, &proto.CreateTaskRequest{Title: "Mine"})
+	if _, err := ts.MoveTask(editor, &proto.MoveTaskRequest{TaskId: personal.TaskId, ColumnId: todo.Column.Id}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for personal task, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	"task-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestComments_AddEditDelete(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	editor := ctxWithJWT(makeJWT(t, "testsecret", editorID, "user"))
+	viewer := ctxWithJWT(makeJWT(t, "testsecret", viewerID, "user"))
+	stray := ctxWithJWT(makeJWT(t, "testsecret", strayID, "user"))
+	projectID := newBoard(t, ts, owner)
+	task, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Discuss", ProjectId: projectID})
+
+	if _, err := ts.AddComment(viewer, &proto.AddCommentRequest{TaskId: task.TaskId, Body: "  "}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for empty body, got %v", err)
+	}
+	if _, err := ts.AddComment(stray, &proto.AddCommentRequest{TaskId: task.TaskId, Body: "hi"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for non-member, got %v", err)
+	}
+	body := fmt.Sprintf("**ping** @%s and @%s, also @%s", editorID, strayID, editorID)
+	added, err := ts.AddComment(viewer, &proto.AddCommentRequest{TaskId: task.TaskId, Body: body})
+	if err != nil {
+		t.Fatalf("add comment failed: %v", err)
+	}
+	c := added.Comment
+	if c.AuthorId != viewerID || c.Body != body || len(c.Mentions) != 1 || c.Mentions[0] != editorID {
+		t.Errorf("expected comment by viewer mentioning only the editor, got %v", c)
+	}
+
+	if _, err := ts.EditComment(editor, &proto.EditCommentRequest{CommentId: c.Id, Body: "hijack"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied editing someone else's comment, got %v", err)
+	}
+	edited, err := ts.EditComment(viewer, &proto.EditCommentRequest{CommentId: c.Id, Body: "fixed @" + ownerID})
+	if err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	if edited.Comment.EditedAt == "" || len(edited.Comment.Mentions) != 1 || edited.Comment.Mentions[0] != ownerID {
+		t.Errorf("expected edited comment mentioning owner, got %v", edited.Comment)
+	}
+
+	list, err := ts.ListComments(editor, &proto.ListCommentsRequest{TaskId: task.TaskId, IncludeHistory: true})
+	if err != nil || len(list.Comments) != 1 {
+		t.Fatalf("expected 1 comment, got %v, %v", list, err)
+	}
+	if h := list.Comments[0].History; len(h) != 1 || h[0].Body != body || h[0].EditorId != viewerID {
+		t.Errorf("expected original body in history, got %v", h)
+	}
+
+	if _, err := ts.DeleteComment(editor, &proto.DeleteCommentRequest{CommentId: c.Id}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for editor deleting other's comment, got %v", err)
+	}
+	// владелец проекта модерирует обсуждение
+	if _, err := ts.DeleteComment(owner, &proto.DeleteCommentRequest{CommentId: c.Id}); err != nil {
+		t.Fatalf("expected project owner to delete comment, got %v", err)
+	}
+	list, _ = ts.ListComments(viewer, &proto.ListCommentsRequest{TaskId: task.TaskId, IncludeHistory: true})
+	if len(list.Comments) != 1 || !list.Comments[0].Deleted || list.Comments[0].Body != "" || len(list.Comments[0].History) != 0 {
+		t.Errorf("expected tombstone without text and history, got %v", list.Comments)
+	}
+	if _, err := ts.EditComment(viewer, &proto.EditCommentRequest{CommentId: c.Id, Body: "back"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound editing deleted comment, got %v", err)
+	}
+	if _, err := ts.DeleteComment(viewer, &proto.DeleteCommentRequest{CommentId: c.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound deleting twice, got %v", err)
+	}
+}
+
+func TestComments_PageTokenPagination(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	task, _ := ts.CreateTask(ctx, &proto.CreateTaskRequest{Title: "Long thread"})
+	for i := 0; i < 5; i++ {
+		if _, err := ts.AddComment(ctx, &proto.AddCommentRequest{TaskId: task.TaskId, Body: fmt.Sprintf("comment %d", i)}); err != nil {
+			t.Fatalf("add comment failed: %v", err)
+		}
+	}
+
+	var bodies []string
+	token, first := "", ""
+	for pages := 0; pages < 10; pages++ {
+		page, err := ts.ListComments(ctx, &proto.ListCommentsRequest{TaskId: task.TaskId, PageToken: token, PageSize: 2})
+		if err != nil {
+			t.Fatalf("list comments failed: %v", err)
+		}
+		for _, c := range page.Comments {
+			bodies = append(bodies, c.Body)
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		token = page.NextPageToken
+		if first == "" {
+			first = token
+		}
+	}
+	if len(bodies) != 5 {
+		t.Fatalf("expected 5 comments across pages, got %v", bodies)
+	}
+	for i, b := range bodies {
+		if b != fmt.Sprintf("comment %d", i) {
+			t.Errorf("expected comments in creation order, got %v", bodies)
+			break
+		}
+	}
+
+	// токен подписан и привязан к списку и задаче
+	other, _ := ts.CreateTask(ctx, &proto.CreateTaskRequest{Title: "Other"})
+	invalid := map[string]error{}
+	_, invalid["garbage"] = ts.ListComments(ctx, &proto.ListCommentsRequest{TaskId: task.TaskId, PageToken: "garbage!"})
+	_, invalid["tampered"] = ts.ListComments(ctx, &proto.ListCommentsRequest{TaskId: task.TaskId, PageToken: "f" + first[1:]})
+	_, invalid["other task"] = ts.ListComments(ctx, &proto.ListCommentsRequest{TaskId: other.TaskId, PageToken: first})
+	_, invalid["history"] = ts.GetTaskHistory(ctx, &proto.GetTaskHistoryRequest{TaskId: task.TaskId, PageToken: first})
+	for name, err := range invalid {
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument for page_token, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package test
+
+import (
+	"testing"
+
+	"task-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSubtasks_Progress(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	editor := ctxWithJWT(makeJWT(t, "testsecret", editorID, "user"))
+	stray := ctxWithJWT(makeJWT(t, "testsecret", strayID, "user"))
+	projectID := newBoard(t, ts, owner)
+
+	parent, err := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Epic", ProjectId: projectID})
+	if err != nil {
+		t.Fatalf("create parent failed: %v", err)
+	}
+	// подзадача без project_id наследует проект родителя
+	first, err := ts.CreateTask(editor, &proto.CreateTaskRequest{Title: "Part 1", ParentId: parent.TaskId})
+	if err != nil {
+		t.Fatalf("create subtask failed: %v", err)
+	}
+	second, err := ts.CreateTask(editor, &proto.CreateTaskRequest{Title: "Part 2", ProjectId: projectID, ParentId: parent.TaskId})
+	if err != nil {
+		t.Fatalf("create subtask failed: %v", err)
+	}
+	got, _ := ts.GetTask(editor, &proto.GetTaskRequest{TaskId: first.TaskId})
+	if got.Task.ParentId != parent.TaskId || got.Task.ProjectId != projectID {
+		t.Errorf("expected subtask in parent's project, got %v", got.Task)
+	}
+	if _, err := ts.CreateTask(stray, &proto.CreateTaskRequest{Title: "Sneaky", ParentId: parent.TaskId}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for invisible parent, got %v", err)
+	}
+	if _, err := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Elsewhere", ParentId: first.TaskId, ProjectId: newBoard(t, ts, owner)}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for project mismatch, got %v", err)
+	}
+
+	if _, err := ts.ChangeStatus(editor, &proto.ChangeStatusRequest{TaskId: first.TaskId, Status: "done"}); err != nil {
+		t.Fatalf("change status failed: %v", err)
+	}
+	got, _ = ts.GetTask(editor, &proto.GetTaskRequest{TaskId: parent.TaskId})
+	if got.Task.SubtasksTotal != 2 || got.Task.SubtasksDone != 1 {
+		t.Errorf("expected progress 1/2, got %d/%d", got.Task.SubtasksDone, got.Task.SubtasksTotal)
+	}
+
+	list, err := ts.ListTasks(editor, &proto.ListTasksRequest{ParentId: parent.TaskId, Page: 1, PageSize: 10})
+	if err != nil || len(list.Tasks) != 2 {
+		t.Fatalf("expected 2 subtasks, got %v, %v", list, err)
+	}
+	if _, err := ts.ListTasks(stray, &proto.ListTasksRequest{ParentId: parent.TaskId, Page: 1, PageSize: 10}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound listing subtasks of invisible task, got %v", err)
+	}
+
+	// отвязка подзадачи
+	detached, err := ts.SetTaskParent(editor, &proto.SetTaskParentRequest{TaskId: second.TaskId})
+	if err != nil || detached.Task.ParentId != "" {
+		t.Fatalf("expected subtask detached, got %v, %v", detached, err)
+	}
+	got, _ = ts.GetTask(editor, &proto.GetTaskRequest{TaskId: parent.TaskId})
+	if got.Task.SubtasksTotal != 1 || got.Task.SubtasksDone != 1 {
+		t.Errorf("expected progress 1/1, got %d/%d", got.Task.SubtasksDone, got.Task.SubtasksTotal)
+	}
+
+	// удаление родителя делает подзадачи задачами верхнего уровня
+	if _, err := ts.DeleteTask(owner, &proto.DeleteTaskRequest{TaskId: parent.TaskId}); err != nil {
+		t.Fatalf("delete parent failed: %v", err)
+	}
+	got, err = ts.GetTask(editor, &proto.GetTaskRequest{TaskId: first.TaskId})
+	if err != nil || got.Task.ParentId != "" {
+		t.Errorf("expected orphaned subtask detached, got %v, %v", got, err)
+	}
+}
+
+func TestSubtasks_ParentCycle(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	viewer := ctxWithJWT(makeJWT(t, "testsecret", viewerID, "user"))
+	projectID := newBoard(t, ts, owner)
+
+	a, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "A", ProjectId: projectID})
+	b, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "B", ParentId: a.TaskId})
+	c, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "C", ParentId: b.TaskId})
+	personal, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Personal"})
+
+	if _, err := ts.SetTaskParent(owner, &proto.SetTaskParentRequest{TaskId: a.TaskId, ParentId: c.TaskId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for parent cycle, got %v", err)
+	}
+	if _, err := ts.SetTaskParent(owner, &proto.SetTaskParentRequest{TaskId: a.TaskId, ParentId: a.TaskId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for self parent, got %v", err)
+	}
+	if _, err := ts.SetTaskParent(owner, &proto.SetTaskParentRequest{TaskId: personal.TaskId, ParentId: a.TaskId}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for cross-project parent, got %v", err)
+	}
+	if _, err := ts.SetTaskParent(viewer, &proto.SetTaskParentRequest{TaskId: c.TaskId, ParentId: a.TaskId}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for viewer, got %v", err)
+	}
+	moved, err := ts.SetTaskParent(owner, &proto.SetTaskParentRequest{TaskId: c.TaskId, ParentId: a.TaskId})
+	if err != nil || moved.Task.ParentId != a.TaskId {
+		t.Fatalf("expected C moved under A, got %v, %v", moved, err)
+	}
+	if moved.Task.SubtasksTotal != 0 {
+		t.Errorf("expected C without subtasks, got %d", moved.Task.SubtasksTotal)
+	}
+	fields, _ := historyFields(t, ts, c.TaskId)
+	if fields[len(fields)-1] != "update/parent_id" {
+		t.Errorf("expected parent change in history, got %v", fields)
+	}
+}
+
+func TestDependencies_BlockDone(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	editor := ctxWithJWT(makeJWT(t, "testsecret", editorID, "user"))
+	viewer := ctxWithJWT(makeJWT(t, "testsecret", viewerID, "user"))
+	projectID := newBoard(t, ts, owner)
+
+	blocker, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Blocker", ProjectId: projectID})
+	blocked, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Blocked", ProjectId: projectID})
+	hidden, _ := ts.CreateTask(ctxWithJWT(makeJWT(t, "testsecret", strayID, "user")), &proto.CreateTaskRequest{Title: "Hidden"})
+
+	if _, err := ts.AddDependency(viewer, &proto.AddDependencyRequest{TaskId: blocked.TaskId, BlockerId: blocker.TaskId}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for viewer, got %v", err)
+	}
+	if _, err := ts.AddDependency(editor, &proto.AddDependencyRequest{TaskId: blocked.TaskId, BlockerId: blocked.TaskId}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for self dependency, got %v", err)
+	}
+	if _, err := ts.AddDependency(editor, &proto.AddDependencyRequest{TaskId: blocked.TaskId, BlockerId: hidden.TaskId}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for invisible blocker, got %v", err)
+	}
+	if _, err := ts.AddDependency(editor, &proto.AddDependencyRequest{TaskId: blocked.TaskId, BlockerId: blocker.TaskId}); err != nil {
+		t.Fatalf("add dependency failed: %v", err)
+	}
+
+	deps, err := ts.ListDependencies(viewer, &proto.ListDependenciesRequest{TaskId: blocked.TaskId})
+	if err != nil || len(deps.BlockedBy) != 1 || deps.BlockedBy[0].Id != blocker.TaskId || len(deps.Blocks) != 0 {
+		t.Fatalf("expected one blocker, got %v, %v", deps, err)
+	}
+
+	if _, err := ts.ChangeStatus(editor, &proto.ChangeStatusRequest{TaskId: blocked.TaskId, Status: "done"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition while blocker is open, got %v", err)
+	}
+	if _, err := ts.UpdateTask(editor, &proto.UpdateTaskRequest{TaskId: blocked.TaskId, Title: "Blocked", Status: "done"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition on update while blocker is open, got %v", err)
+	}
+	if _, err := ts.ChangeStatus(editor, &proto.ChangeStatusRequest{TaskId: blocked.TaskId, Status: "in_progress"}); err != nil {
+		t.Errorf("expected non-done status allowed, got %v", err)
+	}
+	batch, err := ts.BatchUpdateTasks(editor, &proto.BatchUpdateTasksRequest{TaskIds: []string{blocked.TaskId}, Status: "done"})
+	if err != nil || batch.Updated != 0 || codes.Code(batch.Results[0].Code) != codes.FailedPrecondition {
+		t.Errorf("expected batch result FailedPrecondition while blocker is open, got %v, %v", batch, err)
+	}
+	if _, err := ts.ChangeStatus(editor, &proto.ChangeStatusRequest{TaskId: blocker.TaskId, Status: "done"}); err != nil {
+		t.Fatalf("finish blocker failed: %v", err)
+	}
+	if _, err := ts.ChangeStatus(editor, &proto.ChangeStatusRequest{TaskId: blocked.TaskId, Status: "done"}); err != nil {
+		t.Errorf("expected done allowed after blocker is done, got %v", err)
+	}
+
+	if _, err := ts.RemoveDependency(editor, &proto.RemoveDependencyRequest{TaskId: blocked.TaskId, BlockerId: blocker.TaskId}); err != nil {
+		t.Fatalf("remove dependency failed: %v", err)
+	}
+	if _, err := ts.RemoveDependency(editor, &proto.RemoveDependencyRequest{TaskId: blocked.TaskId, BlockerId: blocker.TaskId}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound removing missing dependency, got %v", err)
+	}
+	fields, _ := historyFields(t, ts, blocked.TaskId)
+	want := "dependency_add/blocker:" + blocker.TaskId
+	found := false
+	for _, f := range fields {
+		found = found || f == want
+	}
+	if !found {
+		t.Errorf("expected %q in history, got %v", want, fields)
+	}
+}
+
+func TestDependencies_Cycle(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	projectID := newBoard(t, ts, owner)
+
+	var ids []string
+	for _, title := range []string{"A", "B", "C"} {
+		task, err := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: title, ProjectId: projectID})
+		if err != nil {
+			t.Fatalf("create task failed: %v", err)
+		}
+		ids = append(ids, task.TaskId)
+	}
+	// A блокирует B, B блокирует C
+	if _, err := ts.AddDependency(owner, &proto.AddDependencyRequest{TaskId: ids[1], BlockerId: ids[0]}); err != nil {
+		t.Fatalf("add dependency failed: %v", err)
+	}
+	if _, err := ts.AddDependency(owner, &proto.AddDependencyRequest{TaskId: ids[2], BlockerId: ids[1]}); err != nil {
+		t.Fatalf("add dependency failed: %v", err)
+	}
+	// повторное добавление ничего не меняет
+	if _, err := ts.AddDependency(owner, &proto.AddDependencyRequest{TaskId: ids[2], BlockerId: ids[1]}); err != nil {
+		t.Errorf("expected duplicate dependency accepted, got %v", err)
+	}
+	if _, err := ts.AddDependency(owner, &proto.AddDependencyRequest{TaskId: ids[0], BlockerId: ids[2]}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for dependency cycle, got %v", err)
+	}
+	if _, err := ts.AddDependency(owner, &proto.AddDependencyRequest{TaskId: ids[0], BlockerId: ids[1]}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for direct cycle, got %v", err)
+	}
+
+	deps, err := ts.ListDependencies(owner, &proto.ListDependenciesRequest{TaskId: ids[1]})
+	if err != nil || len(deps.BlockedBy) != 1 || len(deps.Blocks) != 1 || deps.Blocks[0].Id != ids[2] {
+		t.Fatalf("expected B blocked by A and blocking C, got %v, %v", deps, err)
+	}
+
+	// удаление задачи удаляет её зависимости
+	if _, err := ts.DeleteTask(owner, &proto.DeleteTaskRequest{TaskId: ids[1]}); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	deps, err = ts.ListDependencies(owner, &proto.ListDependenciesRequest{TaskId: ids[2]})
+	if err != nil || len(deps.BlockedBy) != 0 {
+		t.Errorf("expected dependencies removed with task, got %v, %v", deps, err)
+	}
+}
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"task-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// historyFields возвращает записи журнала задачи в виде «action/field»
+func historyFields(t *testing.T, ts proto.TaskServiceClient, taskID string) ([]string, []*proto.ActivityEntry) {
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	resp, err := ts.GetTaskHistory(owner, &proto.GetTaskHistoryRequest{TaskId: taskID, PageSize: 200})
+	if err != nil {
+		t.Fatalf("get history failed: %v", err)
+	}
+	var fields []string
+	for _, e := range resp.Entries {
+		fields = append(fields, e.Action+"/"+e.Field)
+	}
+	return fields, resp.Entries
+}
+
+func TestHistory_RecordsMutations(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	editor := ctxWithJWT(makeJWT(t, "testsecret", editorID, "user"))
+	stray := ctxWithJWT(makeJWT(t, "testsecret", strayID, "user"))
+	projectID := newBoard(t, ts, owner)
+	todo, _ := ts.CreateColumn(owner, &proto.CreateColumnRequest{ProjectId: projectID, Name: "Todo"})
+	doing, _ := ts.CreateColumn(owner, &proto.CreateColumnRequest{ProjectId: projectID, Name: "Doing"})
+
+	task, err := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Audited", ProjectId: projectID})
+	if err != nil {
+		t.Fatalf("create task failed: %v", err)
+	}
+	if _, err := ts.UpdateTask(editor, &proto.UpdateTaskRequest{TaskId: task.TaskId, Title: "Audited v2", AssigneeId: editorID}); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if _, err := ts.ChangeStatus(editor, &proto.ChangeStatusRequest{TaskId: task.TaskId, Status: "in_progress"}); err != nil {
+		t.Fatalf("change status failed: %v", err)
+	}
+	if _, err := ts.MoveTask(editor, &proto.MoveTaskRequest{TaskId: task.TaskId, ColumnId: doing.Column.Id}); err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+	if _, err := ts.AddComment(editor, &proto.AddCommentRequest{TaskId: task.TaskId, Body: "done soon"}); err != nil {
+		t.Fatalf("comment failed: %v", err)
+	}
+
+	fields, entries := historyFields(t, ts, task.TaskId)
+	want := []string{
+		"create/", "move/column_id",
+		"update/title", "update/assignee_id",
+		"update/status",
+		"move/column_id", "move/rank",
+		"comment_add/comment:",
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("expected %d entries, got %v", len(want), fields)
+	}
+	for i, w := range want {
+		// поле комментария — comment:<id>
+		if fields[i] != w && !(strings.HasSuffix(w, ":") && strings.HasPrefix(fields[i], w)) {
+			t.Errorf("entry %d: expected %s, got %s", i, w, fields[i])
+		}
+	}
+	title := entries[2]
+	if title.ActorId != editorID || title.OldValue != "Audited" || title.NewValue != "Audited v2" || title.CreatedAt == "" {
+		t.Errorf("unexpected title entry: %v", title)
+	}
+	if e := entries[4]; e.OldValue != "todo" || e.NewValue != "in_progress" {
+		t.Errorf("unexpected status entry: %v", e)
+	}
+	if e := entries[5]; e.OldValue != todo.Column.Id || e.NewValue != doing.Column.Id {
+		t.Errorf("unexpected column entry: %v", e)
+	}
+
+	if _, err := ts.GetTaskHistory(stray, &proto.GetTaskHistoryRequest{TaskId: task.TaskId}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for non-member, got %v", err)
+	}
+}
+
+func TestHistory_FailedMutationNotRecorded(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	projectID := newBoard(t, ts, owner)
+	ts.CreateColumn(owner, &proto.CreateColumnRequest{ProjectId: projectID, Name: "Todo"})
+	full, _ := ts.CreateColumn(owner, &proto.CreateColumnRequest{ProjectId: projectID, Name: "Doing", WipLimit: 1})
+	first, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "First", ProjectId: projectID})
+	second, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Second", ProjectId: projectID})
+	if _, err := ts.MoveTask(owner, &proto.MoveTaskRequest{TaskId: first.TaskId, ColumnId: full.Column.Id}); err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+	before, _ := historyFields(t, ts, second.TaskId)
+
+	if _, err := ts.MoveTask(owner, &proto.MoveTaskRequest{TaskId: second.TaskId, ColumnId: full.Column.Id}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+	if _, err := ts.ChangeStatus(owner, &proto.ChangeStatusRequest{TaskId: second.TaskId, Status: "backlog"}); err != nil {
+		t.Fatalf("change status failed: %v", err)
+	}
+	if _, err := ts.ChangeStatus(owner, &proto.ChangeStatusRequest{TaskId: second.TaskId, Status: "done"}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition for backlog -> done, got %v", err)
+	}
+	after, _ := historyFields(t, ts, second.TaskId)
+	if len(after) != len(before)+1 || after[len(after)-1] != "update/status" {
+		t.Errorf("expected only the successful status change to be recorded, got %v -> %v", before, after)
+	}
+}
+
+func TestHistory_Pagination(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	task, _ := ts.CreateTask(ctx, &proto.CreateTaskRequest{Title: "v0"})
+	for _, title := range []string{"v1", "v2", "v3", "v4"} {
+		ts.UpdateTask(ctx, &proto.UpdateTaskRequest{TaskId: task.TaskId, Title: title})
+	}
+	var values []string
+	token := ""
+	for pages := 0; pages < 10; pages++ {
+		page, err := ts.GetTaskHistory(ctx, &proto.GetTaskHistoryRequest{TaskId: task.TaskId, PageToken: token, PageSize: 2})
+		if err != nil {
+			t.Fatalf("get history failed: %v", err)
+		}
+		for _, e := range page.Entries {
+			values = append(values, e.NewValue)
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		token = page.NextPageToken
+	}
+	if len(values) != 5 || values[0] != "v0" || values[4] != "v4" {
+		t.Errorf("expected create and 4 title changes in order, got %v", values)
+	}
+}
@@ -0,0 +1,159 @@
+package test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"shared/jwks"
+	"shared/revocation"
+	"task-service/security"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newJWKSServer поднимает HTTP-сервер, отдающий JWKS с одним Ed25519-ключом
+func newJWKSServer(t *testing.T, kid string, pub ed25519.PublicKey) *httptest.Server {
+	body, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "OKP", "crv": "Ed25519", "kid": kid, "alg": "EdDSA", "use": "sig",
+			"x": base64.RawURLEncoding.EncodeToString(pub),
+		}},
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestJWKSService_ValidateToken(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	srv := newJWKSServer(t, "key-1", pub)
+	svc := security.NewJWKSService(jwks.NewKeySet(srv.URL))
+
+	sign := func(kid string, key interface{}, method jwt.SigningMethod) string {
+		tok := jwt.NewWithClaims(method, jwt.MapClaims{"user_id": "user-1"})
+		tok.Header["kid"] = kid
+		s, err := tok.SignedString(key)
+		if err != nil {
+			t.Fatalf("failed to sign jwt: %v", err)
+		}
+		return s
+	}
+
+	claims, err := svc.ValidateToken(sign("key-1", priv, jwt.SigningMethodEdDSA))
+	if err != nil || claims["user_id"] != "user-1" {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+
+	_, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := svc.ValidateToken(sign("key-1", otherPriv, jwt.SigningMethodEdDSA)); err == nil {
+		t.Error("expected error for token signed by foreign key")
+	}
+	if _, err := svc.ValidateToken(sign("unknown", priv, jwt.SigningMethodEdDSA)); err == nil {
+		t.Error("expected error for unknown kid")
+	}
+	if _, err := svc.ValidateToken(sign("key-1", []byte("testsecret"), jwt.SigningMethodHS256)); err == nil {
+		t.Error("expected error for HS256 token in JWKS mode")
+	}
+}
+
+func TestKeySet_ThrottlesRefreshWhileUnavailable(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	keys := jwks.NewKeySet(srv.URL)
+
+	for i := 0; i < 5; i++ {
+		if _, err := keys.Key("key-1"); err == nil {
+			t.Fatal("expected error while JWKS is unavailable")
+		}
+	}
+	// неудачная загрузка тоже откладывает следующую на minRefresh
+	if got := requests.Load(); got != 1 {
+		t.Errorf("expected a single JWKS request, got %d", got)
+	}
+}
+
+func TestJWTService_IssuerAudience(t *testing.T) {
+	svc := security.NewJWTService("testsecret").WithIssuer("user-service", "team-platform")
+
+	sign := func(claims jwt.MapClaims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("testsecret"))
+		if err != nil {
+			t.Fatalf("failed to sign jwt: %v", err)
+		}
+		return s
+	}
+
+	valid := sign(jwt.MapClaims{"sub": "user-1", "role": "admin", "iss": "user-service", "aud": "team-platform"})
+	if _, err := svc.ValidateToken(valid); err != nil {
+		t.Errorf("expected valid token, got %v", err)
+	}
+	if _, err := svc.ValidateToken(sign(jwt.MapClaims{"sub": "user-1", "iss": "other", "aud": "team-platform"})); err == nil {
+		t.Error("expected error for wrong issuer")
+	}
+	if _, err := svc.ValidateToken(sign(jwt.MapClaims{"sub": "user-1", "iss": "user-service", "aud": "other"})); err == nil {
+		t.Error("expected error for wrong audience")
+	}
+	if _, err := svc.ValidateToken(sign(jwt.MapClaims{"sub": "user-1"})); err == nil {
+		t.Error("expected error for token without iss/aud")
+	}
+}
+
+func TestJWTService_Revocations(t *testing.T) {
+	issuedAt := time.Now().Add(-time.Minute)
+	revokedAt := time.Now()
+	body, _ := json.Marshal(map[string]interface{}{
+		"tokens": []map[string]interface{}{{"jti": "revoked-jti", "expires_at": time.Now().Add(time.Hour).Unix()}},
+		"users": []map[string]interface{}{{
+			"user_id": "user-2", "revoked_before": revokedAt.Unix(), "revoked_before_ms": revokedAt.UnixMilli(),
+			"expires_at": time.Now().Add(time.Hour).Unix(),
+		}},
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer srv.Close()
+	svc := security.NewJWTService("testsecret").WithRevocations(revocation.NewList(srv.URL))
+
+	sign := func(claims jwt.MapClaims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("testsecret"))
+		if err != nil {
+			t.Fatalf("failed to sign jwt: %v", err)
+		}
+		return s
+	}
+
+	if _, err := svc.ValidateToken(sign(jwt.MapClaims{"user_id": "user-1", "jti": "ok-jti", "iat": issuedAt.Unix()})); err != nil {
+		t.Errorf("expected valid token, got %v", err)
+	}
+	if _, err := svc.ValidateToken(sign(jwt.MapClaims{"user_id": "user-1", "jti": "revoked-jti", "iat": issuedAt.Unix()})); err == nil {
+		t.Error("expected error for revoked jti")
+	}
+	if _, err := svc.ValidateToken(sign(jwt.MapClaims{"user_id": "user-2", "jti": "old-jti", "iat": issuedAt.Unix()})); err == nil {
+		t.Error("expected error for token issued before sessions were revoked")
+	}
+	// iat с миллисекундами: токен, выданный сразу после отзыва (в ту же секунду), действует
+	if _, err := svc.ValidateToken(sign(jwt.MapClaims{"user_id": "user-2", "jti": "new-jti", "iat": unixMilli(revokedAt.Add(time.Millisecond))})); err != nil {
+		t.Errorf("expected token issued after revocation to be valid, got %v", err)
+	}
+	if _, err := svc.ValidateToken(sign(jwt.MapClaims{"user_id": "user-2", "jti": "recent-jti", "iat": unixMilli(revokedAt.Add(-time.Millisecond))})); err == nil {
+		t.Error("expected error for token issued just before sessions were revoked")
+	}
+}
+
+// unixMilli — iat с миллисекундами, как его выдаёт user-service
+func unixMilli(t time.Time) float64 {
+	return float64(t.UnixMilli()) / 1000
+}
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"shared/rbac"
+	"task-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// newPolicyServer поднимает HTTP-сервер, отдающий таблицу прав в формате /internal/policy user-service
+func newPolicyServer(t *testing.T, roles map[string][]string) *httptest.Server {
+	body, _ := json.Marshal(map[string]interface{}{"roles": roles})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPolicy_RemoteRoles(t *testing.T) {
+	srv := newPolicyServer(t, map[string][]string{
+		"user":      {rbac.PermTaskRead, rbac.PermTaskCreate, rbac.PermTaskUpdateOwn, rbac.PermProjectCreate, rbac.PermProjectManageOwn},
+		"moderator": {rbac.PermTaskRead, rbac.PermTaskDeleteAny},
+		"reader":    {rbac.PermTaskRead},
+	})
+	ts := newTaskServer(t)
+	if err := ts.Policy.WithSource(srv.URL).Refresh(); err != nil {
+		t.Fatalf("policy refresh failed: %v", err)
+	}
+	client := newClient(t, ts)
+
+	owner := ctxWithJWT(makeJWT(t, "testsecret", "11111111-1111-1111-1111-111111111111", "user"))
+	project, err := client.CreateProject(owner, &proto.CreateProjectRequest{Name: "Moderated"})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+	for _, id := range []string{"22222222-2222-2222-2222-222222222222", "33333333-3333-3333-3333-333333333333"} {
+		if _, err := client.AddMember(owner, &proto.AddMemberRequest{ProjectId: project.Project.Id, UserId: id, Role: "viewer"}); err != nil {
+			t.Fatalf("add member failed: %v", err)
+		}
+	}
+	resp, err := client.CreateTask(owner, &proto.CreateTaskRequest{Title: "Moderated", ProjectId: project.Project.Id})
+	if err != nil {
+		t.Fatalf("create task failed: %v", err)
+	}
+
+	// у user в этой таблице нет task:delete:own
+	_, err = client.DeleteTask(owner, &proto.DeleteTaskRequest{TaskId: resp.TaskId})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for creator without task:delete:own, got %v", err)
+	}
+
+	reader := ctxWithJWT(makeJWT(t, "testsecret", "22222222-2222-2222-2222-222222222222", "reader"))
+	if _, err := client.GetTask(reader, &proto.GetTaskRequest{TaskId: resp.TaskId}); err != nil {
+		t.Errorf("expected reader to get task, got %v", err)
+	}
+	if _, err := client.CreateTask(reader, &proto.CreateTaskRequest{Title: "Nope"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for reader create, got %v", err)
+	}
+
+	moderator := ctxWithJWT(makeJWT(t, "testsecret", "33333333-3333-3333-3333-333333333333", "moderator"))
+	if _, err := client.DeleteTask(moderator, &proto.DeleteTaskRequest{TaskId: resp.TaskId}); err != nil {
+		t.Errorf("expected moderator to delete any task, got %v", err)
+	}
+
+	// роль admin в таблице user-service отсутствует — прав нет
+	admin := ctxWithJWT(makeJWT(t, "testsecret", "44444444-4444-4444-4444-444444444444", "admin"))
+	if _, err := client.ListTasks(admin, &proto.ListTasksRequest{Page: 1, PageSize: 10}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for role missing in policy, got %v", err)
+	}
+}
+
+func TestPolicy_SourceUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+	p := rbac.NewPolicy(rbac.DefaultRoles()).WithSource(srv.URL)
+	if err := p.Refresh(); err == nil {
+		t.Error("expected refresh error")
+	}
+	// до первой успешной загрузки действуют роли по умолчанию
+	if !p.Allows("admin", rbac.PermTaskDeleteAny) || p.Allows("user", rbac.PermTaskDeleteAny) {
+		t.Error("expected default roles while user-service is unavailable")
+	}
+}
+
+func TestPolicy_BackgroundRefresh(t *testing.T) {
+	roles := map[string][]string{"user": {rbac.PermTaskRead}}
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		json.NewEncoder(w).Encode(map[string]interface{}{"roles": roles})
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+	p := rbac.NewPolicy(rbac.DefaultRoles()).WithSource(srv.URL)
+	p.StartRefresh(10 * time.Millisecond)
+
+	// пока user-service не отвечает, проверки не ждут его и используют текущую таблицу
+	done := make(chan bool)
+	go func() { done <- p.Allows("user", rbac.PermTaskCreate) }()
+	select {
+	case allowed := <-done:
+		if !allowed {
+			t.Error("expected default roles before the first load")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Allows must not wait for user-service")
+	}
+
+	release <- struct{}{}
+	deadline := time.Now().Add(2 * time.Second)
+	for p.Allows("user", rbac.PermTaskCreate) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the table to be reloaded in background")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAuthInterceptor_PublicAndUnauthenticated(t *testing.T) {
+	client := setupTestServer(t)
+	if _, err := client.HealthCheck(context.Background(), &emptypb.Empty{}); status.Code(err) == codes.Unauthenticated {
+		t.Errorf("HealthCheck must not require token, got %v", err)
+	}
+	if _, err := client.ListTasks(ctxWithJWT("bad.token.value"), &proto.ListTasksRequest{Page: 1, PageSize: 10}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated for invalid token, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package test
+
+import (
+	"testing"
+
+	"task-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	ownerID  = "11111111-1111-1111-1111-111111111111"
+	editorID = "22222222-2222-2222-2222-222222222222"
+	viewerID = "33333333-3333-3333-3333-333333333333"
+	strayID  = "44444444-4444-4444-4444-444444444444"
+)
+
+func TestProjects_CreateListAddMember(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	stray := ctxWithJWT(makeJWT(t, "testsecret", strayID, "user"))
+
+	if _, err := ts.CreateProject(owner, &proto.CreateProjectRequest{Name: " "}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for empty name, got %v", err)
+	}
+	resp, err := ts.CreateProject(owner, &proto.CreateProjectRequest{Name: "Board", Description: "Main board"})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+	projectID := resp.Project.Id
+	if resp.Project.OwnerId != ownerID {
+		t.Errorf("expected owner %s, got %s", ownerID, resp.Project.OwnerId)
+	}
+
+	list, err := ts.ListProjects(owner, &proto.ListProjectsRequest{Page: 1, PageSize: 10})
+	if err != nil || len(list.Projects) != 1 {
+		t.Fatalf("expected owner to see 1 project, got %v, %v", list, err)
+	}
+	list, _ = ts.ListProjects(stray, &proto.ListProjectsRequest{Page: 1, PageSize: 10})
+	if len(list.Projects) != 0 {
+		t.Errorf("expected stray user to see no projects, got %d", len(list.Projects))
+	}
+
+	if _, err := ts.AddMember(stray, &proto.AddMemberRequest{ProjectId: projectID, UserId: strayID}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for non-owner, got %v", err)
+	}
+	if _, err := ts.AddMember(owner, &proto.AddMemberRequest{ProjectId: projectID, UserId: editorID, Role: "god"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for unknown role, got %v", err)
+	}
+	if _, err := ts.AddMember(owner, &proto.AddMemberRequest{ProjectId: projectID, UserId: ownerID, Role: "viewer"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for demoting creator, got %v", err)
+	}
+	member, err := ts.AddMember(owner, &proto.AddMemberRequest{ProjectId: projectID, UserId: editorID})
+	if err != nil || member.Role != "editor" {
+		t.Fatalf("expected editor by default, got %v, %v", member, err)
+	}
+	editor := ctxWithJWT(makeJWT(t, "testsecret", editorID, "user"))
+	list, _ = ts.ListProjects(editor, &proto.ListProjectsRequest{Page: 1, PageSize: 10})
+	if len(list.Projects) != 1 {
+		t.Errorf("expected member to see project, got %d", len(list.Projects))
+	}
+
+	admin := ctxWithJWT(makeJWT(t, "testsecret", "admin-id", "admin"))
+	if _, err := ts.AddMember(admin, &proto.AddMemberRequest{ProjectId: projectID, UserId: strayID, Role: "viewer"}); err != nil {
+		t.Errorf("expected admin to manage any project, got %v", err)
+	}
+}
+
+func TestProjects_ListPages(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	for _, name := range []string{"A", "B", "C"} {
+		ts.CreateProject(owner, &proto.CreateProjectRequest{Name: name})
+	}
+
+	list, err := ts.ListProjects(owner, &proto.ListProjectsRequest{Page: 2, PageSize: 2})
+	if err != nil || len(list.Projects) != 1 || list.Projects[0].Name != "C" || list.Total != 3 {
+		t.Fatalf("expected last project and total 3, got %v, %v", list, err)
+	}
+	// размер страницы 0 — значение по умолчанию
+	list, err = ts.ListProjects(owner, &proto.ListProjectsRequest{Page: 1})
+	if err != nil || len(list.Projects) != 3 || list.Total != 3 {
+		t.Errorf("expected default page size, got %v, %v", list, err)
+	}
+	// номер страницы меньше 1 — первая страница
+	for _, page := range []int32{0, -1} {
+		list, err := ts.ListProjects(owner, &proto.ListProjectsRequest{Page: page, PageSize: 2})
+		if err != nil || len(list.Projects) != 2 || list.Projects[0].Name != "A" {
+			t.Errorf("page %d: expected first page, got %v, %v", page, list, err)
+		}
+	}
+}
+
+func TestProjects_TaskVisibility(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	editor := ctxWithJWT(makeJWT(t, "testsecret", editorID, "user"))
+	viewer := ctxWithJWT(makeJWT(t, "testsecret", viewerID, "user"))
+	stray := ctxWithJWT(makeJWT(t, "testsecret", strayID, "user"))
+
+	project, _ := ts.CreateProject(owner, &proto.CreateProjectRequest{Name: "Board"})
+	projectID := project.Project.Id
+	ts.AddMember(owner, &proto.AddMemberRequest{ProjectId: projectID, UserId: editorID, Role: "editor"})
+	ts.AddMember(owner, &proto.AddMemberRequest{ProjectId: projectID, UserId: viewerID, Role: "viewer"})
+
+	task, err := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Board task", ProjectId: projectID})
+	if err != nil {
+		t.Fatalf("create task failed: %v", err)
+	}
+	if _, err := ts.CreateTask(viewer, &proto.CreateTaskRequest{Title: "Viewer task", ProjectId: projectID}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for viewer create, got %v", err)
+	}
+	if _, err := ts.CreateTask(stray, &proto.CreateTaskRequest{Title: "Stray task", ProjectId: projectID}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for non-member create, got %v", err)
+	}
+	ts.CreateTask(stray, &proto.CreateTaskRequest{Title: "Personal"})
+
+	got, err := ts.GetTask(viewer, &proto.GetTaskRequest{TaskId: task.TaskId})
+	if err != nil || got.Task.ProjectId != projectID {
+		t.Errorf("expected viewer to see project task, got %v, %v", got, err)
+	}
+	if _, err := ts.GetTask(stray, &proto.GetTaskRequest{TaskId: task.TaskId}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for non-member, got %v", err)
+	}
+
+	if _, err := ts.UpdateTask(editor, &proto.UpdateTaskRequest{TaskId: task.TaskId, Title: "By editor"}); err != nil {
+		t.Errorf("expected editor to update teammate's task, got %v", err)
+	}
+	if _, err := ts.UpdateTask(viewer, &proto.UpdateTaskRequest{TaskId: task.TaskId, Title: "By viewer"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for viewer update, got %v", err)
+	}
+	if _, err := ts.DeleteTask(editor, &proto.DeleteTaskRequest{TaskId: task.TaskId}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for editor delete, got %v", err)
+	}
+
+	list, err := ts.ListTasks(viewer, &proto.ListTasksRequest{Page: 1, PageSize: 10})
+	if err != nil || len(list.Tasks) != 1 || list.Tasks[0].Id != task.TaskId {
+		t.Errorf("expected viewer to list only the project task, got %v, %v", list, err)
+	}
+	list, _ = ts.ListTasks(stray, &proto.ListTasksRequest{Page: 1, PageSize: 10})
+	if len(list.Tasks) != 1 || list.Tasks[0].Title != "Personal" {
+		t.Errorf("expected stray user to list only personal task, got %v", list.Tasks)
+	}
+	if _, err := ts.ListTasks(stray, &proto.ListTasksRequest{ProjectId: projectID, Page: 1, PageSize: 10}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied listing foreign project, got %v", err)
+	}
+
+	admin := ctxWithJWT(makeJWT(t, "testsecret", "admin-id", "admin"))
+	list, _ = ts.ListTasks(admin, &proto.ListTasksRequest{Page: 1, PageSize: 10})
+	if len(list.Tasks) != 2 {
+		t.Errorf("expected admin to list all tasks, got %d", len(list.Tasks))
+	}
+
+	if _, err := ts.DeleteTask(owner, &proto.DeleteTaskRequest{TaskId: task.TaskId}); err != nil {
+		t.Errorf("expected project owner to delete task, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package test
+
+import (
+	"testing"
+
+	"task-service/rank"
+)
+
+func TestRank_Between(t *testing.T) {
+	cases := []struct{ prev, next string }{
+		{"", ""}, {"", "1"}, {"a", ""}, {"a", "b"}, {"ab", "ac"}, {"a", "a1"}, {"az", "b"}, {"0i", "1"},
+	}
+	for _, c := range cases {
+		got, err := rank.Between(c.prev, c.next)
+		if err != nil {
+			t.Fatalf("Between(%q, %q): %v", c.prev, c.next, err)
+		}
+		if got <= c.prev || (c.next != "" && got >= c.next) || got[len(got)-1] == '0' {
+			t.Errorf("Between(%q, %q) = %q, not strictly between", c.prev, c.next, got)
+		}
+	}
+	for _, c := range []struct{ prev, next string }{{"b", "a"}, {"a", "a"}, {"A", ""}} {
+		if _, err := rank.Between(c.prev, c.next); err != rank.ErrInvalidRange {
+			t.Errorf("Between(%q, %q): expected ErrInvalidRange, got %v", c.prev, c.next, err)
+		}
+	}
+}
+
+func TestRank_RepeatedInsertKeepsOrder(t *testing.T) {
+	// многократная вставка в одно и то же место: позиции растут в длину, но порядок сохраняется
+	prev, next := "", ""
+	for i := 0; i < 200; i++ {
+		mid, err := rank.Between(prev, next)
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if i%2 == 0 {
+			next = mid
+		} else {
+			prev = mid
+		}
+	}
+	if prev >= next {
+		t.Errorf("expected %q < %q", prev, next)
+	}
+}
@@ -0,0 +1,261 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"task-service/model"
+	"task-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// listTitles возвращает названия задач ответа ListTasks по порядку и total
+func listTitles(t *testing.T, ts proto.TaskServiceClient, req *proto.ListTasksRequest) ([]string, int32) {
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	if req.PageSize == 0 {
+		req.PageSize = 50
+	}
+	req.IncludeTotal = true
+	resp, err := ts.ListTasks(owner, req)
+	if err != nil {
+		t.Fatalf("list tasks failed: %v", err)
+	}
+	var titles []string
+	for _, task := range resp.Tasks {
+		titles = append(titles, task.Title)
+	}
+	return titles, resp.Total
+}
+
+func TestListTasks_Filters(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	projectID := newBoard(t, ts, owner)
+	day := func(d int) string { return time.Now().UTC().AddDate(0, 0, d).Format(time.RFC3339) }
+
+	for _, req := range []*proto.CreateTaskRequest{
+		{Title: "Fix login bug", Description: "OAuth redirect fails", Labels: []string{"bug", "auth"}, DueDate: day(-2), AssigneeId: editorID},
+		{Title: "Write docs", Description: "API reference", Labels: []string{"docs"}, DueDate: day(3)},
+		{Title: "Refactor auth", Description: "split login handler", Labels: []string{"auth", "tech-debt"}, DueDate: day(-1), AssigneeId: editorID},
+		{Title: "Release", Labels: []string{"100%_done"}},
+	} {
+		req.ProjectId = projectID
+		if _, err := ts.CreateTask(owner, req); err != nil {
+			t.Fatalf("create task failed: %v", err)
+		}
+	}
+	editor := ctxWithJWT(makeJWT(t, "testsecret", editorID, "user"))
+	if _, err := ts.CreateTask(editor, &proto.CreateTaskRequest{Title: "Editor task", ProjectId: projectID}); err != nil {
+		t.Fatalf("create task failed: %v", err)
+	}
+
+	got, err := ts.GetTask(owner, &proto.GetTaskRequest{TaskId: mustFirstID(t, ts, "Fix login bug")})
+	if err != nil || !reflect.DeepEqual(got.Task.Labels, []string{"bug", "auth"}) {
+		t.Fatalf("expected labels stored, got %v, %v", got, err)
+	}
+
+	tests := []struct {
+		name string
+		req  *proto.ListTasksRequest
+		want []string
+	}{
+		{"assignee", &proto.ListTasksRequest{AssigneeId: editorID}, []string{"Fix login bug", "Refactor auth"}},
+		{"creator", &proto.ListTasksRequest{CreatorId: editorID}, []string{"Editor task"}},
+		{"labels any", &proto.ListTasksRequest{Labels: []string{"docs", "bug"}}, []string{"Fix login bug", "Write docs"}},
+		{"labels all", &proto.ListTasksRequest{Labels: []string{"auth", "bug"}, LabelsMatch: "all"}, []string{"Fix login bug"}},
+		{"label with like wildcards", &proto.ListTasksRequest{Labels: []string{"100%_done"}}, []string{"Release"}},
+		{"label prefix is not a match", &proto.ListTasksRequest{Labels: []string{"tech"}}, nil},
+		{"due range", &proto.ListTasksRequest{DueFrom: day(0), DueTo: day(5)}, []string{"Write docs"}},
+		{"overdue", &proto.ListTasksRequest{Overdue: true}, []string{"Fix login bug", "Refactor auth"}},
+		{"search", &proto.ListTasksRequest{Search: "login"}, []string{"Fix login bug", "Refactor auth"}},
+		{"search description", &proto.ListTasksRequest{Search: "reference"}, []string{"Write docs"}},
+		{"sort by due date", &proto.ListTasksRequest{Sort: "due_date,title"}, []string{"Fix login bug", "Refactor auth", "Write docs", "Editor task", "Release"}},
+		{"sort by title desc", &proto.ListTasksRequest{Sort: "-title", Labels: []string{"auth", "docs"}}, []string{"Write docs", "Refactor auth", "Fix login bug"}},
+	}
+	for _, tt := range tests {
+		tt.req.ProjectId = projectID
+		titles, total := listTitles(t, ts, tt.req)
+		if !reflect.DeepEqual(titles, tt.want) || int(total) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v (total %d)", tt.name, tt.want, titles, total)
+		}
+	}
+
+	// задача без срока остаётся в конце и при сортировке по убыванию
+	titles, _ := listTitles(t, ts, &proto.ListTasksRequest{ProjectId: projectID, Sort: "-due_date,title"})
+	if want := []string{"Write docs", "Refactor auth", "Fix login bug", "Editor task", "Release"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("expected %v, got %v", want, titles)
+	}
+
+	// overdue не включает выполненные задачи
+	if _, err := ts.ChangeStatus(owner, &proto.ChangeStatusRequest{TaskId: mustFirstID(t, ts, "Refactor auth"), Status: "done"}); err != nil {
+		t.Fatalf("change status failed: %v", err)
+	}
+	if titles, _ := listTitles(t, ts, &proto.ListTasksRequest{ProjectId: projectID, Overdue: true}); !reflect.DeepEqual(titles, []string{"Fix login bug"}) {
+		t.Errorf("expected done task excluded from overdue, got %v", titles)
+	}
+}
+
+func TestListTasks_LegacyPageAndTotal(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	for _, title := range []string{"A", "B", "C", "D", "E"} {
+		if _, err := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: title}); err != nil {
+			t.Fatalf("create task failed: %v", err)
+		}
+	}
+	titles, total := listTitles(t, ts, &proto.ListTasksRequest{Page: 2, PageSize: 2, Sort: "title"})
+	if !reflect.DeepEqual(titles, []string{"C", "D"}) || total != 5 {
+		t.Errorf("expected page [C D] of 5, got %v of %d", titles, total)
+	}
+	// без include_total общее число не считается; page 0 — первая страница
+	resp, err := ts.ListTasks(owner, &proto.ListTasksRequest{Page: 0, PageSize: 2, Sort: "title"})
+	if err != nil || len(resp.Tasks) != 2 || resp.Tasks[0].Title != "A" || resp.Total != 0 {
+		t.Errorf("expected first page without total, got %v, %v", resp, err)
+	}
+}
+
+// walkPages обходит выборку по next_page_token страницами по size задач и возвращает названия
+func walkPages(t *testing.T, ts proto.TaskServiceClient, req *proto.ListTasksRequest, size int32) []string {
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	var titles []string
+	req.PageSize = size
+	for i := 0; ; i++ {
+		if i > 20 {
+			t.Fatalf("pagination does not terminate: %v", titles)
+		}
+		resp, err := ts.ListTasks(owner, req)
+		if err != nil {
+			t.Fatalf("list page failed: %v", err)
+		}
+		if len(resp.Tasks) > int(size) {
+			t.Fatalf("page larger than page_size: %d", len(resp.Tasks))
+		}
+		for _, task := range resp.Tasks {
+			titles = append(titles, task.Title)
+		}
+		if resp.NextPageToken == "" {
+			return titles
+		}
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+func TestListTasks_PageTokens(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	due := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	// одинаковые сроки и названия проверяют дозаполнение ключа по id; у C и E срока нет
+	for _, task := range []struct {
+		title string
+		due   int
+	}{{"A", 2}, {"B", 1}, {"C", 0}, {"D", 1}, {"E", 0}, {"F", 3}, {"G", 1}} {
+		req := &proto.CreateTaskRequest{Title: task.title}
+		if task.due > 0 {
+			req.DueDate = due.AddDate(0, 0, task.due).Format(time.RFC3339)
+		}
+		if _, err := ts.CreateTask(owner, req); err != nil {
+			t.Fatalf("create task failed: %v", err)
+		}
+	}
+
+	for _, sort := range []string{"", "title", "-title", "due_date", "-due_date", "due_date,-title", "-due_date,title"} {
+		want, _ := listTitles(t, ts, &proto.ListTasksRequest{Sort: sort})
+		for _, size := range []int32{1, 2, 3} {
+			got := walkPages(t, ts, &proto.ListTasksRequest{Sort: sort}, size)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("sort %q, page size %d: expected %v, got %v", sort, size, want, got)
+			}
+		}
+	}
+
+	first, err := ts.ListTasks(owner, &proto.ListTasksRequest{PageSize: 2, Sort: "title"})
+	if err != nil || first.NextPageToken == "" {
+		t.Fatalf("expected next page token, got %v, %v", first, err)
+	}
+	token := first.NextPageToken
+	for name, req := range map[string]*proto.ListTasksRequest{
+		"tampered":     {PageToken: "x" + token, Sort: "title"},
+		"other sort":   {PageToken: token, Sort: "-title"},
+		"other filter": {PageToken: token, Sort: "title", Search: "A"},
+		"garbage":      {PageToken: "not-a-token"},
+	} {
+		if _, err := ts.ListTasks(owner, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, got %v", name, err)
+		}
+	}
+	// размер страницы меняется между запросами — токен остаётся действительным
+	if resp, err := ts.ListTasks(owner, &proto.ListTasksRequest{PageToken: token, Sort: "title", PageSize: 10}); err != nil || len(resp.Tasks) != 5 {
+		t.Errorf("expected remaining 5 tasks, got %v, %v", resp, err)
+	}
+}
+
+func TestListTasks_PageSizeLimits(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	for i := 0; i < 105; i++ {
+		if _, err := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "T"}); err != nil {
+			t.Fatalf("create task failed: %v", err)
+		}
+	}
+	resp, err := ts.ListTasks(owner, &proto.ListTasksRequest{})
+	if err != nil || len(resp.Tasks) != 20 || resp.NextPageToken == "" {
+		t.Errorf("expected default page of 20, got %d, %v", len(resp.GetTasks()), err)
+	}
+	resp, err = ts.ListTasks(owner, &proto.ListTasksRequest{PageSize: 1000, IncludeTotal: true})
+	if err != nil || len(resp.Tasks) != 100 || resp.Total != 105 {
+		t.Errorf("expected page capped at 100 of 105, got %d of %d, %v", len(resp.GetTasks()), resp.GetTotal(), err)
+	}
+}
+
+func TestListTasks_InvalidArguments(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	for _, req := range []*proto.ListTasksRequest{
+		{AssigneeId: "not-a-uuid"},
+		{CreatorId: "not-a-uuid"},
+		{LabelsMatch: "some"},
+		{DueFrom: "yesterday"},
+		{Sort: "priority"},
+		{Sort: "title,-secret"},
+	} {
+		req.Page, req.PageSize = 1, 10
+		if _, err := ts.ListTasks(owner, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%v: expected InvalidArgument, got %v", req, err)
+		}
+	}
+}
+
+func TestLabels_ArrayLiteral(t *testing.T) {
+	labels := model.Labels{"a", `say "hi"`, `back\slash`, "x,y"}
+	value, err := labels.Value()
+	if err != nil {
+		t.Fatalf("value failed: %v", err)
+	}
+	var scanned model.Labels
+	if err := scanned.Scan(value); err != nil || !reflect.DeepEqual(scanned, labels) {
+		t.Errorf("expected round trip %v, got %v, %v", labels, scanned, err)
+	}
+	// вывод Postgres: простые элементы без кавычек, NULL пропускается
+	if err := scanned.Scan([]byte(`{bug,"two words",NULL}`)); err != nil || !reflect.DeepEqual(scanned, model.Labels{"bug", "two words"}) {
+		t.Errorf("expected postgres literal parsed, got %v, %v", scanned, err)
+	}
+	if err := scanned.Scan("{}"); err != nil || len(scanned) != 0 {
+		t.Errorf("expected empty labels, got %v, %v", scanned, err)
+	}
+	if err := scanned.Scan(`{"unterminated}`); err == nil {
+		t.Error("expected error for invalid literal")
+	}
+}
+
+// mustFirstID возвращает id первой задачи владельца с названием title
+func mustFirstID(t *testing.T, ts proto.TaskServiceClient, title string) string {
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	resp, err := ts.ListTasks(owner, &proto.ListTasksRequest{Search: title, Page: 1, PageSize: 1})
+	if err != nil || len(resp.Tasks) == 0 {
+		t.Fatalf("task %q not found: %v", title, err)
+	}
+	return resp.Tasks[0].Id
+}
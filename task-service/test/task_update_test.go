@@ -2,8 +2,17 @@ package test
 
 import (
 	"context"
+	"errors"
+	"task-service/handler"
 	"task-service/proto"
+	"task-service/repository"
 	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 func TestUpdateTask(t *testing.T) {
@@ -66,3 +75,180 @@ func TestUpdateTask_Auth_EdgeCases(t *testing.T) {
 		t.Errorf("expected update by admin, got err: %v", err)
 	}
 }
+
+func TestUpdateTask_UnconfirmedEmail(t *testing.T) {
+	ts := setupTestServer(t)
+	user1 := "11111111-1111-1111-1111-111111111111"
+	ctx := ctxWithJWT(makeJWT(t, "testsecret", user1, "user"))
+	resp, err := ts.CreateTask(ctx, &proto.CreateTaskRequest{Title: "Task"})
+	if err != nil {
+		t.Fatalf("create task failed: %v", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": user1, "role": "user", "email_verified": false})
+	tokStr, _ := token.SignedString([]byte("testsecret"))
+	_, err = ts.UpdateTask(ctxWithJWT(tokStr), &proto.UpdateTaskRequest{TaskId: resp.TaskId, Title: "Unconfirmed"})
+	if err == nil {
+		t.Error("expected error for token issued before email confirmation")
+	}
+}
+
+func TestUpdateTask_ExpectedVersion(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := ctxWithJWT(makeJWT(t, "testsecret", "11111111-1111-1111-1111-111111111111", "user"))
+	resp, err := ts.CreateTask(ctx, &proto.CreateTaskRequest{Title: "Versioned"})
+	if err != nil {
+		t.Fatalf("create task failed: %v", err)
+	}
+	version := func() int64 {
+		got, err := ts.GetTask(ctx, &proto.GetTaskRequest{TaskId: resp.TaskId})
+		if err != nil {
+			t.Fatalf("get task failed: %v", err)
+		}
+		return got.Task.Version
+	}
+	if v := version(); v != 1 {
+		t.Fatalf("expected version 1 for a new task, got %d", v)
+	}
+
+	upd, err := ts.UpdateTask(ctx, &proto.UpdateTaskRequest{TaskId: resp.TaskId, Title: "First", ExpectedVersion: 1})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if upd.Version != 2 || version() != 2 {
+		t.Fatalf("expected version 2 after update, got %d", upd.Version)
+	}
+
+	// правка на основе устаревшей версии отклоняется и ничего не меняет
+	_, err = ts.UpdateTask(ctx, &proto.UpdateTaskRequest{TaskId: resp.TaskId, Title: "Stale", ExpectedVersion: 1})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition for a stale version, got %v", err)
+	}
+	// деталь VERSION отличает устаревшую версию от прочих FailedPrecondition (в api-gateway — 412)
+	details := status.Convert(err).Details()
+	if len(details) != 1 {
+		t.Fatalf("expected one error detail, got %v", details)
+	}
+	if failure, ok := details[0].(*errdetails.PreconditionFailure); !ok || failure.Violations[0].Type != handler.PreconditionVersion {
+		t.Errorf("expected VERSION precondition failure, got %v", details[0])
+	}
+	got, _ := ts.GetTask(ctx, &proto.GetTaskRequest{TaskId: resp.TaskId})
+	if got.Task.Title != "First" || got.Task.Version != 2 {
+		t.Fatalf("stale update must not change the task, got %q v%d", got.Task.Title, got.Task.Version)
+	}
+
+	// смена статуса тоже увеличивает версию
+	if _, err := ts.ChangeStatus(ctx, &proto.ChangeStatusRequest{TaskId: resp.TaskId, Status: "todo"}); err != nil {
+		t.Fatalf("change status failed: %v", err)
+	}
+	if v := version(); v != 3 {
+		t.Fatalf("expected version 3 after status change, got %d", v)
+	}
+
+	// без expected_version обновление проходит
+	if upd, err = ts.UpdateTask(ctx, &proto.UpdateTaskRequest{TaskId: resp.TaskId, Title: "Unchecked"}); err != nil || upd.Version != 4 {
+		t.Fatalf("expected unchecked update to version 4, got %v %v", upd, err)
+	}
+}
+
+func TestUpdateTask_ConcurrentWrite(t *testing.T) {
+	server := newTaskServer(t)
+	ts := newClient(t, server)
+	ctx := ctxWithJWT(makeJWT(t, "testsecret", "11111111-1111-1111-1111-111111111111", "user"))
+	resp, err := ts.CreateTask(ctx, &proto.CreateTaskRequest{Title: "Shared"})
+	if err != nil {
+		t.Fatalf("create task failed: %v", err)
+	}
+
+	// два писателя прочитали одну и ту же версию; второй не должен затереть изменения первого
+	first, _ := server.Repo.GetTaskByID(resp.TaskId)
+	second, _ := server.Repo.GetTaskByID(resp.TaskId)
+	first.Title = "First writer"
+	if err := server.Repo.UpdateTask(first); err != nil {
+		t.Fatalf("first update failed: %v", err)
+	}
+	second.Title = "Second writer"
+	if err := server.Repo.UpdateTask(second); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+	if second.Version != 1 {
+		t.Fatalf("failed update must keep the read version, got %d", second.Version)
+	}
+	got, _ := server.Repo.GetTaskByID(resp.TaskId)
+	if got.Title != "First writer" || got.Version != 2 {
+		t.Fatalf("expected first writer's title at version 2, got %q v%d", got.Title, got.Version)
+	}
+}
+
+func TestUpdateTask_FieldMask(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := ctxWithJWT(makeJWT(t, "testsecret", "11111111-1111-1111-1111-111111111111", "user"))
+	assignee := "33333333-3333-3333-3333-333333333333"
+	resp, err := ts.CreateTask(ctx, &proto.CreateTaskRequest{Title: "Masked", Description: "keep me", Labels: []string{"bug"},
+		AssigneeId: assignee, DueDate: "2026-03-01T10:00:00Z"})
+	if err != nil {
+		t.Fatalf("create task failed: %v", err)
+	}
+	update := func(req *proto.UpdateTaskRequest, paths ...string) (*proto.Task, error) {
+		req.TaskId = resp.TaskId
+		req.UpdateMask = &fieldmaskpb.FieldMask{Paths: paths}
+		if _, err := ts.UpdateTask(ctx, req); err != nil {
+			return nil, err
+		}
+		got, err := ts.GetTask(ctx, &proto.GetTaskRequest{TaskId: resp.TaskId})
+		if err != nil {
+			t.Fatalf("get task failed: %v", err)
+		}
+		return got.Task, nil
+	}
+
+	// меняется только название, остальные поля не нужно пересылать
+	task, err := update(&proto.UpdateTaskRequest{Title: "Renamed"}, "title")
+	if err != nil {
+		t.Fatalf("masked update failed: %v", err)
+	}
+	if task.Title != "Renamed" || task.Description != "keep me" || len(task.Labels) != 1 ||
+		task.AssigneeId != assignee || task.DueDate != "2026-03-01T10:00:00Z" {
+		t.Fatalf("expected only the title to change, got %v", task)
+	}
+
+	// пустые значения в маске снимают исполнителя и срок
+	task, err = update(&proto.UpdateTaskRequest{}, "assignee_id", "due_date")
+	if err != nil {
+		t.Fatalf("clearing update failed: %v", err)
+	}
+	if task.AssigneeId != "00000000-0000-0000-0000-000000000000" || task.DueDate != "" || task.Title != "Renamed" {
+		t.Fatalf("expected assignee and due date cleared, got %v", task)
+	}
+
+	// статус по маске; пустое название вне маски не проверяется
+	task, err = update(&proto.UpdateTaskRequest{StatusCode: proto.TaskStatus_TASK_STATUS_TODO}, "status_code")
+	if err != nil || task.Status != "todo" || task.Title != "Renamed" {
+		t.Fatalf("expected status todo, got %v %v", task, err)
+	}
+
+	for _, c := range []struct {
+		name  string
+		req   *proto.UpdateTaskRequest
+		paths []string
+	}{
+		{"unknown field", &proto.UpdateTaskRequest{Title: "x"}, []string{"creator_id"}},
+		{"empty title", &proto.UpdateTaskRequest{}, []string{"title"}},
+		{"empty status", &proto.UpdateTaskRequest{}, []string{"status"}},
+		{"invalid assignee", &proto.UpdateTaskRequest{AssigneeId: "nope"}, []string{"assignee_id"}},
+		{"invalid due date", &proto.UpdateTaskRequest{DueDate: "tomorrow"}, []string{"due_date"}},
+	} {
+		if _, err := update(c.req, c.paths...); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, got %v", c.name, err)
+		}
+	}
+
+	// без маски — прежнее поведение: description и labels перезаписываются
+	if _, err := ts.UpdateTask(ctx, &proto.UpdateTaskRequest{TaskId: resp.TaskId, Title: "Full"}); err != nil {
+		t.Fatalf("full update failed: %v", err)
+	}
+	got, _ := ts.GetTask(ctx, &proto.GetTaskRequest{TaskId: resp.TaskId})
+	if got.Task.Title != "Full" || got.Task.Description != "" || len(got.Task.Labels) != 0 || got.Task.Status != "todo" {
+		t.Fatalf("expected full overwrite without a mask, got %v", got.Task)
+	}
+}
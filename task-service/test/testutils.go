@@ -2,33 +2,67 @@ package test
 
 import (
 	"context"
+	"net"
 	"testing"
 	"time"
 
+	"shared/cursor"
+	"shared/rbac"
 	"task-service/handler"
 	"task-service/model"
+	"task-service/proto"
 	"task-service/repository"
 	"task-service/security"
+	"task-service/workflow"
 
 	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-// setupTestServer создаёт тестовый gRPC сервер с in-memory SQLite
-func setupTestServer(t *testing.T) *handler.TaskServer {
+// setupTestServer создаёт тестовый gRPC сервер с in-memory SQLite и AuthInterceptor, как в main,
+// и возвращает клиент к нему: права проверяет только интерцептор
+func setupTestServer(t *testing.T) proto.TaskServiceClient {
+	return newClient(t, newTaskServer(t))
+}
+
+// newTaskServer создаёт TaskServer с in-memory SQLite и ролями по умолчанию
+func newTaskServer(t *testing.T) *handler.TaskServer {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("failed to open test db: %v", err)
 	}
-	if err := db.AutoMigrate(&model.Task{}); err != nil {
+	if err := db.AutoMigrate(&model.Task{}, &model.Project{}, &model.ProjectMember{}, &model.Column{}, &model.Comment{}, &model.CommentMention{}, &model.CommentRevision{}, &model.Activity{}, &model.TaskDependency{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 	repo := repository.NewTaskRepository(db)
 	jwtService := security.NewJWTService("testsecret")
 	rateLimiter := handler.NewRateLimiter(10 * time.Millisecond)
-	return &handler.TaskServer{Repo: repo, JwtService: jwtService, RateLimiter: rateLimiter}
+	policy := rbac.NewPolicy(rbac.DefaultRoles())
+	return &handler.TaskServer{Repo: repo, JwtService: jwtService, Policy: policy, Workflow: workflow.Default(), Cursors: cursor.NewCodec([]byte("testsecret")), RateLimiter: rateLimiter}
+}
+
+// newClient поднимает ts на in-memory соединении и возвращает клиент к нему
+func newClient(t *testing.T, ts *handler.TaskServer) proto.TaskServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(ts.AuthInterceptor()))
+	proto.RegisterTaskServiceServer(srv, ts)
+	go srv.Serve(lis)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial test server: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+	})
+	return proto.NewTaskServiceClient(conn)
 }
 
 // makeJWT генерирует JWT для тестов
@@ -41,8 +75,8 @@ func makeJWT(t *testing.T, secret, userID, role string) string {
 	return tokStr
 }
 
-// ctxWithJWT возвращает context с JWT в metadata
+// ctxWithJWT возвращает context с JWT в исходящей metadata
 func ctxWithJWT(token string) context.Context {
 	md := metadata.New(map[string]string{"authorization": "Bearer " + token})
-	return metadata.NewIncomingContext(context.Background(), md)
+	return metadata.NewOutgoingContext(context.Background(), md)
 }
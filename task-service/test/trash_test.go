@@ -0,0 +1,212 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"task-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// viewTitles возвращает названия задач проекта в представлении view
+func viewTitles(t *testing.T, ts proto.TaskServiceClient, ctx context.Context, projectID, view string) []string {
+	list, err := ts.ListTasks(ctx, &proto.ListTasksRequest{ProjectId: projectID, View: view, Sort: "title", PageSize: 50})
+	if err != nil {
+		t.Fatalf("list %q tasks failed: %v", view, err)
+	}
+	var titles []string
+	for _, task := range list.Tasks {
+		titles = append(titles, task.Title)
+	}
+	return titles
+}
+
+func TestTrash_DeleteAndRestore(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	editor := ctxWithJWT(makeJWT(t, "testsecret", editorID, "user"))
+	stray := ctxWithJWT(makeJWT(t, "testsecret", strayID, "user"))
+	projectID := newBoard(t, ts, owner)
+
+	a, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "A", ProjectId: projectID})
+	b, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "B", ProjectId: projectID})
+	if _, err := ts.AddDependency(owner, &proto.AddDependencyRequest{TaskId: b.TaskId, BlockerId: a.TaskId}); err != nil {
+		t.Fatalf("add dependency failed: %v", err)
+	}
+
+	if _, err := ts.DeleteTask(owner, &proto.DeleteTaskRequest{TaskId: a.TaskId}); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := ts.GetTask(owner, &proto.GetTaskRequest{TaskId: a.TaskId}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for a deleted task, got %v", err)
+	}
+	if got := viewTitles(t, ts, owner, projectID, ""); len(got) != 1 || got[0] != "B" {
+		t.Errorf("expected only B in the default view, got %v", got)
+	}
+	trash, err := ts.ListTasks(owner, &proto.ListTasksRequest{ProjectId: projectID, View: "trash"})
+	if err != nil || len(trash.Tasks) != 1 || trash.Tasks[0].Title != "A" || trash.Tasks[0].DeletedAt == "" {
+		t.Fatalf("expected A in trash with deleted_at, got %v, %v", trash, err)
+	}
+	if got, _ := ts.ListTasks(stray, &proto.ListTasksRequest{View: "trash"}); len(got.GetTasks()) != 0 {
+		t.Errorf("trash must only show visible tasks, got %v", got.Tasks)
+	}
+	deps, _ := ts.ListDependencies(owner, &proto.ListDependenciesRequest{TaskId: b.TaskId})
+	if len(deps.BlockedBy) != 0 {
+		t.Errorf("deleted blocker must not be listed, got %v", deps.BlockedBy)
+	}
+
+	// восстанавливать могут те же, кто может удалить
+	if _, err := ts.RestoreTask(editor, &proto.RestoreTaskRequest{TaskId: a.TaskId}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for editor, got %v", err)
+	}
+	if _, err := ts.RestoreTask(stray, &proto.RestoreTaskRequest{TaskId: a.TaskId}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for stray user, got %v", err)
+	}
+	restored, err := ts.RestoreTask(owner, &proto.RestoreTaskRequest{TaskId: a.TaskId})
+	if err != nil || restored.Task.Title != "A" || restored.Task.DeletedAt != "" {
+		t.Fatalf("restore failed: %v, %v", restored, err)
+	}
+	if got := viewTitles(t, ts, owner, projectID, ""); len(got) != 2 {
+		t.Errorf("expected A and B after restore, got %v", got)
+	}
+	deps, _ = ts.ListDependencies(owner, &proto.ListDependenciesRequest{TaskId: b.TaskId})
+	if len(deps.BlockedBy) != 1 || deps.BlockedBy[0].Id != a.TaskId {
+		t.Errorf("expected dependency back after restore, got %v", deps.BlockedBy)
+	}
+	if _, err := ts.RestoreTask(owner, &proto.RestoreTaskRequest{TaskId: a.TaskId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition restoring an active task, got %v", err)
+	}
+	if _, err := ts.ListTasks(owner, &proto.ListTasksRequest{View: "deleted"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for unknown view, got %v", err)
+	}
+}
+
+func TestTrash_RestoreIntoFullColumn(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	projectID := newBoard(t, ts, owner)
+	doing, _ := ts.CreateColumn(owner, &proto.CreateColumnRequest{ProjectId: projectID, Name: "Doing", WipLimit: 1})
+
+	a, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "A", ProjectId: projectID})
+	ts.DeleteTask(owner, &proto.DeleteTaskRequest{TaskId: a.TaskId})
+	if _, err := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "B", ProjectId: projectID}); err != nil {
+		t.Fatalf("create task failed: %v", err)
+	}
+
+	if _, err := ts.RestoreTask(owner, &proto.RestoreTaskRequest{TaskId: a.TaskId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition restoring into a full column, got %v", err)
+	}
+	if got := columnTitles(t, ts, owner, doing.Column.Id); len(got) != 1 || got[0] != "B" {
+		t.Errorf("expected only B in the column, got %v", got)
+	}
+	trash, _ := ts.ListTasks(owner, &proto.ListTasksRequest{ProjectId: projectID, View: "trash"})
+	if len(trash.GetTasks()) != 1 || trash.Tasks[0].Title != "A" {
+		t.Errorf("expected A to stay in trash, got %v", trash.GetTasks())
+	}
+}
+
+func TestArchive(t *testing.T) {
+	ts := setupTestServer(t)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	editor := ctxWithJWT(makeJWT(t, "testsecret", editorID, "user"))
+	viewer := ctxWithJWT(makeJWT(t, "testsecret", viewerID, "user"))
+	projectID := newBoard(t, ts, owner)
+	ts.CreateColumn(owner, &proto.CreateColumnRequest{ProjectId: projectID, Name: "Todo"})
+	doing, _ := ts.CreateColumn(owner, &proto.CreateColumnRequest{ProjectId: projectID, Name: "Doing", WipLimit: 1})
+
+	a, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "A", ProjectId: projectID})
+	b, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "B", ProjectId: projectID})
+	if _, err := ts.MoveTask(editor, &proto.MoveTaskRequest{TaskId: a.TaskId, ColumnId: doing.Column.Id}); err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+
+	if _, err := ts.ArchiveTask(viewer, &proto.ArchiveTaskRequest{TaskId: a.TaskId}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for viewer, got %v", err)
+	}
+	archived, err := ts.ArchiveTask(editor, &proto.ArchiveTaskRequest{TaskId: a.TaskId})
+	if err != nil || archived.Task.ArchivedAt == "" || archived.Task.Version != 3 {
+		t.Fatalf("archive failed: %v, %v", archived, err)
+	}
+	if _, err := ts.ArchiveTask(editor, &proto.ArchiveTaskRequest{TaskId: a.TaskId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition archiving twice, got %v", err)
+	}
+	if got := viewTitles(t, ts, owner, projectID, ""); len(got) != 1 || got[0] != "B" {
+		t.Errorf("expected archived task hidden by default, got %v", got)
+	}
+	if got := viewTitles(t, ts, owner, projectID, "archived"); len(got) != 1 || got[0] != "A" {
+		t.Errorf("expected A in archive, got %v", got)
+	}
+	if got, err := ts.GetTask(viewer, &proto.GetTaskRequest{TaskId: a.TaskId}); err != nil || got.Task.ArchivedAt == "" {
+		t.Errorf("archived task must stay readable, got %v, %v", got, err)
+	}
+
+	// архивная задача не занимает место в WIP-лимите
+	if _, err := ts.MoveTask(editor, &proto.MoveTaskRequest{TaskId: b.TaskId, ColumnId: doing.Column.Id}); err != nil {
+		t.Errorf("expected archived task not to count towards WIP limit, got %v", err)
+	}
+
+	// колонка заполнена до WIP-лимита: вернуть A на доску нельзя
+	if _, err := ts.UnarchiveTask(editor, &proto.UnarchiveTaskRequest{TaskId: a.TaskId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition unarchiving into a full column, got %v", err)
+	}
+	if got := viewTitles(t, ts, owner, projectID, "archived"); len(got) != 1 || got[0] != "A" {
+		t.Errorf("expected A to stay archived, got %v", got)
+	}
+	ts.UpdateColumn(owner, &proto.UpdateColumnRequest{ColumnId: doing.Column.Id, Name: "Doing", WipLimit: 2})
+	unarchived, err := ts.UnarchiveTask(editor, &proto.UnarchiveTaskRequest{TaskId: a.TaskId})
+	if err != nil || unarchived.Task.ArchivedAt != "" {
+		t.Fatalf("unarchive failed: %v, %v", unarchived, err)
+	}
+	// место A в колонке занято B — A встаёт в конец
+	if got := columnTitles(t, ts, owner, doing.Column.Id); len(got) != 2 || got[0] != "B" || got[1] != "A" {
+		t.Errorf("expected unarchived A after B, got %v", got)
+	}
+	if _, err := ts.UnarchiveTask(editor, &proto.UnarchiveTaskRequest{TaskId: a.TaskId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition unarchiving an active task, got %v", err)
+	}
+
+	// удалённая из архива задача восстанавливается обратно в архив
+	ts.ArchiveTask(editor, &proto.ArchiveTaskRequest{TaskId: a.TaskId})
+	ts.DeleteTask(owner, &proto.DeleteTaskRequest{TaskId: a.TaskId})
+	if got := viewTitles(t, ts, owner, projectID, "archived"); len(got) != 0 {
+		t.Errorf("deleted task must leave the archive view, got %v", got)
+	}
+	ts.RestoreTask(owner, &proto.RestoreTaskRequest{TaskId: a.TaskId})
+	if got := viewTitles(t, ts, owner, projectID, "archived"); len(got) != 1 || got[0] != "A" {
+		t.Errorf("expected A back in archive after restore, got %v", got)
+	}
+}
+
+func TestTrash_Purge(t *testing.T) {
+	server := newTaskServer(t)
+	ts := newClient(t, server)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	projectID := newBoard(t, ts, owner)
+
+	parent, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Parent", ProjectId: projectID})
+	child, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Child", ParentId: parent.TaskId})
+	kept, _ := ts.CreateTask(owner, &proto.CreateTaskRequest{Title: "Kept", ProjectId: projectID})
+	ts.AddDependency(owner, &proto.AddDependencyRequest{TaskId: kept.TaskId, BlockerId: child.TaskId})
+	// удалённая подзадача отвязывается от родителя, удалённого после неё
+	ts.DeleteTask(owner, &proto.DeleteTaskRequest{TaskId: child.TaskId})
+	ts.DeleteTask(owner, &proto.DeleteTaskRequest{TaskId: parent.TaskId})
+
+	if n, err := server.Repo.PurgeDeletedTasks(time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Fatalf("expected nothing purged before retention, got %d, %v", n, err)
+	}
+	if n, err := server.Repo.PurgeDeletedTasks(time.Now().Add(time.Minute)); err != nil || n != 2 {
+		t.Fatalf("expected 2 tasks purged, got %d, %v", n, err)
+	}
+	if _, err := ts.RestoreTask(owner, &proto.RestoreTaskRequest{TaskId: parent.TaskId}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound restoring a purged task, got %v", err)
+	}
+	if got := viewTitles(t, ts, owner, projectID, "trash"); len(got) != 0 {
+		t.Errorf("expected empty trash, got %v", got)
+	}
+	if got := viewTitles(t, ts, owner, projectID, ""); len(got) != 1 || got[0] != "Kept" {
+		t.Errorf("expected Kept untouched, got %v", got)
+	}
+}
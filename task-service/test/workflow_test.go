@@ -0,0 +1,167 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"task-service/proto"
+	"task-service/workflow"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWorkflow_ChangeStatusTransitions(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	created, err := ts.CreateTask(ctx, &proto.CreateTaskRequest{Title: "Workflow"})
+	if err != nil {
+		t.Fatalf("create task failed: %v", err)
+	}
+	got, _ := ts.GetTask(ctx, &proto.GetTaskRequest{TaskId: created.TaskId})
+	if got.Task.Status != "todo" || got.Task.StatusCode != proto.TaskStatus_TASK_STATUS_TODO {
+		t.Fatalf("expected initial status todo, got %q/%v", got.Task.Status, got.Task.StatusCode)
+	}
+
+	if _, err := ts.ChangeStatus(ctx, &proto.ChangeStatusRequest{TaskId: created.TaskId, Status: "whatever"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for unknown status, got %v", err)
+	}
+	if _, err := ts.ChangeStatus(ctx, &proto.ChangeStatusRequest{TaskId: created.TaskId}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for missing status, got %v", err)
+	}
+	if _, err := ts.ChangeStatus(ctx, &proto.ChangeStatusRequest{TaskId: created.TaskId, Status: "done", StatusCode: proto.TaskStatus_TASK_STATUS_BACKLOG}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for mismatched status and status_code, got %v", err)
+	}
+	if _, err := ts.ChangeStatus(ctx, &proto.ChangeStatusRequest{TaskId: created.TaskId, StatusCode: proto.TaskStatus_TASK_STATUS_IN_PROGRESS}); err != nil {
+		t.Fatalf("expected todo -> in_progress by enum, got %v", err)
+	}
+	if _, err := ts.ChangeStatus(ctx, &proto.ChangeStatusRequest{TaskId: created.TaskId, Status: "backlog"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for in_progress -> backlog, got %v", err)
+	}
+	if _, err := ts.ChangeStatus(ctx, &proto.ChangeStatusRequest{TaskId: created.TaskId, Status: "done"}); err != nil {
+		t.Errorf("expected in_progress -> done, got %v", err)
+	}
+	got, _ = ts.GetTask(ctx, &proto.GetTaskRequest{TaskId: created.TaskId})
+	if got.Task.Status != "done" || got.Task.StatusCode != proto.TaskStatus_TASK_STATUS_DONE {
+		t.Errorf("expected status done, got %q/%v", got.Task.Status, got.Task.StatusCode)
+	}
+
+	if _, err := ts.ListTasks(ctx, &proto.ListTasksRequest{Status: "whatever", Page: 1, PageSize: 10}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for unknown status filter, got %v", err)
+	}
+}
+
+func TestWorkflow_UpdateTaskEnforced(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	created, _ := ts.CreateTask(ctx, &proto.CreateTaskRequest{Title: "Workflow"})
+
+	if _, err := ts.UpdateTask(ctx, &proto.UpdateTaskRequest{TaskId: created.TaskId, Title: "Renamed", Status: "backlog"}); err != nil {
+		t.Fatalf("expected todo -> backlog, got %v", err)
+	}
+	if _, err := ts.UpdateTask(ctx, &proto.UpdateTaskRequest{TaskId: created.TaskId, Title: "Renamed", Status: "done"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for backlog -> done, got %v", err)
+	}
+	// архив — не статус
+	if _, err := ts.UpdateTask(ctx, &proto.UpdateTaskRequest{TaskId: created.TaskId, Status: "archived"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for archived status, got %v", err)
+	}
+	// без status задача сохраняет текущий статус
+	if _, err := ts.UpdateTask(ctx, &proto.UpdateTaskRequest{TaskId: created.TaskId, Title: "Again"}); err != nil {
+		t.Fatalf("update without status failed: %v", err)
+	}
+	got, _ := ts.GetTask(ctx, &proto.GetTaskRequest{TaskId: created.TaskId})
+	if got.Task.Status != "backlog" || got.Task.Title != "Again" || got.Task.ArchivedAt != "" {
+		t.Errorf("expected backlog task titled Again, got %v", got.Task)
+	}
+}
+
+func TestWorkflow_PerRoleTransitions(t *testing.T) {
+	ts := newTaskServer(t)
+	ts.Workflow = &workflow.Workflow{
+		Initial:  "open",
+		Statuses: []string{"open", "review", "closed"},
+		Transitions: []workflow.Transition{
+			{From: "open", To: "review"},
+			{From: "review", To: "closed", Roles: []string{"admin"}},
+		},
+	}
+	client := newClient(t, ts)
+	user := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+	admin := ctxWithJWT(makeJWT(t, "testsecret", "admin-id", "admin"))
+
+	created, _ := client.CreateTask(user, &proto.CreateTaskRequest{Title: "Custom"})
+	got, _ := client.GetTask(user, &proto.GetTaskRequest{TaskId: created.TaskId})
+	if got.Task.Status != "open" || got.Task.StatusCode != proto.TaskStatus_TASK_STATUS_UNSPECIFIED {
+		t.Fatalf("expected custom initial status without enum, got %q/%v", got.Task.Status, got.Task.StatusCode)
+	}
+	if _, err := client.ChangeStatus(user, &proto.ChangeStatusRequest{TaskId: created.TaskId, Status: "review"}); err != nil {
+		t.Fatalf("expected open -> review, got %v", err)
+	}
+	if _, err := client.ChangeStatus(user, &proto.ChangeStatusRequest{TaskId: created.TaskId, Status: "closed"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for user closing, got %v", err)
+	}
+	if _, err := client.ChangeStatus(admin, &proto.ChangeStatusRequest{TaskId: created.TaskId, Status: "closed"}); err != nil {
+		t.Errorf("expected admin to close, got %v", err)
+	}
+}
+
+func TestWorkflow_LegacyStatus(t *testing.T) {
+	ts := newTaskServer(t)
+	client := newClient(t, ts)
+	owner := ctxWithJWT(makeJWT(t, "testsecret", ownerID, "user"))
+
+	// статус из старой версии сервиса, когда статус был произвольной строкой
+	created, _ := client.CreateTask(owner, &proto.CreateTaskRequest{Title: "Legacy"})
+	task, _ := ts.Repo.GetTaskByID(created.TaskId)
+	task.Status = "open"
+	if err := ts.Repo.UpdateTask(task); err != nil {
+		t.Fatalf("failed to set legacy status: %v", err)
+	}
+
+	if _, err := client.ChangeStatus(owner, &proto.ChangeStatusRequest{TaskId: created.TaskId, Status: "done"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for open -> done, got %v", err)
+	}
+	if _, err := client.ChangeStatus(owner, &proto.ChangeStatusRequest{TaskId: created.TaskId, Status: "todo"}); err != nil {
+		t.Fatalf("expected legacy status -> initial status, got %v", err)
+	}
+	if _, err := client.ChangeStatus(owner, &proto.ChangeStatusRequest{TaskId: created.TaskId, Status: "in_progress"}); err != nil {
+		t.Errorf("expected regular transitions after leaving the legacy status, got %v", err)
+	}
+}
+
+func TestWorkflow_Load(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	os.WriteFile(good, []byte(`{"initial":"new","statuses":["new","done"],"transitions":[{"from":"*","to":"done"}]}`), 0o600)
+	w, err := workflow.Load(good)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if err := w.CheckTransition("new", "done", "user"); err != nil {
+		t.Errorf("expected wildcard transition, got %v", err)
+	}
+	if err := w.CheckTransition("done", "new", "user"); err != workflow.ErrTransitionNotAllowed {
+		t.Errorf("expected ErrTransitionNotAllowed, got %v", err)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte(`{"initial":"new","statuses":["new"],"transitions":[{"from":"new","to":"gone"}]}`), 0o600)
+	if _, err := workflow.Load(bad); err == nil {
+		t.Error("expected error for transition to undeclared status")
+	}
+	badDone := filepath.Join(dir, "bad_done.json")
+	os.WriteFile(badDone, []byte(`{"initial":"new","statuses":["new"],"done":["closed"]}`), 0o600)
+	if _, err := workflow.Load(badDone); err == nil {
+		t.Error("expected error for undeclared done status")
+	}
+	reserved := filepath.Join(dir, "reserved.json")
+	os.WriteFile(reserved, []byte(`{"initial":"new","statuses":["new","archived"]}`), 0o600)
+	if _, err := workflow.Load(reserved); err == nil {
+		t.Error("expected error for reserved archived status")
+	}
+	if err := workflow.Default().Validate(); err != nil {
+		t.Errorf("default workflow is invalid: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package workflow описывает жизненный цикл задачи: допустимые статусы и переходы между ними.
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Встроенные статусы задачи (совпадают с enum TaskStatus в task.proto)
+const (
+	StatusBacklog    = "backlog"
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusDone       = "done"
+)
+
+// ReservedArchived — имя, которое не может быть статусом: архив задаётся ArchiveTask (archived_at), а не статусом
+const ReservedArchived = "archived"
+
+// AnyStatus в поле From перехода означает «из любого статуса»
+const AnyStatus = "*"
+
+var (
+	// ErrUnknownStatus — статуса нет в workflow
+	ErrUnknownStatus = errors.New("unknown status")
+	// ErrTransitionNotAllowed — переход между статусами запрещён (или запрещён для роли)
+	ErrTransitionNotAllowed = errors.New("status transition not allowed")
+)
+
+// Transition — разрешённый переход From → To. Roles ограничивает переход ролями пользователя;
+// пустой список — переход доступен всем, у кого есть право изменять задачу.
+type Transition struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Workflow — статусы задачи и переходы между ними. Initial — статус новой задачи,
+// Done — статусы выполненной задачи (для прогресса подзадач и блокирующих зависимостей).
+type Workflow struct {
+	Initial     string       `json:"initial"`
+	Statuses    []string     `json:"statuses"`
+	Done        []string     `json:"done"`
+	Transitions []Transition `json:"transitions"`
+}
+
+// Default — workflow по умолчанию: backlog → todo → in_progress → done
+func Default() *Workflow {
+	return &Workflow{
+		Initial:  StatusTodo,
+		Statuses: []string{StatusBacklog, StatusTodo, StatusInProgress, StatusDone},
+		Done:     []string{StatusDone},
+		Transitions: []Transition{
+			{From: StatusBacklog, To: StatusTodo},
+			{From: StatusTodo, To: StatusBacklog},
+			{From: StatusTodo, To: StatusInProgress},
+			{From: StatusTodo, To: StatusDone},
+			{From: StatusInProgress, To: StatusTodo},
+			{From: StatusInProgress, To: StatusDone},
+			{From: StatusDone, To: StatusInProgress},
+		},
+	}
+}
+
+// Load читает workflow из JSON-файла и проверяет его
+func Load(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var w Workflow
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", path, err)
+	}
+	if err := w.Validate(); err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", path, err)
+	}
+	return &w, nil
+}
+
+// Validate проверяет, что начальный статус и концы переходов объявлены в Statuses
+// и среди статусов нет зарезервированного archived
+func (w *Workflow) Validate() error {
+	if len(w.Statuses) == 0 {
+		return errors.New("no statuses defined")
+	}
+	if w.IsStatus(ReservedArchived) {
+		return fmt.Errorf("status %q is reserved: archive tasks with ArchiveTask", ReservedArchived)
+	}
+	if !w.IsStatus(w.Initial) {
+		return fmt.Errorf("initial status %q: %w", w.Initial, ErrUnknownStatus)
+	}
+	for _, s := range w.Done {
+		if !w.IsStatus(s) {
+			return fmt.Errorf("done status %q: %w", s, ErrUnknownStatus)
+		}
+	}
+	for _, t := range w.Transitions {
+		if t.From != AnyStatus && !w.IsStatus(t.From) {
+			return fmt.Errorf("transition from %q: %w", t.From, ErrUnknownStatus)
+		}
+		if !w.IsStatus(t.To) {
+			return fmt.Errorf("transition to %q: %w", t.To, ErrUnknownStatus)
+		}
+	}
+	return nil
+}
+
+// IsStatus сообщает, объявлен ли статус в workflow
+func (w *Workflow) IsStatus(status string) bool {
+	for _, s := range w.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDone сообщает, считается ли задача в статусе status выполненной
+func (w *Workflow) IsDone(status string) bool {
+	for _, s := range w.Done {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTransition проверяет переход from → to для роли role.
+// Переход в тот же статус всегда разрешён. Из статуса вне workflow (задача, созданная до его
+// введения, или статус, убранный из файла workflow) можно перейти в начальный статус.
+func (w *Workflow) CheckTransition(from, to, role string) error {
+	if !w.IsStatus(to) {
+		return ErrUnknownStatus
+	}
+	if from == to || (!w.IsStatus(from) && to == w.Initial) {
+		return nil
+	}
+	for _, t := range w.Transitions {
+		if (t.From == from || t.From == AnyStatus) && t.To == to && allowsRole(t.Roles, role) {
+			return nil
+		}
+	}
+	return ErrTransitionNotAllowed
+}
+
+func allowsRole(roles []string, role string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -3,18 +3,50 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DBUrl     string
-	JWTSecret string
-	SMTPHost  string
-	SMTPPort  string
-	SMTPUser  string
-	SMTPPass  string
-	FromEmail string
+	DBUrl        string
+	JWTSecret    string
+	JWTKeysDir   string // каталог с PEM-ключами подписи (RS256/EdDSA); если задан, JWT_SECRET не нужен
+	JWTActiveKID string // kid ключа для подписи новых токенов; по умолчанию — последний по имени файла
+	JWKSPort     string // публичный HTTP-порт для /.well-known/jwks.json
+	InternalPort string // HTTP-порт служебных эндпоинтов (/internal/...) для других сервисов; не публикуется наружу
+	JWTIssuer    string // claim iss выдаваемых токенов
+	JWTAudience  string // claim aud выдаваемых токенов
+	CursorSecret string // CURSOR_SECRET: ключ подписи page_token; пусто — случайный ключ при старте
+	AccessTTL    time.Duration
+	RefreshTTL   time.Duration
+
+	// UNCONFIRMED_LOGIN: reject (по умолчанию), restrict или allow
+	UnconfirmedLogin string
+	ConfirmationTTL  time.Duration // EMAIL_CONFIRMATION_TTL, по умолчанию 24h
+
+	// Хэширование паролей: PASSWORD_HASHER = argon2id (по умолчанию) или bcrypt
+	PasswordHasher    string
+	Argon2Memory      uint32 // KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	BcryptCost        int
+
+	SMTPHost    string
+	SMTPPort    string
+	SMTPUser    string
+	SMTPPass    string
+	FromEmail   string
+	MailBackend string // MAIL_BACKEND: smtp, file, log; по умолчанию smtp, если задан SMTP_HOST, иначе log
+	MailDir     string // каталог для MAIL_BACKEND=file
+	MailLocale  string // язык шаблонов писем (en, ru)
+	AppBaseURL  string // адрес фронтенда для ссылок в письмах
+
+	// Первый администратор: создаётся при старте, если в БД ещё нет ни одного admin
+	AdminEmail    string
+	AdminUsername string
+	AdminPassword string
 }
 
 func LoadConfig() *Config {
@@ -22,18 +54,99 @@ func LoadConfig() *Config {
 	_ = godotenv.Load()
 
 	cfg := &Config{
-		DBUrl:     os.Getenv("DB_URL"),
-		JWTSecret: os.Getenv("JWT_SECRET"),
-		SMTPHost:  os.Getenv("SMTP_HOST"),
-		SMTPPort:  os.Getenv("SMTP_PORT"),
-		SMTPUser:  os.Getenv("SMTP_USER"),
-		SMTPPass:  os.Getenv("SMTP_PASS"),
-		FromEmail: os.Getenv("FROM_EMAIL"),
+		DBUrl:        os.Getenv("DB_URL"),
+		JWTSecret:    os.Getenv("JWT_SECRET"),
+		JWTKeysDir:   os.Getenv("JWT_KEYS_DIR"),
+		JWTActiveKID: os.Getenv("JWT_ACTIVE_KID"),
+		JWKSPort:     os.Getenv("JWKS_PORT"),
+		InternalPort: os.Getenv("INTERNAL_PORT"),
+		JWTIssuer:    os.Getenv("JWT_ISSUER"),
+		JWTAudience:  os.Getenv("JWT_AUDIENCE"),
+		CursorSecret: os.Getenv("CURSOR_SECRET"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUser:     os.Getenv("SMTP_USER"),
+		SMTPPass:     os.Getenv("SMTP_PASS"),
+		FromEmail:    os.Getenv("FROM_EMAIL"),
+		MailBackend:  os.Getenv("MAIL_BACKEND"),
+		MailDir:      os.Getenv("MAIL_DIR"),
+		MailLocale:   os.Getenv("MAIL_LOCALE"),
+		AppBaseURL:   os.Getenv("APP_BASE_URL"),
+
+		AdminEmail:    os.Getenv("ADMIN_EMAIL"),
+		AdminUsername: os.Getenv("ADMIN_USERNAME"),
+		AdminPassword: os.Getenv("ADMIN_PASSWORD"),
+	}
+	cfg.AccessTTL = getDuration("ACCESS_TOKEN_TTL")
+	cfg.RefreshTTL = getDuration("REFRESH_TOKEN_TTL")
+	cfg.ConfirmationTTL = getDuration("EMAIL_CONFIRMATION_TTL")
+	cfg.UnconfirmedLogin = os.Getenv("UNCONFIRMED_LOGIN")
+	switch cfg.UnconfirmedLogin {
+	case "":
+		cfg.UnconfirmedLogin = "reject"
+	case "reject", "restrict", "allow":
+	default:
+		log.Fatalf("UNCONFIRMED_LOGIN: неизвестная политика %q", cfg.UnconfirmedLogin)
+	}
+	cfg.PasswordHasher = os.Getenv("PASSWORD_HASHER")
+	if cfg.PasswordHasher == "" {
+		cfg.PasswordHasher = "argon2id"
+	}
+	cfg.Argon2Memory = uint32(getInt("ARGON2_MEMORY", 64*1024))
+	cfg.Argon2Iterations = uint32(getInt("ARGON2_ITERATIONS", 3))
+	cfg.Argon2Parallelism = uint8(getInt("ARGON2_PARALLELISM", 2))
+	cfg.BcryptCost = getInt("BCRYPT_COST", 12)
+	if cfg.MailBackend == "" {
+		cfg.MailBackend = "log"
+		if cfg.SMTPHost != "" {
+			cfg.MailBackend = "smtp"
+		}
+	}
+	if cfg.MailDir == "" {
+		cfg.MailDir = "mail-out"
+	}
+	if cfg.AppBaseURL == "" {
+		cfg.AppBaseURL = "http://localhost:8080"
+	}
+	if cfg.JWKSPort == "" {
+		cfg.JWKSPort = "8081"
+	}
+	if cfg.InternalPort == "" {
+		cfg.InternalPort = "8082"
+	}
+	if cfg.InternalPort == cfg.JWKSPort {
+		log.Fatal("INTERNAL_PORT должен отличаться от JWKS_PORT")
 	}
 
-	if cfg.DBUrl == "" || cfg.JWTSecret == "" {
-		log.Fatal("DB_URL и JWT_SECRET должны быть заданы в .env или переменных окружения")
+	if cfg.DBUrl == "" || (cfg.JWTSecret == "" && cfg.JWTKeysDir == "") {
+		log.Fatal("DB_URL и JWT_SECRET (или JWT_KEYS_DIR) должны быть заданы в .env или переменных окружения")
 	}
 
 	return cfg
 }
+
+// getDuration читает длительность вида "15m"/"720h"; пустое значение — 0 (значение по умолчанию)
+func getDuration(key string) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("%s: неверная длительность %q", key, v)
+	}
+	return d
+}
+
+// getInt читает положительное целое; пустое значение — def
+func getInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Fatalf("%s: неверное значение %q", key, v)
+	}
+	return n
+}
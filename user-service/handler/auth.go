@@ -2,16 +2,17 @@ package handler
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
+	"log"
+	"shared/rbac"
 	"strings"
 	"time"
-	"user-service/config"
 	"user-service/model"
 	pb "user-service/proto"
+	"user-service/repository"
 
 	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
 )
 
 func (s *UserServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
@@ -29,30 +30,34 @@ func (s *UserServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb
 		return nil, errors.New("email already exists")
 	}
 
-	hash := sha256.Sum256([]byte(req.Password))
-	hashedPassword := hex.EncodeToString(hash[:])
-	role := req.Role
-	if role == "" {
-		role = "user"
+	hashedPassword, err := s.Passwords.Hash(req.Password)
+	if err != nil {
+		return nil, err
 	}
 	token, err := GenerateEmailToken()
 	if err != nil {
 		return nil, err
 	}
 	user := &model.User{
-		ID:                     uuid.New(),
-		Username:               req.Username,
-		Email:                  req.Email,
-		Password:               hashedPassword,
-		Role:                   role,
-		IsEmailConfirmed:       false,
-		EmailConfirmationToken: token,
-	}
-	if err := s.Repo.CreateUser(user); err != nil {
+		ID:                         uuid.New(),
+		Username:                   req.Username,
+		Email:                      req.Email,
+		Password:                   hashedPassword,
+		Role:                       rbac.RoleUser, // роль меняется только через SetUserRole
+		IsEmailConfirmed:           false,
+		EmailConfirmationToken:     token,
+		EmailConfirmationExpiresAt: s.confirmationExpiresAt(),
+	}
+	// пользователь и письмо подтверждения сохраняются вместе: аккаунта без письма в outbox не бывает
+	err = s.Repo.Transaction(func(repo *repository.UserRepository) error {
+		if err := repo.CreateUser(user); err != nil {
+			return err
+		}
+		return s.sendConfirmationEmail(repo, user.Email, token)
+	})
+	if err != nil {
 		return nil, err
 	}
-	cfg := config.LoadConfig()
-	_ = SendEmailFunc(cfg, user.Email, token) // Ошибку можно логировать
 	return &pb.RegisterResponse{UserId: user.ID.String()}, nil
 }
 
@@ -68,15 +73,43 @@ func (s *UserServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Login
 		return nil, errors.New("user not found")
 	}
 
-	hash := sha256.Sum256([]byte(req.Password))
-	if user.Password != hex.EncodeToString(hash[:]) {
+	ok, rehash, err := s.Passwords.Verify(req.Password, user.Password)
+	if err != nil || !ok {
 		return nil, errors.New("invalid password")
 	}
-	token, err := s.JwtService.GenerateToken(user.ID.String())
+	if rehash {
+		// прозрачный переход со старого хэша (SHA-256 или прежние параметры) на активный алгоритм
+		if hashed, err := s.Passwords.Hash(req.Password); err == nil {
+			if err := s.Repo.UpdatePasswordHash(user, hashed); err != nil {
+				log.Printf("failed to rehash password for user %s: %v", user.ID, err)
+			}
+		}
+	}
+	if !user.IsEmailConfirmed {
+		switch s.UnconfirmedLogin {
+		case UnconfirmedLoginAllow:
+		case UnconfirmedLoginRestrict:
+			token, err := s.JwtService.GenerateUnconfirmedToken(user.ID.String(), user.Role)
+			if err != nil {
+				return nil, err
+			}
+			return &pb.LoginResponse{Token: token, ExpiresIn: int64(s.JwtService.AccessTTL().Seconds())}, nil
+		default:
+			return nil, GRPCError("email not confirmed", codes.FailedPrecondition)
+		}
+	}
+	token, refresh, rt, err := s.issueTokens(user, uuid.New())
 	if err != nil {
 		return nil, err
 	}
-	return &pb.LoginResponse{Token: token}, nil
+	if err := s.Repo.CreateRefreshToken(rt); err != nil {
+		return nil, err
+	}
+	return &pb.LoginResponse{
+		Token:        token,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(s.JwtService.AccessTTL().Seconds()),
+	}, nil
 }
 
 func (s *UserServer) ConfirmEmail(ctx context.Context, req *pb.ConfirmEmailRequest) (*pb.ConfirmEmailResponse, error) {
@@ -90,12 +123,50 @@ func (s *UserServer) ConfirmEmail(ctx context.Context, req *pb.ConfirmEmailReque
 	if user.IsEmailConfirmed {
 		return &pb.ConfirmEmailResponse{Success: false, Message: "email already confirmed"}, nil
 	}
+	// 0 — токен выдан до появления срока действия (миграция 7): считается истёкшим, нужен новый
+	if user.EmailConfirmationExpiresAt < time.Now().Unix() {
+		return &pb.ConfirmEmailResponse{Success: false, Message: "token expired"}, nil
+	}
 	if err := s.Repo.ConfirmUserEmail(user); err != nil {
 		return &pb.ConfirmEmailResponse{Success: false, Message: "failed to confirm email"}, err
 	}
 	return &pb.ConfirmEmailResponse{Success: true, Message: "email confirmed"}, nil
 }
 
+// resendConfirmationMessage — ответ ResendConfirmation в любом случае: по нему нельзя узнать,
+// зарегистрирован ли email и подтверждён ли он
+const resendConfirmationMessage = "if the email is registered and not confirmed, a confirmation email has been sent"
+
+// ResendConfirmation выпускает новый токен подтверждения email и отправляет письмо повторно.
+// Для неизвестного или уже подтверждённого email письмо не отправляется, а ответ тот же.
+func (s *UserServer) ResendConfirmation(ctx context.Context, req *pb.ResendConfirmationRequest) (*pb.ResendConfirmationResponse, error) {
+	if !ResendLimiter.Allow(strings.ToLower(req.Email)) {
+		return nil, GRPCError("too many confirmation requests, try later", codes.ResourceExhausted)
+	}
+	sent := &pb.ResendConfirmationResponse{Success: true, Message: resendConfirmationMessage}
+	user, err := s.Repo.GetUserByEmail(req.Email)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if user == nil || user.IsEmailConfirmed {
+		return sent, nil
+	}
+	token, err := GenerateEmailToken()
+	if err != nil {
+		return &pb.ResendConfirmationResponse{Success: false, Message: "failed to generate token"}, err
+	}
+	err = s.Repo.Transaction(func(repo *repository.UserRepository) error {
+		if err := repo.SetEmailConfirmationToken(user, token, s.confirmationExpiresAt()); err != nil {
+			return err
+		}
+		return s.sendConfirmationEmail(repo, user.Email, token)
+	})
+	if err != nil {
+		return &pb.ResendConfirmationResponse{Success: false, Message: "failed to send email"}, err
+	}
+	return sent, nil
+}
+
 func (s *UserServer) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
 	user, err := s.Repo.GetUserByEmail(req.Email)
 	if err != nil || user == nil {
@@ -106,11 +177,15 @@ func (s *UserServer) RequestPasswordReset(ctx context.Context, req *pb.RequestPa
 		return &pb.RequestPasswordResetResponse{Success: false, Message: "failed to generate token"}, err
 	}
 	expiresAt := time.Now().Add(30 * time.Minute).Unix()
-	if err := s.Repo.SetPasswordResetToken(user.Email, token, expiresAt); err != nil {
-		return &pb.RequestPasswordResetResponse{Success: false, Message: "failed to save token"}, err
+	err = s.Repo.Transaction(func(repo *repository.UserRepository) error {
+		if err := repo.SetPasswordResetToken(user.Email, token, expiresAt); err != nil {
+			return err
+		}
+		return s.sendPasswordResetEmail(repo, user.Email, token)
+	})
+	if err != nil {
+		return &pb.RequestPasswordResetResponse{Success: false, Message: "failed to send email"}, err
 	}
-	cfg := config.LoadConfig()
-	_ = SendPasswordResetEmail(cfg, user.Email, token)
 	return &pb.RequestPasswordResetResponse{Success: true, Message: "reset email sent"}, nil
 }
 
@@ -125,9 +200,18 @@ func (s *UserServer) ResetPassword(ctx context.Context, req *pb.ResetPasswordReq
 	if len(req.NewPassword) < 6 {
 		return &pb.ResetPasswordResponse{Success: false, Message: "password too short"}, nil
 	}
-	hash := sha256.Sum256([]byte(req.NewPassword))
-	hashedPassword := hex.EncodeToString(hash[:])
-	if err := s.Repo.ResetPassword(user, hashedPassword); err != nil {
+	hashedPassword, err := s.Passwords.Hash(req.NewPassword)
+	if err != nil {
+		return &pb.ResetPasswordResponse{Success: false, Message: "failed to reset password"}, err
+	}
+	// новый пароль и отзыв всех сессий — вместе: украденная сессия не переживает сброс пароля
+	err = s.Repo.Transaction(func(repo *repository.UserRepository) error {
+		if err := repo.ResetPassword(user, hashedPassword); err != nil {
+			return err
+		}
+		return s.revokeSessions(repo, user.ID)
+	})
+	if err != nil {
 		return &pb.ResetPasswordResponse{Success: false, Message: "failed to reset password"}, err
 	}
 	return &pb.ResetPasswordResponse{Success: true, Message: "password reset successful"}, nil
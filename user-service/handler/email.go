@@ -3,25 +3,25 @@ package handler
 import (
 	"crypto/rand"
 	"encoding/base64"
-	"fmt"
-	"net/smtp"
-	"user-service/config"
+	"user-service/repository"
 )
 
-var SendEmailFunc = SendConfirmationEmail
-
-func SendConfirmationEmail(cfg *config.Config, to, token string) error {
-	msg := fmt.Sprintf("Subject: Email Confirmation\n\nPlease confirm your email by clicking the link: http://localhost:8080/confirm?email=%s&token=%s", to, token)
-	auth := smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPass, cfg.SMTPHost)
-	addr := fmt.Sprintf("%s:%s", cfg.SMTPHost, cfg.SMTPPort)
-	return smtp.SendMail(addr, auth, cfg.FromEmail, []string{to}, []byte(msg))
+// sendConfirmationEmail ставит письмо подтверждения email в outbox через repo (обычно — в транзакции с токеном)
+func (s *UserServer) sendConfirmationEmail(repo *repository.UserRepository, to, token string) error {
+	msg, err := s.Templates.Confirmation(to, token)
+	if err != nil {
+		return err
+	}
+	return enqueueEmail(repo, msg)
 }
 
-func SendPasswordResetEmail(cfg *config.Config, to, token string) error {
-	msg := fmt.Sprintf("Subject: Password Reset\n\nTo reset your password, click the link: http://localhost:8080/reset?email=%s&token=%s", to, token)
-	auth := smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPass, cfg.SMTPHost)
-	addr := fmt.Sprintf("%s:%s", cfg.SMTPHost, cfg.SMTPPort)
-	return smtp.SendMail(addr, auth, cfg.FromEmail, []string{to}, []byte(msg))
+// sendPasswordResetEmail ставит письмо сброса пароля в outbox через repo
+func (s *UserServer) sendPasswordResetEmail(repo *repository.UserRepository, to, token string) error {
+	msg, err := s.Templates.PasswordReset(to, token)
+	if err != nil {
+		return err
+	}
+	return enqueueEmail(repo, msg)
 }
 
 func GenerateEmailToken() (string, error) {
@@ -0,0 +1,10 @@
+package handler
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func GRPCError(msg string, code codes.Code) error {
+	return status.Error(code, msg)
+}
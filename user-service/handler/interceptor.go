@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"shared/rbac"
+	pb "user-service/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// PublicMethods — RPC, доступные без access token
+var PublicMethods = map[string]bool{
+	pb.UserService_Register_FullMethodName:             true,
+	pb.UserService_Login_FullMethodName:                true,
+	pb.UserService_RefreshToken_FullMethodName:         true,
+	pb.UserService_ConfirmEmail_FullMethodName:         true,
+	pb.UserService_ResendConfirmation_FullMethodName:   true,
+	pb.UserService_RequestPasswordReset_FullMethodName: true,
+	pb.UserService_ResetPassword_FullMethodName:        true,
+}
+
+// MethodRules — права, необходимые для вызова RPC. Непубличный метод без правила запрещён всем.
+var MethodRules = map[string]rbac.Rule{
+	pb.UserService_GetProfile_FullMethodName:         {Any: rbac.PermUserReadAny, Own: rbac.PermUserReadOwn, Owner: isSelf},
+	pb.UserService_UpdateUser_FullMethodName:         {Any: rbac.PermUserUpdateAny, Own: rbac.PermUserUpdateOwn, Owner: isSelf},
+	pb.UserService_DeleteUser_FullMethodName:         {Any: rbac.PermUserDelete},
+	pb.UserService_ListUsers_FullMethodName:          {Any: rbac.PermUserList},
+	pb.UserService_Logout_FullMethodName:             {},
+	pb.UserService_RevokeUserSessions_FullMethodName: {Any: rbac.PermUserManage},
+	pb.UserService_SetUserRole_FullMethodName:        {Any: rbac.PermUserManage},
+	pb.UserService_ListRoles_FullMethodName:          {Any: rbac.PermUserManage},
+	pb.UserService_UpsertRole_FullMethodName:         {Any: rbac.PermUserManage},
+	pb.UserService_DeleteRole_FullMethodName:         {Any: rbac.PermUserManage},
+}
+
+type authClaimsKey struct{}
+
+// AuthInterceptor проверяет bearer-токен для всех RPC, кроме PublicMethods, и права роли
+// по MethodRules, после чего кладёт проверенные claims в контекст обработчика
+func (s *UserServer) AuthInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if PublicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		auth, err := s.GetAuthClaims(ctx)
+		if err != nil {
+			return nil, err
+		}
+		rule, ok := MethodRules[info.FullMethod]
+		if !ok {
+			return nil, GRPCError("forbidden", codes.PermissionDenied)
+		}
+		allowed, err := s.Policy.Check(ctx, rule, auth.UserID.String(), auth.Role, req)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, GRPCError("forbidden", codes.PermissionDenied)
+		}
+		return handler(context.WithValue(ctx, authClaimsKey{}, auth), req)
+	}
+}
+
+// authClaims возвращает claims, проверенные интерцептором; при прямом вызове обработчика
+// (без интерцептора) токен проверяется здесь же
+func (s *UserServer) authClaims(ctx context.Context) (*AuthClaims, error) {
+	if auth, ok := ctx.Value(authClaimsKey{}).(*AuthClaims); ok {
+		return auth, nil
+	}
+	return s.GetAuthClaims(ctx)
+}
+
+// isSelf — владелец профиля: запрос касается самого вызывающего пользователя
+func isSelf(ctx context.Context, userID string, req interface{}) (bool, error) {
+	r, ok := req.(interface{ GetUserId() string })
+	return ok && r.GetUserId() == userID, nil
+}
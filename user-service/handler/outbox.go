@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"time"
+	"user-service/mail"
+	"user-service/model"
+	"user-service/repository"
+)
+
+// MaxEmailAttempts — после стольких неудачных попыток письмо остаётся в outbox с last_error
+const MaxEmailAttempts = 10
+
+// emailLease — на это время воркер захватывает письмо: другие воркеры не возьмут его, пока аренда не истечёт
+const emailLease = 5 * time.Minute
+
+// enqueueEmail кладёт письмо в outbox через repo; доставка — в DeliverOutbox
+func enqueueEmail(repo *repository.UserRepository, msg mail.Message) error {
+	return repo.EnqueueEmail(&model.OutboxEmail{
+		Recipient: msg.To,
+		Subject:   msg.Subject,
+		TextBody:  msg.Text,
+		HTMLBody:  msg.HTML,
+	})
+}
+
+// DeliverOutbox захватывает письма, для которых подошло время попытки, отправляет их и возвращает число
+// доставленных. Неудачная попытка откладывается с экспоненциальной задержкой (1 мин, 2 мин, ... до 1 часа).
+func (s *UserServer) DeliverOutbox(ctx context.Context) (int, error) {
+	now := time.Now()
+	emails, err := s.Repo.ClaimPendingEmails(now, now.Add(emailLease), MaxEmailAttempts, 50)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, e := range emails {
+		err := s.Mailer.Send(ctx, mail.Message{To: e.Recipient, Subject: e.Subject, Text: e.TextBody, HTML: e.HTMLBody})
+		if err != nil {
+			attempts := e.Attempts + 1
+			log.Printf("email %s to %s failed (attempt %d): %v", e.ID, e.Recipient, attempts, err)
+			if err := s.Repo.MarkEmailFailed(e.ID, attempts, err.Error(), now.Add(retryDelay(attempts))); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		if err := s.Repo.MarkEmailSent(e.ID, time.Now()); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func retryDelay(attempts int) time.Duration {
+	d := time.Minute << (attempts - 1)
+	if d > time.Hour || d <= 0 {
+		return time.Hour
+	}
+	return d
+}
+
+// StartOutbox периодически отправляет письма из outbox
+func (s *UserServer) StartOutbox(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := s.DeliverOutbox(context.Background()); err != nil {
+				log.Printf("outbox delivery failed: %v", err)
+			}
+		}
+	}()
+}
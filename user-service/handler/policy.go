@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"shared/rbac"
+	"sort"
+	"user-service/model"
+	pb "user-service/proto"
+
+	"google.golang.org/grpc/codes"
+)
+
+var roleNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+
+// LoadPolicy читает роли и права из БД в s.Policy. Если таблица roles пуста
+// (миграция не выполнялась, тестовая БД), она заполняется rbac.DefaultRoles.
+func (s *UserServer) LoadPolicy() error {
+	roles, err := s.Repo.ListRoles()
+	if err != nil {
+		return err
+	}
+	if len(roles) == 0 {
+		for name, perms := range rbac.DefaultRoles() {
+			if err := s.Repo.SaveRole(name, "", perms); err != nil {
+				return err
+			}
+		}
+		if roles, err = s.Repo.ListRoles(); err != nil {
+			return err
+		}
+	}
+	table := make(map[string][]string, len(roles))
+	for _, r := range roles {
+		table[r.Name] = r.PermissionNames()
+	}
+	if s.Policy == nil {
+		s.Policy = rbac.NewPolicy(table)
+	} else {
+		s.Policy.Set(table)
+	}
+	return nil
+}
+
+// ListRoles (user:manage) возвращает роли с правами и список всех известных прав
+func (s *UserServer) ListRoles(ctx context.Context, req *pb.ListRolesRequest) (*pb.ListRolesResponse, error) {
+	roles, err := s.Repo.ListRoles()
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	resp := &pb.ListRolesResponse{Permissions: rbac.AllPermissions}
+	for i := range roles {
+		resp.Roles = append(resp.Roles, toProtoRole(&roles[i]))
+	}
+	return resp, nil
+}
+
+// UpsertRole (user:manage) создаёт роль или заменяет её описание и права
+func (s *UserServer) UpsertRole(ctx context.Context, req *pb.UpsertRoleRequest) (*pb.UpsertRoleResponse, error) {
+	if req.Role == nil || !roleNameRegex.MatchString(req.Role.Name) {
+		return nil, GRPCError("invalid role name", codes.InvalidArgument)
+	}
+	perms := make([]string, 0, len(req.Role.Permissions))
+	seen := map[string]bool{}
+	for _, perm := range req.Role.Permissions {
+		if !rbac.IsKnownPermission(perm) {
+			return nil, GRPCError("unknown permission: "+perm, codes.InvalidArgument)
+		}
+		if !seen[perm] {
+			seen[perm] = true
+			perms = append(perms, perm)
+		}
+	}
+	// иначе управлять ролями станет некому
+	if req.Role.Name == rbac.RoleAdmin && !seen[rbac.PermUserManage] {
+		return nil, GRPCError("admin role must keep "+rbac.PermUserManage, codes.InvalidArgument)
+	}
+	sort.Strings(perms)
+	if err := s.Repo.SaveRole(req.Role.Name, req.Role.Description, perms); err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if err := s.LoadPolicy(); err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.UpsertRoleResponse{Role: &pb.RoleInfo{Name: req.Role.Name, Description: req.Role.Description, Permissions: perms}}, nil
+}
+
+// DeleteRole (user:manage) удаляет роль; встроенные роли и роли, назначенные пользователям, удалить нельзя
+func (s *UserServer) DeleteRole(ctx context.Context, req *pb.DeleteRoleRequest) (*pb.DeleteRoleResponse, error) {
+	if req.Name == rbac.RoleUser || req.Name == rbac.RoleAdmin {
+		return &pb.DeleteRoleResponse{Success: false}, GRPCError("builtin role cannot be deleted", codes.FailedPrecondition)
+	}
+	role, err := s.Repo.GetRole(req.Name)
+	if err != nil {
+		return &pb.DeleteRoleResponse{Success: false}, GRPCError("internal error", codes.Internal)
+	}
+	if role == nil {
+		return &pb.DeleteRoleResponse{Success: false}, GRPCError("role not found", codes.NotFound)
+	}
+	count, err := s.Repo.CountUsersByRole(req.Name)
+	if err != nil {
+		return &pb.DeleteRoleResponse{Success: false}, GRPCError("internal error", codes.Internal)
+	}
+	if count > 0 {
+		return &pb.DeleteRoleResponse{Success: false}, GRPCError("role is assigned to users", codes.FailedPrecondition)
+	}
+	if err := s.Repo.DeleteRole(req.Name); err != nil {
+		return &pb.DeleteRoleResponse{Success: false}, GRPCError("internal error", codes.Internal)
+	}
+	if err := s.LoadPolicy(); err != nil {
+		return &pb.DeleteRoleResponse{Success: false}, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.DeleteRoleResponse{Success: true}, nil
+}
+
+// PolicyHandler отдаёт таблицу «роль → права» по HTTP для task-service
+func (s *UserServer) PolicyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"roles": s.Policy.Roles()})
+	})
+}
+
+func toProtoRole(r *model.Role) *pb.RoleInfo {
+	perms := r.PermissionNames()
+	sort.Strings(perms)
+	return &pb.RoleInfo{Name: r.Name, Description: r.Description, Permissions: perms}
+}
@@ -41,5 +41,6 @@ func (r *RateLimiter) Allow(key string) bool {
 	return true
 }
 
-var RegLimiter = NewRateLimiter(5, 60)    // 5 регистраций в минуту на email
-var LoginLimiter = NewRateLimiter(10, 60) // 10 логинов в минуту на email
+var RegLimiter = NewRateLimiter(5, 60)     // 5 регистраций в минуту на email
+var LoginLimiter = NewRateLimiter(10, 60)  // 10 логинов в минуту на email
+var ResendLimiter = NewRateLimiter(3, 600) // 3 повторных письма подтверждения за 10 минут на email
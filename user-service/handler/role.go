@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"shared/rbac"
+	"user-service/model"
+	pb "user-service/proto"
+	"user-service/repository"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+// SetUserRole (user:manage) меняет роль пользователя; смена пишется в журнал role_audit_log
+func (s *UserServer) SetUserRole(ctx context.Context, req *pb.SetUserRoleRequest) (*pb.SetUserRoleResponse, error) {
+	auth, err := s.authClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !s.Policy.HasRole(req.Role) {
+		return nil, GRPCError("invalid role", codes.InvalidArgument)
+	}
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return nil, GRPCError("invalid user_id", codes.InvalidArgument)
+	}
+	user, err := s.Repo.GetUserByID(req.UserId)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if user == nil {
+		return nil, GRPCError("user not found", codes.NotFound)
+	}
+	if err := s.changeRole(s.Repo, user, req.Role, auth.UserID); err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.SetUserRoleResponse{UserId: user.ID.String(), Role: user.Role}, nil
+}
+
+// changeRole меняет роль с записью в журнал и отзывает сессии пользователя,
+// чтобы токены со старой ролью перестали действовать. Обе записи — в одной транзакции repo.
+func (s *UserServer) changeRole(repo *repository.UserRepository, user *model.User, role string, actorID uuid.UUID) error {
+	if user.Role == role {
+		return nil
+	}
+	return repo.Transaction(func(repo *repository.UserRepository) error {
+		if err := repo.SetUserRole(user, role, actorID); err != nil {
+			return err
+		}
+		return s.revokeSessions(repo, user.ID)
+	})
+}
+
+// BootstrapAdmin назначает роль admin пользователю с email, создавая его (с подтверждённым email),
+// если он ещё не зарегистрирован. Используется при первом запуске (ADMIN_EMAIL) и командой create-admin.
+func (s *UserServer) BootstrapAdmin(email, username, password string) (*model.User, error) {
+	user, err := s.Repo.GetUserByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		if !EmailRegex.MatchString(email) {
+			return nil, errors.New("invalid email")
+		}
+		if len(password) < 6 {
+			return nil, errors.New("password must be at least 6 characters")
+		}
+		hashed, err := s.Passwords.Hash(password)
+		if err != nil {
+			return nil, err
+		}
+		if username == "" {
+			username = "admin"
+		}
+		user = &model.User{
+			ID:               uuid.New(),
+			Username:         username,
+			Email:            email,
+			Password:         hashed,
+			Role:             rbac.RoleUser,
+			IsEmailConfirmed: true,
+		}
+		if err := s.Repo.CreateUser(user); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.changeRole(s.Repo, user, rbac.RoleAdmin, uuid.Nil); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
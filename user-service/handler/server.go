@@ -1,13 +1,44 @@
 package handler
 
 import (
+	"shared/cursor"
+	"shared/rbac"
+	"time"
+	"user-service/mail"
 	pb "user-service/proto"
 	"user-service/repository"
 	"user-service/security"
+	"user-service/security/password"
 )
 
 type UserServer struct {
 	pb.UnimplementedUserServiceServer
 	Repo       *repository.UserRepository
 	JwtService *security.JWTService
+	Policy     *rbac.Policy // роли и права; заполняется LoadPolicy
+	Passwords  *password.Service
+	Mailer     mail.Mailer
+	Templates  *mail.Renderer
+	Cursors    *cursor.Codec // подпись page_token в ListUsers
+
+	UnconfirmedLogin string        // политика входа с неподтверждённым email: reject (по умолчанию), restrict, allow
+	ConfirmationTTL  time.Duration // срок действия токена подтверждения email; 0 — DefaultConfirmationTTL
+}
+
+// Политики входа пользователя с неподтверждённым email
+const (
+	UnconfirmedLoginReject   = "reject"   // вход запрещён
+	UnconfirmedLoginRestrict = "restrict" // выдаётся только access token с email_verified=false, без refresh token
+	UnconfirmedLoginAllow    = "allow"    // вход без ограничений
+)
+
+// DefaultConfirmationTTL — срок действия токена подтверждения email по умолчанию
+const DefaultConfirmationTTL = 24 * time.Hour
+
+func (s *UserServer) confirmationExpiresAt() int64 {
+	ttl := s.ConfirmationTTL
+	if ttl == 0 {
+		ttl = DefaultConfirmationTTL
+	}
+	return time.Now().Add(ttl).Unix()
 }
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+	pb "user-service/proto"
+	"user-service/repository"
+	"user-service/security"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+// Logout отзывает текущий access token (по jti) и, если передан, семью refresh token
+func (s *UserServer) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	auth, err := s.authClaims(ctx)
+	if err != nil {
+		return &pb.LogoutResponse{Success: false}, err
+	}
+	if auth.JTI != "" {
+		expiresAt := auth.ExpiresAt
+		if expiresAt.IsZero() {
+			expiresAt = time.Now().Add(s.JwtService.AccessTTL())
+		}
+		if err := s.Repo.RevokeToken(auth.JTI, auth.UserID, expiresAt); err != nil {
+			return &pb.LogoutResponse{Success: false}, GRPCError("internal error", codes.Internal)
+		}
+	}
+	if req.RefreshToken != "" {
+		rt, err := s.Repo.GetRefreshTokenByHash(security.HashRefreshToken(req.RefreshToken))
+		if err != nil {
+			return &pb.LogoutResponse{Success: false}, GRPCError("internal error", codes.Internal)
+		}
+		if rt != nil && rt.UserID == auth.UserID {
+			if err := s.Repo.RevokeRefreshTokenFamily(rt.FamilyID); err != nil {
+				return &pb.LogoutResponse{Success: false}, GRPCError("internal error", codes.Internal)
+			}
+		}
+	}
+	return &pb.LogoutResponse{Success: true}, nil
+}
+
+// RevokeUserSessions (user:manage) отзывает все токены пользователя, выданные до текущего момента
+func (s *UserServer) RevokeUserSessions(ctx context.Context, req *pb.RevokeUserSessionsRequest) (*pb.RevokeUserSessionsResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return &pb.RevokeUserSessionsResponse{Success: false}, GRPCError("invalid user_id", codes.InvalidArgument)
+	}
+	if err := s.revokeSessions(s.Repo, userID); err != nil {
+		return &pb.RevokeUserSessionsResponse{Success: false}, GRPCError("internal error", codes.Internal)
+	}
+	return &pb.RevokeUserSessionsResponse{Success: true}, nil
+}
+
+// revokeSessions отзывает через repo семьи refresh token пользователя и его access token,
+// выданные до текущего момента (запись хранится, пока они не истекут)
+func (s *UserServer) revokeSessions(repo *repository.UserRepository, userID uuid.UUID) error {
+	now := time.Now()
+	return repo.RevokeUserSessions(userID, now, now.Add(s.JwtService.AccessTTL()))
+}
+
+// revocationsDoc — формат /internal/revocations, который читают task-service и api-gateway
+type revocationsDoc struct {
+	Tokens []revokedTokenDoc   `json:"tokens"`
+	Users  []revokedSessionDoc `json:"users"`
+}
+
+type revokedTokenDoc struct {
+	JTI       string `json:"jti"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+type revokedSessionDoc struct {
+	UserID          string `json:"user_id"`
+	RevokedBefore   int64  `json:"revoked_before"`    // Unix-секунды, для старых версий сервисов
+	RevokedBeforeMs int64  `json:"revoked_before_ms"` // Unix-миллисекунды
+	ExpiresAt       int64  `json:"expires_at"`
+}
+
+// RevocationsHandler отдаёт актуальный список отзывов по HTTP для локальных кэшей других сервисов
+func (s *UserServer) RevocationsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens, sessions, err := s.Repo.ListActiveRevocations(time.Now())
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		doc := revocationsDoc{Tokens: []revokedTokenDoc{}, Users: []revokedSessionDoc{}}
+		for _, t := range tokens {
+			doc.Tokens = append(doc.Tokens, revokedTokenDoc{JTI: t.JTI, ExpiresAt: t.ExpiresAt.Unix()})
+		}
+		for _, u := range sessions {
+			doc.Users = append(doc.Users, revokedSessionDoc{
+				UserID:          u.UserID.String(),
+				RevokedBefore:   u.RevokedBefore.Unix(),
+				RevokedBeforeMs: u.RevokedBefore.UnixMilli(),
+				ExpiresAt:       u.ExpiresAt.Unix(),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(doc)
+	})
+}
+
+// StartRevocationCleanup периодически удаляет истёкшие записи об отзыве токенов
+func (s *UserServer) StartRevocationCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := s.Repo.DeleteExpiredRevocations(time.Now()); err != nil {
+				log.Printf("revocation cleanup failed: %v", err)
+			}
+		}
+	}()
+}
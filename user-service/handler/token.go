@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"time"
+	"user-service/model"
+	pb "user-service/proto"
+	"user-service/security"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+// issueTokens выдаёт access token и новый refresh token в семье familyID
+func (s *UserServer) issueTokens(user *model.User, familyID uuid.UUID) (access, refresh string, rt *model.RefreshToken, err error) {
+	access, err = s.JwtService.GenerateToken(user.ID.String(), user.Role)
+	if err != nil {
+		return "", "", nil, err
+	}
+	refresh, err = security.GenerateRefreshToken()
+	if err != nil {
+		return "", "", nil, err
+	}
+	rt = &model.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: security.HashRefreshToken(refresh),
+		ExpiresAt: time.Now().Add(s.JwtService.RefreshTTL()),
+	}
+	return access, refresh, rt, nil
+}
+
+// RefreshToken обменивает refresh token на новую пару токенов (ротация).
+// Повторное использование уже обменянного токена отзывает всю семью токенов.
+func (s *UserServer) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, GRPCError("refresh token is required", codes.InvalidArgument)
+	}
+	current, err := s.Repo.GetRefreshTokenByHash(security.HashRefreshToken(req.RefreshToken))
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if current == nil {
+		return nil, GRPCError("invalid refresh token", codes.Unauthenticated)
+	}
+	if current.RevokedAt != nil {
+		_ = s.Repo.RevokeRefreshTokenFamily(current.FamilyID)
+		return nil, GRPCError("refresh token reuse detected", codes.Unauthenticated)
+	}
+	if current.ExpiresAt.Before(time.Now()) {
+		return nil, GRPCError("refresh token expired", codes.Unauthenticated)
+	}
+	user, err := s.Repo.GetUserByID(current.UserID.String())
+	if err != nil || user == nil {
+		return nil, GRPCError("user not found", codes.Unauthenticated)
+	}
+	access, refresh, next, err := s.issueTokens(user, current.FamilyID)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	rotated, err := s.Repo.RotateRefreshToken(current, next)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if !rotated {
+		// токен успели обменять параллельно — считаем это повторным использованием
+		_ = s.Repo.RevokeRefreshTokenFamily(current.FamilyID)
+		return nil, GRPCError("refresh token reuse detected", codes.Unauthenticated)
+	}
+	return &pb.RefreshTokenResponse{
+		Token:        access,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(s.JwtService.AccessTTL().Seconds()),
+	}, nil
+}
@@ -3,7 +3,12 @@ package handler
 import (
 	"context"
 	"errors"
+	"shared/rbac"
 	pb "user-service/proto"
+	"user-service/repository"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
 )
 
 func (s *UserServer) GetProfile(ctx context.Context, req *pb.GetProfileRequest) (*pb.GetProfileResponse, error) {
@@ -23,9 +28,16 @@ func (s *UserServer) GetProfile(ctx context.Context, req *pb.GetProfileRequest)
 }
 
 func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	auth, err := s.authClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
 	if err := ValidateUpdateInput(req); err != nil {
 		return nil, err
 	}
+	if req.Role != "" && !s.Policy.HasRole(req.Role) {
+		return nil, errors.New("invalid role")
+	}
 	user, err := s.Repo.GetUserByID(req.UserId)
 	if err != nil {
 		return nil, err
@@ -33,10 +45,46 @@ func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 	if user == nil {
 		return nil, errors.New("user not found")
 	}
+	// менять роль можно только с правом user:manage; пустая роль — без изменений
+	if req.Role != "" && req.Role != user.Role && !s.Policy.Allows(auth.Role, rbac.PermUserManage) {
+		return nil, GRPCError("only admin can change role", codes.PermissionDenied)
+	}
+	// новый email не подтверждён: нужен свободный адрес, новый токен и письмо подтверждения
+	emailChanged := req.Email != user.Email
+	var token string
+	if emailChanged {
+		existing, err := s.Repo.GetUserByEmail(req.Email)
+		if err != nil {
+			return nil, GRPCError("internal error", codes.Internal)
+		}
+		if existing != nil {
+			return nil, GRPCError("email already exists", codes.AlreadyExists)
+		}
+		if token, err = GenerateEmailToken(); err != nil {
+			return nil, GRPCError("internal error", codes.Internal)
+		}
+		user.Email = req.Email
+		user.IsEmailConfirmed = false
+		user.EmailConfirmationToken = token
+		user.EmailConfirmationExpiresAt = s.confirmationExpiresAt()
+	}
 	user.Username = req.Username
-	user.Email = req.Email
-	user.Role = req.Role
-	if err := s.Repo.UpdateUser(user); err != nil {
+	// профиль, письмо подтверждения и смена роли сохраняются вместе
+	err = s.Repo.Transaction(func(repo *repository.UserRepository) error {
+		if err := repo.UpdateUser(user); err != nil {
+			return err
+		}
+		if emailChanged {
+			if err := s.sendConfirmationEmail(repo, user.Email, token); err != nil {
+				return err
+			}
+		}
+		if req.Role != "" {
+			return s.changeRole(repo, user, req.Role, auth.UserID)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &pb.UpdateUserResponse{UserId: user.ID.String()}, nil
@@ -50,21 +98,60 @@ func (s *UserServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest)
 	return &pb.DeleteUserResponse{Success: true}, nil
 }
 
+const (
+	defaultUsersPageSize = 20
+	maxUsersPageSize     = 100
+)
+
+// usersPageToken — содержимое page_token ListUsers (подписывается s.Cursors)
+type usersPageToken struct {
+	After uuid.UUID `json:"a"` // последний пользователь предыдущей страницы
+}
+
+// ListUsers возвращает пользователей в порядке id. Следующая страница — по next_page_token;
+// page — устаревшая страница по смещению.
 func (s *UserServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
-	offset := (int(req.Page) - 1) * int(req.PageSize)
-	limit := int(req.PageSize)
-	users, err := s.Repo.ListUsers(offset, limit)
+	limit := defaultUsersPageSize
+	if req.PageSize > 0 {
+		limit = min(int(req.PageSize), maxUsersPageSize)
+	}
+	var after uuid.UUID
+	offset := 0
+	if req.PageToken != "" {
+		var token usersPageToken
+		if err := s.Cursors.Decode(req.PageToken, &token); err != nil || token.After == uuid.Nil {
+			return nil, GRPCError("invalid page_token", codes.InvalidArgument)
+		}
+		after = token.After
+	} else if req.Page > 1 {
+		offset = int(req.Page-1) * limit
+	}
+	// на один больше, чтобы узнать, есть ли следующая страница
+	users, err := s.Repo.ListUsers(after, offset, limit+1)
 	if err != nil {
-		return nil, err
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	resp := &pb.ListUsersResponse{}
+	if len(users) > limit {
+		users = users[:limit]
+		if resp.NextPageToken, err = s.Cursors.Encode(usersPageToken{After: users[limit-1].ID}); err != nil {
+			return nil, GRPCError("internal error", codes.Internal)
+		}
+	}
+	if req.IncludeTotal {
+		total, err := s.Repo.CountUsers()
+		if err != nil {
+			return nil, GRPCError("internal error", codes.Internal)
+		}
+		resp.Total = int32(total)
 	}
-	var userInfos []*pb.UserInfo
 	for _, u := range users {
-		userInfos = append(userInfos, &pb.UserInfo{
+		resp.Users = append(resp.Users, &pb.UserInfo{
 			UserId:   u.ID.String(),
 			Username: u.Username,
 			Email:    u.Email,
 			Role:     u.Role,
 		})
 	}
-	return &pb.ListUsersResponse{Users: userInfos, Total: int32(len(userInfos))}, nil
+	return resp, nil
 }
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"shared/revocation"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// AuthClaims — проверенные данные из bearer-токена запроса
+type AuthClaims struct {
+	UserID    uuid.UUID
+	Role      string
+	JTI       string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// GetAuthClaims проверяет JWT из metadata (подпись, iss/aud, срок, отзыв) и возвращает его claims
+func (s *UserServer) GetAuthClaims(ctx context.Context) (*AuthClaims, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md["authorization"]) == 0 {
+		return nil, GRPCError("unauthorized", codes.Unauthenticated)
+	}
+	tokenStr := strings.TrimPrefix(md["authorization"][0], "Bearer ")
+	token, err := s.JwtService.ValidateToken(tokenStr)
+	if err != nil || !token.Valid {
+		return nil, GRPCError("invalid token", codes.Unauthenticated)
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	uid, _ := claims["user_id"].(string)
+	if uid == "" {
+		uid, _ = claims["sub"].(string)
+	}
+	userID, err := uuid.Parse(uid)
+	if err != nil {
+		return nil, GRPCError("invalid token", codes.Unauthenticated)
+	}
+	auth := &AuthClaims{UserID: userID}
+	auth.Role, _ = claims["role"].(string)
+	auth.JTI, _ = claims["jti"].(string)
+	auth.IssuedAt = revocation.IssuedAt(claims)
+	if exp, _ := claims.GetExpirationTime(); exp != nil {
+		auth.ExpiresAt = exp.Time
+	}
+	revoked, err := s.Repo.IsTokenRevoked(auth.JTI, auth.UserID, auth.IssuedAt)
+	if err != nil {
+		return nil, GRPCError("internal error", codes.Internal)
+	}
+	if revoked {
+		return nil, GRPCError("token revoked", codes.Unauthenticated)
+	}
+	return auth, nil
+}
@@ -0,0 +1,16 @@
+package mail
+
+import "context"
+
+// Message — письмо с текстовой и HTML-версией
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer — способ доставки писем (SMTP, файлы/лог, память для тестов)
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryMailer складывает письма в память; используется в тестах
+type MemoryMailer struct {
+	mu   sync.Mutex
+	sent []Message
+	Err  error // если задана, Send возвращает её и письмо не сохраняется
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// Messages возвращает копию отправленных писем
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.sent...)
+}
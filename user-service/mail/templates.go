@@ -0,0 +1,82 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"net/url"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// DefaultLocale используется, если шаблонов для выбранной локали нет
+const DefaultLocale = "en"
+
+// Renderer собирает письма из шаблонов templates/<locale>/<name>.{txt,html}.
+// В .txt определяются блоки "subject" и "body", .html — HTML-версия письма.
+type Renderer struct {
+	baseURL string
+	locale  string
+}
+
+// NewRenderer создаёт рендерер; baseURL — адрес фронтенда для ссылок в письмах
+func NewRenderer(baseURL, locale string) (*Renderer, error) {
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	if _, err := fs.Stat(templateFS, "templates/"+locale); err != nil {
+		return nil, fmt.Errorf("mail: no templates for locale %q", locale)
+	}
+	return &Renderer{baseURL: strings.TrimRight(baseURL, "/"), locale: locale}, nil
+}
+
+// Confirmation — письмо со ссылкой подтверждения email
+func (r *Renderer) Confirmation(to, token string) (Message, error) {
+	return r.render("confirmation", to, r.link("/confirm", to, token))
+}
+
+// PasswordReset — письмо со ссылкой сброса пароля
+func (r *Renderer) PasswordReset(to, token string) (Message, error) {
+	return r.render("password_reset", to, r.link("/reset", to, token))
+}
+
+func (r *Renderer) link(path, email, token string) string {
+	q := url.Values{"email": {email}, "token": {token}}
+	return r.baseURL + path + "?" + q.Encode()
+}
+
+func (r *Renderer) render(name, to, link string) (Message, error) {
+	data := struct{ Email, Link string }{Email: to, Link: link}
+	dir := "templates/" + r.locale + "/"
+
+	text, err := texttemplate.ParseFS(templateFS, dir+name+".txt")
+	if err != nil {
+		return Message{}, err
+	}
+	var subject, body bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := text.ExecuteTemplate(&body, "body", data); err != nil {
+		return Message{}, err
+	}
+	html, err := htmltemplate.ParseFS(templateFS, dir+name+".html")
+	if err != nil {
+		return Message{}, err
+	}
+	var htmlBody bytes.Buffer
+	if err := html.Execute(&htmlBody, data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+		HTML:    htmlBody.String(),
+	}, nil
+}
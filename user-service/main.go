@@ -1,14 +1,22 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"time"
 
+	"shared/cursor"
+	"shared/rbac"
 	"user-service/config"
 	"user-service/handler"
+	"user-service/mail"
 	pb "user-service/proto"
 	"user-service/repository"
 	"user-service/security"
+	"user-service/security/password"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -27,19 +35,152 @@ func main() {
 	// Миграции теперь выполняются отдельно через golang-migrate
 
 	repo := repository.NewUserRepository(db)
-	jwtService := security.NewJWTService(cfg.JWTSecret)
+	jwtService := security.NewJWTService(cfg.JWTSecret).WithIssuer(cfg.JWTIssuer, cfg.JWTAudience).WithTTL(cfg.AccessTTL, cfg.RefreshTTL)
+	httpMux := http.NewServeMux()
+	if cfg.JWTKeysDir != "" {
+		keys, err := security.LoadSigningKeys(cfg.JWTKeysDir)
+		if err != nil {
+			log.Fatalf("failed to load signing keys: %v", err)
+		}
+		jwtService, err = security.NewAsymmetricJWTService(keys, cfg.JWTActiveKID)
+		if err != nil {
+			log.Fatalf("failed to init jwt service: %v", err)
+		}
+		jwtService.WithIssuer(cfg.JWTIssuer, cfg.JWTAudience).WithTTL(cfg.AccessTTL, cfg.RefreshTTL)
 
-	lis, err := net.Listen("tcp", ":50051")
+		// Публичные ключи для task-service и api-gateway
+		httpMux.Handle("/.well-known/jwks.json", jwtService.JWKSHandler())
+	}
+
+	templates, err := mail.NewRenderer(cfg.AppBaseURL, cfg.MailLocale)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		log.Fatalf("failed to load mail templates: %v", err)
 	}
-	s := grpc.NewServer()
-	pb.RegisterUserServiceServer(s, &handler.UserServer{
+
+	userServer := &handler.UserServer{
 		Repo:       repo,
 		JwtService: jwtService,
-	})
+		Passwords:  password.NewService(newPasswordHasher(cfg)),
+		Mailer:     newMailer(cfg),
+		Templates:  templates,
+		Cursors:    cursor.NewCodec(cursor.Secret(cfg.CursorSecret)),
+
+		UnconfirmedLogin: cfg.UnconfirmedLogin,
+		ConfirmationTTL:  cfg.ConfirmationTTL,
+	}
+
+	if err := userServer.LoadPolicy(); err != nil {
+		log.Fatalf("failed to load roles: %v", err)
+	}
+
+	// user-service create-admin -email ... -password ... — назначить администратора и выйти
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		createAdmin(userServer, os.Args[2:])
+		return
+	}
+	bootstrapAdmin(userServer, repo, cfg)
+
+	userServer.StartRevocationCleanup(10 * time.Minute)
+	userServer.StartOutbox(5 * time.Second)
+
+	go func() {
+		log.Printf("http endpoint (jwks) started on :%s", cfg.JWKSPort)
+		if err := http.ListenAndServe(":"+cfg.JWKSPort, httpMux); err != nil {
+			log.Fatalf("failed to serve http: %v", err)
+		}
+	}()
+
+	// Служебный порт: доступен только другим сервисам внутри сети, наружу не публикуется
+	internalMux := http.NewServeMux()
+	// Список отозванных токенов для локальных кэшей task-service и api-gateway
+	internalMux.Handle("/internal/revocations", userServer.RevocationsHandler())
+	// Роли и права для интерцептора task-service
+	internalMux.Handle("/internal/policy", userServer.PolicyHandler())
+	go func() {
+		log.Printf("internal http endpoint (revocations, policy) started on :%s", cfg.InternalPort)
+		if err := http.ListenAndServe(":"+cfg.InternalPort, internalMux); err != nil {
+			log.Fatalf("failed to serve internal http: %v", err)
+		}
+	}()
+
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+	s := grpc.NewServer(grpc.UnaryInterceptor(userServer.AuthInterceptor()))
+	pb.RegisterUserServiceServer(s, userServer)
 	log.Println("user-service started on :50051")
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
 }
+
+// newPasswordHasher выбирает алгоритм хэширования новых паролей по конфигу
+func newPasswordHasher(cfg *config.Config) password.Hasher {
+	switch cfg.PasswordHasher {
+	case "argon2id":
+		return password.NewArgon2id(password.Argon2idParams{
+			Memory:      cfg.Argon2Memory,
+			Iterations:  cfg.Argon2Iterations,
+			Parallelism: cfg.Argon2Parallelism,
+			SaltLength:  password.DefaultArgon2idParams.SaltLength,
+			KeyLength:   password.DefaultArgon2idParams.KeyLength,
+		})
+	case "bcrypt":
+		return password.NewBcrypt(cfg.BcryptCost)
+	default:
+		log.Fatalf("PASSWORD_HASHER: неизвестный алгоритм %q", cfg.PasswordHasher)
+		return nil
+	}
+}
+
+// newMailer выбирает способ доставки писем по конфигу
+func newMailer(cfg *config.Config) mail.Mailer {
+	switch cfg.MailBackend {
+	case "smtp":
+		return &mail.SMTPMailer{Host: cfg.SMTPHost, Port: cfg.SMTPPort, User: cfg.SMTPUser, Pass: cfg.SMTPPass, From: cfg.FromEmail}
+	case "file":
+		return &mail.FileMailer{Dir: cfg.MailDir, From: cfg.FromEmail}
+	case "log":
+		return mail.LogMailer{}
+	default:
+		log.Fatalf("MAIL_BACKEND: неизвестный способ доставки %q", cfg.MailBackend)
+		return nil
+	}
+}
+
+// createAdmin — CLI-команда create-admin
+func createAdmin(userServer *handler.UserServer, args []string) {
+	fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	email := fs.String("email", "", "email администратора")
+	username := fs.String("username", "admin", "имя пользователя (если пользователь создаётся)")
+	pass := fs.String("password", "", "пароль (если пользователь создаётся)")
+	_ = fs.Parse(args)
+	if *email == "" {
+		log.Fatal("create-admin: -email обязателен")
+	}
+	user, err := userServer.BootstrapAdmin(*email, *username, *pass)
+	if err != nil {
+		log.Fatalf("create-admin: %v", err)
+	}
+	log.Printf("user %s (%s) is admin", user.Email, user.ID)
+}
+
+// bootstrapAdmin создаёт первого администратора из ADMIN_EMAIL/ADMIN_PASSWORD, если администраторов ещё нет
+func bootstrapAdmin(userServer *handler.UserServer, repo *repository.UserRepository, cfg *config.Config) {
+	if cfg.AdminEmail == "" {
+		return
+	}
+	count, err := repo.CountUsersByRole(rbac.RoleAdmin)
+	if err != nil {
+		log.Fatalf("failed to count admins: %v", err)
+	}
+	if count > 0 {
+		return
+	}
+	user, err := userServer.BootstrapAdmin(cfg.AdminEmail, cfg.AdminUsername, cfg.AdminPassword)
+	if err != nil {
+		log.Fatalf("failed to bootstrap admin: %v", err)
+	}
+	log.Printf("bootstrap admin %s (%s) created", user.Email, user.ID)
+}
@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
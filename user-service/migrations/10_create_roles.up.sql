@@ -0,0 +1,38 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(32) PRIMARY KEY,
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(32) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(64) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+-- встроенные роли; при пустой таблице user-service заполняет их сам при старте
+INSERT INTO roles (name, description) VALUES
+    ('user', 'Обычный пользователь'),
+    ('admin', 'Администратор')
+ON CONFLICT (name) DO NOTHING;
+INSERT INTO role_permissions (role, permission) VALUES
+    ('user', 'user:read:own'),
+    ('user', 'user:update:own'),
+    ('user', 'task:create'),
+    ('user', 'task:read'),
+    ('user', 'task:update:own'),
+    ('user', 'task:delete:own'),
+    ('admin', 'user:read:own'),
+    ('admin', 'user:read:any'),
+    ('admin', 'user:update:own'),
+    ('admin', 'user:update:any'),
+    ('admin', 'user:delete'),
+    ('admin', 'user:list'),
+    ('admin', 'user:manage'),
+    ('admin', 'task:create'),
+    ('admin', 'task:read'),
+    ('admin', 'task:update:own'),
+    ('admin', 'task:update:any'),
+    ('admin', 'task:delete:own'),
+    ('admin', 'task:delete:any')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+-- +migrate Down
+DELETE FROM role_permissions WHERE permission LIKE 'project:%';
@@ -0,0 +1,12 @@
+-- +migrate Up
+INSERT INTO role_permissions (role, permission)
+SELECT r.name, p.permission
+FROM roles r
+JOIN (VALUES
+    ('user', 'project:create'),
+    ('user', 'project:manage:own'),
+    ('admin', 'project:create'),
+    ('admin', 'project:manage:own'),
+    ('admin', 'project:manage:any')
+) AS p(role, permission) ON p.role = r.name
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE IF EXISTS session_revocations;
+DROP TABLE IF EXISTS revoked_tokens;
@@ -0,0 +1,2 @@
+-- +migrate Down
+ALTER TABLE users DROP COLUMN email_confirmation_expires_at;
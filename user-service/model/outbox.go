@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OutboxEmail — письмо в очереди на отправку. Сохраняется в той же БД,
+// что и пользователь, и доставляется фоновым воркером с повторными попытками.
+type OutboxEmail struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Recipient     string
+	Subject       string
+	TextBody      string
+	HTMLBody      string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time `gorm:"index"`
+	SentAt        *time.Time
+	CreatedAt     time.Time
+}
+
+func (OutboxEmail) TableName() string {
+	return "email_outbox"
+}
+
+func (e *OutboxEmail) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
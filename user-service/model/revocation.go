@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokedToken — отозванный access token (по jti).
+// Запись нужна только до истечения самого токена и затем удаляется.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;index"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// SessionRevocation — все токены пользователя, выданные не позже RevokedBefore, недействительны
+type SessionRevocation struct {
+	UserID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	RevokedBefore time.Time
+	ExpiresAt     time.Time `gorm:"index"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Role — роль пользователя и её права (таблица roles). Роли и права редактируются
+// через RPC UpsertRole/DeleteRole; проверка прав — rbac.Policy (пакет shared/rbac).
+type Role struct {
+	Name        string `gorm:"primaryKey"`
+	Description string
+	Permissions []RolePermission `gorm:"foreignKey:Role;references:Name"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// RolePermission — право permission роли role (например, "task:delete:any")
+type RolePermission struct {
+	Role       string `gorm:"primaryKey"`
+	Permission string `gorm:"primaryKey"`
+}
+
+// PermissionNames возвращает список прав роли
+func (r *Role) PermissionNames() []string {
+	perms := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		perms = append(perms, p.Permission)
+	}
+	return perms
+}
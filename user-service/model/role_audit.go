@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoleAudit — запись журнала смены ролей. ActorID = uuid.Nil, если роль
+// назначена при первичной настройке (ADMIN_EMAIL или команда create-admin).
+type RoleAudit struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;index"`
+	ActorID   uuid.UUID `gorm:"type:uuid"`
+	OldRole   string
+	NewRole   string
+	CreatedAt time.Time
+}
+
+func (RoleAudit) TableName() string {
+	return "role_audit_log"
+}
+
+func (a *RoleAudit) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
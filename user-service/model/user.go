@@ -6,15 +6,16 @@ import (
 )
 
 type User struct {
-	ID                     uuid.UUID `gorm:"type:uuid;primaryKey"`
-	Username               string
-	Email                  string `gorm:"uniqueIndex"`
-	Password               string
-	Role                   string // роль пользователя (например, "user", "admin")
-	IsEmailConfirmed       bool
-	EmailConfirmationToken string
-	PasswordResetToken     string
-	PasswordResetExpiresAt int64 // unix timestamp
+	ID                         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Username                   string
+	Email                      string `gorm:"uniqueIndex"`
+	Password                   string
+	Role                       string // роль пользователя (например, "user", "admin")
+	IsEmailConfirmed           bool
+	EmailConfirmationToken     string
+	EmailConfirmationExpiresAt int64 // unix timestamp
+	PasswordResetToken         string
+	PasswordResetExpiresAt     int64 // unix timestamp
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+	"user-service/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EnqueueEmail сохраняет письмо в outbox для фоновой отправки
+func (r *UserRepository) EnqueueEmail(email *model.OutboxEmail) error {
+	if email.NextAttemptAt.IsZero() {
+		email.NextAttemptAt = time.Now()
+	}
+	return r.db.Create(email).Error
+}
+
+// ClaimPendingEmails захватывает до limit неотправленных писем, для которых подошло время попытки:
+// их next_attempt_at переносится на leaseUntil, и до этого момента другие воркеры их не выберут
+func (r *UserRepository) ClaimPendingEmails(now, leaseUntil time.Time, maxAttempts, limit int) ([]model.OutboxEmail, error) {
+	var claimed []model.OutboxEmail
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("sent_at IS NULL AND attempts < ? AND next_attempt_at <= ?", maxAttempts, now).
+			Order("next_attempt_at").Limit(limit)
+		if tx.Dialector.Name() == "postgres" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		var emails []model.OutboxEmail
+		if err := query.Find(&emails).Error; err != nil {
+			return err
+		}
+		for _, e := range emails {
+			// условие повторяется: без блокировки строк (SQLite) письмо мог захватить другой воркер
+			res := tx.Model(&model.OutboxEmail{}).
+				Where("id = ? AND sent_at IS NULL AND next_attempt_at <= ?", e.ID, now).
+				Update("next_attempt_at", leaseUntil)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 1 {
+				e.NextAttemptAt = leaseUntil
+				claimed = append(claimed, e)
+			}
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+// MarkEmailSent отмечает письмо как доставленное
+func (r *UserRepository) MarkEmailSent(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&model.OutboxEmail{}).Where("id = ?", id).Update("sent_at", at).Error
+}
+
+// MarkEmailFailed фиксирует неудачную попытку и время следующей
+func (r *UserRepository) MarkEmailFailed(id uuid.UUID, attempts int, lastErr string, next time.Time) error {
+	return r.db.Model(&model.OutboxEmail{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        attempts,
+		"last_error":      lastErr,
+		"next_attempt_at": next,
+	}).Error
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"time"
+	"user-service/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (r *UserRepository) CreateRefreshToken(token *model.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *UserRepository) GetRefreshTokenByHash(hash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken помечает старый токен использованным и сохраняет новый в одной транзакции.
+// Возвращает false, если старый токен уже был отозван (гонка или повторное использование).
+func (r *UserRepository) RotateRefreshToken(old *model.RefreshToken, next *model.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", old.ID).
+			Update("revoked_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		rotated = true
+		return tx.Create(next).Error
+	})
+	return rotated, err
+}
+
+// RevokeRefreshTokenFamily отзывает все ещё активные токены семьи
+func (r *UserRepository) RevokeRefreshTokenFamily(familyID uuid.UUID) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
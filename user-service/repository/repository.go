@@ -15,6 +15,13 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// Transaction выполняет fn в одной транзакции; repo внутри fn работает через неё
+func (r *UserRepository) Transaction(fn func(repo *UserRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&UserRepository{db: tx})
+	})
+}
+
 func (r *UserRepository) CreateUser(user *model.User) error {
 	return r.db.Create(user).Error
 }
@@ -38,6 +45,9 @@ func (r *UserRepository) GetUserByID(id string) (*model.User, error) {
 		return nil, err
 	}
 	if err := r.db.Where("id = ?", uuidID).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &user, nil
@@ -55,14 +65,29 @@ func (r *UserRepository) DeleteUser(id string) error {
 	return r.db.Delete(&model.User{}, "id = ?", uuidID).Error
 }
 
-func (r *UserRepository) ListUsers(offset, limit int) ([]model.User, error) {
+// ListUsers возвращает до limit пользователей в порядке id: после afterID (keyset)
+// или, если afterID пуст, со смещения offset
+func (r *UserRepository) ListUsers(afterID uuid.UUID, offset, limit int) ([]model.User, error) {
 	var users []model.User
-	if err := r.db.Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+	query := r.db.Order("id").Limit(limit)
+	if afterID != uuid.Nil {
+		query = query.Where("id > ?", afterID)
+	} else if offset > 0 {
+		query = query.Offset(offset)
+	}
+	if err := query.Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
 }
 
+// CountUsers возвращает общее число пользователей
+func (r *UserRepository) CountUsers() (int64, error) {
+	var count int64
+	err := r.db.Model(&model.User{}).Count(&count).Error
+	return count, err
+}
+
 func (r *UserRepository) GetUserByEmailAndToken(email, token string) (*model.User, error) {
 	var user model.User
 	err := r.db.Where("email = ? AND email_confirmation_token = ?", email, token).First(&user).Error
@@ -78,9 +103,20 @@ func (r *UserRepository) GetUserByEmailAndToken(email, token string) (*model.Use
 func (r *UserRepository) ConfirmUserEmail(user *model.User) error {
 	user.IsEmailConfirmed = true
 	user.EmailConfirmationToken = ""
+	user.EmailConfirmationExpiresAt = 0
 	return r.db.Save(user).Error
 }
 
+// SetEmailConfirmationToken заменяет токен подтверждения email (повторная отправка письма)
+func (r *UserRepository) SetEmailConfirmationToken(user *model.User, token string, expiresAt int64) error {
+	user.EmailConfirmationToken = token
+	user.EmailConfirmationExpiresAt = expiresAt
+	return r.db.Model(user).Updates(map[string]interface{}{
+		"email_confirmation_token":      token,
+		"email_confirmation_expires_at": expiresAt,
+	}).Error
+}
+
 func (r *UserRepository) SetPasswordResetToken(email, token string, expiresAt int64) error {
 	return r.db.Model(&model.User{}).Where("email = ?", email).Updates(map[string]interface{}{
 		"password_reset_token":      token,
@@ -106,3 +142,9 @@ func (r *UserRepository) ResetPassword(user *model.User, newPassword string) err
 	user.PasswordResetExpiresAt = 0
 	return r.db.Save(user).Error
 }
+
+// UpdatePasswordHash сохраняет пересчитанный хэш пароля, не трогая остальные поля
+func (r *UserRepository) UpdatePasswordHash(user *model.User, hash string) error {
+	user.Password = hash
+	return r.db.Model(user).Update("password", hash).Error
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"time"
+	"user-service/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokeToken добавляет jti в список отозванных до момента истечения токена
+func (r *UserRepository) RevokeToken(jti string, userID uuid.UUID, expiresAt time.Time) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+// RevokeUserSessions отзывает все refresh token пользователя и все access token,
+// выданные раньше before; запись хранится до expiresAt (истечение последнего такого токена)
+func (r *UserRepository) RevokeUserSessions(userID uuid.UUID, before, expiresAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", before).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&model.SessionRevocation{
+			UserID:        userID,
+			RevokedBefore: before,
+			ExpiresAt:     expiresAt,
+		}).Error
+	})
+}
+
+// IsTokenRevoked проверяет jti и отзыв всех сессий пользователя: отозваны токены, выданные раньше revoked_before
+func (r *UserRepository) IsTokenRevoked(jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	var count int64
+	if jti != "" {
+		if err := r.db.Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	if err := r.db.Model(&model.SessionRevocation{}).
+		Where("user_id = ? AND revoked_before > ?", userID, issuedAt).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListActiveRevocations возвращает ещё не истёкшие отзывы для синхронизации в других сервисах
+func (r *UserRepository) ListActiveRevocations(now time.Time) ([]model.RevokedToken, []model.SessionRevocation, error) {
+	var tokens []model.RevokedToken
+	if err := r.db.Where("expires_at > ?", now).Find(&tokens).Error; err != nil {
+		return nil, nil, err
+	}
+	var sessions []model.SessionRevocation
+	if err := r.db.Where("expires_at > ?", now).Find(&sessions).Error; err != nil {
+		return nil, nil, err
+	}
+	return tokens, sessions, nil
+}
+
+// DeleteExpiredRevocations удаляет записи, которые больше не влияют на проверку токенов
+func (r *UserRepository) DeleteExpiredRevocations(now time.Time) error {
+	if err := r.db.Where("expires_at <= ?", now).Delete(&model.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where("expires_at <= ?", now).Delete(&model.SessionRevocation{}).Error
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"user-service/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SetUserRole меняет роль пользователя и пишет запись в журнал в одной транзакции
+func (r *UserRepository) SetUserRole(user *model.User, role string, actorID uuid.UUID) error {
+	oldRole := user.Role
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("role", role).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.RoleAudit{
+			UserID:  user.ID,
+			ActorID: actorID,
+			OldRole: oldRole,
+			NewRole: role,
+		}).Error
+	})
+	if err == nil {
+		user.Role = role
+	}
+	return err
+}
+
+// ListRoleAudit возвращает журнал смены ролей пользователя, новые записи первыми
+func (r *UserRepository) ListRoleAudit(userID uuid.UUID) ([]model.RoleAudit, error) {
+	var entries []model.RoleAudit
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&entries).Error
+	return entries, err
+}
+
+// CountUsersByRole возвращает число пользователей с ролью role
+func (r *UserRepository) CountUsersByRole(role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.User{}).Where("role = ?", role).Count(&count).Error
+	return count, err
+}
+
+// ListRoles возвращает все роли с их правами
+func (r *UserRepository) ListRoles() ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.Preload("Permissions").Order("name").Find(&roles).Error
+	return roles, err
+}
+
+// GetRole возвращает роль по имени или nil, если её нет
+func (r *UserRepository) GetRole(name string) (*model.Role, error) {
+	var role model.Role
+	if err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// SaveRole создаёт роль или заменяет описание и права существующей в одной транзакции
+func (r *UserRepository) SaveRole(name, description string, permissions []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		role := model.Role{Name: name}
+		if err := tx.Where(model.Role{Name: name}).FirstOrCreate(&role).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&role).Update("description", description).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role = ?", name).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		for _, perm := range permissions {
+			if err := tx.Create(&model.RolePermission{Role: name, Permission: perm}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteRole удаляет роль вместе с её правами
+func (r *UserRepository) DeleteRole(name string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role = ?", name).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("name = ?", name).Delete(&model.Role{}).Error
+	})
+}
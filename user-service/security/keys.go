@@ -0,0 +1,102 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SigningKey — приватный ключ подписи JWT и его идентификатор (kid)
+type SigningKey struct {
+	KID string
+	Key crypto.Signer
+}
+
+// LoadSigningKeys читает все *.pem из dir; имя файла без расширения используется как kid.
+// Если в каталоге нет ключей, генерируется новый Ed25519-ключ и сохраняется туда же.
+func LoadSigningKeys(dir string) ([]SigningKey, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		key, err := GenerateSigningKey(time.Now().UTC().Format("20060102150405"))
+		if err != nil {
+			return nil, err
+		}
+		if err := WriteSigningKey(dir, key); err != nil {
+			return nil, err
+		}
+		return []SigningKey{key}, nil
+	}
+	sort.Strings(files)
+	keys := make([]SigningKey, 0, len(files))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		kid := strings.TrimSuffix(filepath.Base(f), ".pem")
+		keys = append(keys, SigningKey{KID: kid, Key: signer})
+	}
+	return keys, nil
+}
+
+// GenerateSigningKey создаёт новый Ed25519-ключ
+func GenerateSigningKey(kid string) (SigningKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return SigningKey{}, err
+	}
+	return SigningKey{KID: kid, Key: priv}, nil
+}
+
+// WriteSigningKey сохраняет ключ в dir/<kid>.pem (PKCS#8)
+func WriteSigningKey(dir string, key SigningKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key.Key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	return os.WriteFile(filepath.Join(dir, key.KID+".pem"), data, 0o600)
+}
+
+// ParsePrivateKey разбирает PEM с RSA (PKCS#1/PKCS#8) или Ed25519 (PKCS#8) ключом
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM")
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	default:
+		return nil, errors.New("unsupported key type, use RSA or Ed25519")
+	}
+}
@@ -0,0 +1,54 @@
+package password
+
+import "errors"
+
+// ErrUnknownFormat — хэш не распознан ни одним из алгоритмов
+var ErrUnknownFormat = errors.New("unknown password hash format")
+
+// Hasher — алгоритм хэширования паролей
+type Hasher interface {
+	// Hash возвращает закодированный хэш (вместе с солью и параметрами)
+	Hash(password string) (string, error)
+	// Verify сравнивает пароль с хэшем за постоянное время
+	Verify(password, encoded string) (bool, error)
+	// Recognizes сообщает, создан ли хэш этим алгоритмом
+	Recognizes(encoded string) bool
+	// NeedsRehash сообщает, что хэш создан с устаревшими параметрами
+	NeedsRehash(encoded string) bool
+}
+
+// Service хэширует новые пароли активным алгоритмом и проверяет хэши всех поддерживаемых форматов
+type Service struct {
+	active  Hasher
+	hashers []Hasher
+}
+
+// NewService создаёт сервис с активным алгоритмом active; argon2id, bcrypt
+// и устаревший SHA-256 распознаются всегда
+func NewService(active Hasher) *Service {
+	return &Service{
+		active:  active,
+		hashers: []Hasher{active, NewArgon2id(DefaultArgon2idParams), NewBcrypt(DefaultBcryptCost), LegacySHA256{}},
+	}
+}
+
+// Hash хэширует пароль активным алгоритмом
+func (s *Service) Hash(password string) (string, error) {
+	return s.active.Hash(password)
+}
+
+// Verify проверяет пароль; rehash = true, если хэш нужно пересчитать активным алгоритмом
+// (другой алгоритм, устаревшие параметры или SHA-256)
+func (s *Service) Verify(password, encoded string) (ok, rehash bool, err error) {
+	for _, h := range s.hashers {
+		if !h.Recognizes(encoded) {
+			continue
+		}
+		ok, err = h.Verify(password, encoded)
+		if err != nil || !ok {
+			return false, false, err
+		}
+		return true, !s.active.Recognizes(encoded) || s.active.NeedsRehash(encoded), nil
+	}
+	return false, false, ErrUnknownFormat
+}
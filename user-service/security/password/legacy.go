@@ -0,0 +1,33 @@
+package password
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+)
+
+// LegacySHA256 — несолёный hex(sha256(password)), которым хэшировались пароли раньше.
+// Только проверяет старые хэши: после успешного входа пароль пересчитывается активным алгоритмом.
+type LegacySHA256 struct{}
+
+func (LegacySHA256) Hash(string) (string, error) {
+	return "", errors.New("sha256 password hashing is not supported for new passwords")
+}
+
+func (LegacySHA256) Verify(password, encoded string) (bool, error) {
+	sum := sha256.Sum256([]byte(password))
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(encoded)) == 1, nil
+}
+
+func (LegacySHA256) Recognizes(encoded string) bool {
+	if len(encoded) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(encoded)
+	return err == nil
+}
+
+func (LegacySHA256) NeedsRehash(string) bool {
+	return true
+}
@@ -1,30 +1,154 @@
 package security
 
 import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Значения iss/aud по умолчанию; task-service проверяет их при валидации токена
+const (
+	DefaultIssuer   = "user-service"
+	DefaultAudience = "team-platform"
+)
+
+// Время жизни токенов по умолчанию: access — короткий, refresh — длинный
+const (
+	DefaultAccessTTL  = 15 * time.Minute
+	DefaultRefreshTTL = 30 * 24 * time.Hour
 )
 
 type JWTService struct {
-	secret string
+	secret     string
+	keys       []SigningKey
+	active     *SigningKey
+	issuer     string
+	audience   string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
 }
 
+// NewJWTService создаёт сервис с симметричной подписью HS256
 func NewJWTService(secret string) *JWTService {
-	return &JWTService{secret: secret}
+	return &JWTService{secret: secret, issuer: DefaultIssuer, audience: DefaultAudience, accessTTL: DefaultAccessTTL, refreshTTL: DefaultRefreshTTL}
+}
+
+// WithIssuer переопределяет claims iss и aud выдаваемых токенов
+func (j *JWTService) WithIssuer(issuer, audience string) *JWTService {
+	if issuer != "" {
+		j.issuer = issuer
+	}
+	if audience != "" {
+		j.audience = audience
+	}
+	return j
+}
+
+// WithTTL переопределяет время жизни access и refresh токенов; нулевые значения игнорируются
+func (j *JWTService) WithTTL(access, refresh time.Duration) *JWTService {
+	if access > 0 {
+		j.accessTTL = access
+	}
+	if refresh > 0 {
+		j.refreshTTL = refresh
+	}
+	return j
+}
+
+// AccessTTL — время жизни access token
+func (j *JWTService) AccessTTL() time.Duration {
+	return j.accessTTL
+}
+
+// RefreshTTL — время жизни refresh token
+func (j *JWTService) RefreshTTL() time.Duration {
+	return j.refreshTTL
 }
 
-func (j *JWTService) GenerateToken(userID string) (string, error) {
+// NewAsymmetricJWTService подписывает токены ключом activeKID (RS256 или EdDSA);
+// остальные ключи публикуются в JWKS, чтобы токены, выданные до ротации, оставались валидными.
+// Если activeKID пуст, используется последний ключ из списка.
+func NewAsymmetricJWTService(keys []SigningKey, activeKID string) (*JWTService, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+	j := &JWTService{keys: keys, issuer: DefaultIssuer, audience: DefaultAudience, accessTTL: DefaultAccessTTL, refreshTTL: DefaultRefreshTTL}
+	if activeKID == "" {
+		j.active = &j.keys[len(j.keys)-1]
+		return j, nil
+	}
+	for i := range j.keys {
+		if j.keys[i].KID == activeKID {
+			j.active = &j.keys[i]
+			return j, nil
+		}
+	}
+	return nil, errors.New("active signing key not found: " + activeKID)
+}
+
+// GenerateToken выдаёт токен доступа с ролью пользователя и стандартными claims
+// (sub, iss, aud, iat, exp, jti); user_id дублирует sub для совместимости
+func (j *JWTService) GenerateToken(userID, role string) (string, error) {
+	return j.generateToken(userID, role, nil)
+}
+
+// GenerateUnconfirmedToken выдаёт access token с claim email_verified=false:
+// api-gateway и task-service пускают с ним только к /user/*
+func (j *JWTService) GenerateUnconfirmedToken(userID, role string) (string, error) {
+	return j.generateToken(userID, role, jwt.MapClaims{"email_verified": false})
+}
+
+func (j *JWTService) generateToken(userID, role string, extra jwt.MapClaims) (string, error) {
+	now := time.Now()
 	claims := jwt.MapClaims{
 		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
+		"role":    role,
+		"sub":     userID,
+		"iss":     j.issuer,
+		"aud":     j.audience,
+		"iat":     float64(now.UnixMilli()) / 1000, // с миллисекундами: отзыв сессий не задевает токены, выданные после него
+		"exp":     now.Add(j.accessTTL).Unix(),
+		"jti":     uuid.NewString(),
+	}
+	for k, v := range extra {
+		claims[k] = v
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secret))
+	if j.active == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(j.secret))
+	}
+	token := jwt.NewWithClaims(signingMethod(j.active), claims)
+	token.Header["kid"] = j.active.KID
+	return token.SignedString(j.active.Key)
 }
 
 func (j *JWTService) ValidateToken(tokenStr string) (*jwt.Token, error) {
+	if j.active == nil {
+		return jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+			return []byte(j.secret), nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(j.issuer), jwt.WithAudience(j.audience))
+	}
 	return jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return []byte(j.secret), nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		for _, k := range j.keys {
+			if k.KID == kid {
+				return k.Key.Public(), nil
+			}
+		}
+		return nil, errors.New("unknown kid")
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}), jwt.WithIssuer(j.issuer), jwt.WithAudience(j.audience))
+}
+
+func signingMethod(k *SigningKey) jwt.SigningMethod {
+	switch k.Key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
 }
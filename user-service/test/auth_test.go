@@ -6,6 +6,9 @@ import (
 	"testing"
 	"time"
 	user "user-service/proto"
+	"user-service/security"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 func TestRegisterAndLogin(t *testing.T) {
@@ -38,7 +41,7 @@ func TestRegisterAndLogin(t *testing.T) {
 	}
 }
 
-func TestRegisterWithRoleAndValidation(t *testing.T) {
+func TestRegisterValidation(t *testing.T) {
 	h := SetupHandlerTest()
 	ctx := context.Background()
 
@@ -53,7 +56,6 @@ func TestRegisterWithRoleAndValidation(t *testing.T) {
 				Username: "admin",
 				Email:    "admin@example.com",
 				Password: "password123",
-				Role:     "admin",
 			},
 			expectErr: false,
 		},
@@ -63,7 +65,6 @@ func TestRegisterWithRoleAndValidation(t *testing.T) {
 				Username: "",
 				Email:    "user@example.com",
 				Password: "password123",
-				Role:     "user",
 			},
 			expectErr: true,
 		},
@@ -73,7 +74,6 @@ func TestRegisterWithRoleAndValidation(t *testing.T) {
 				Username: "testuser",
 				Email:    "invalid-email",
 				Password: "password123",
-				Role:     "user",
 			},
 			expectErr: true,
 		},
@@ -252,3 +252,45 @@ func TestPasswordReset(t *testing.T) {
 		t.Error("should not reset with expired token")
 	}
 }
+
+func TestLogin_TokenClaims(t *testing.T) {
+	h := SetupHandlerTest()
+	client := newClient(t, h)
+	ctx := context.Background()
+
+	regResp, err := h.Register(ctx, &user.RegisterRequest{
+		Username: "claimsuser",
+		Email:    "claims@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	if _, err := client.SetUserRole(ctxAs(h, adminID, "admin"), &user.SetUserRoleRequest{UserId: regResp.UserId, Role: "admin"}); err != nil {
+		t.Fatalf("set role failed: %v", err)
+	}
+	loginResp, err := h.Login(ctx, &user.LoginRequest{Email: "claims@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	token, err := h.JwtService.ValidateToken(loginResp.Token)
+	if err != nil {
+		t.Fatalf("token should be valid: %v", err)
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	if claims["role"] != "admin" {
+		t.Errorf("expected role admin, got %v", claims["role"])
+	}
+	if claims["sub"] != regResp.UserId || claims["user_id"] != regResp.UserId {
+		t.Errorf("expected sub/user_id %s, got %v/%v", regResp.UserId, claims["sub"], claims["user_id"])
+	}
+	if claims["iss"] != security.DefaultIssuer || claims["aud"] != security.DefaultAudience {
+		t.Errorf("unexpected iss/aud: %v/%v", claims["iss"], claims["aud"])
+	}
+	for _, c := range []string{"iat", "exp", "jti"} {
+		if _, ok := claims[c]; !ok {
+			t.Errorf("missing claim %s", c)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"user-service/handler"
+	user "user-service/proto"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLogin_UnconfirmedPolicies(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+	email := "unconfirmed@example.com"
+	if _, err := h.Register(ctx, &user.RegisterRequest{Username: "unconfirmed", Email: email, Password: "password123"}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	login := &user.LoginRequest{Email: email, Password: "password123"}
+
+	h.UnconfirmedLogin = handler.UnconfirmedLoginReject
+	_, err := h.Login(ctx, login)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition for unconfirmed email, got %v", err)
+	}
+
+	h.UnconfirmedLogin = handler.UnconfirmedLoginRestrict
+	resp, err := h.Login(ctx, login)
+	if err != nil {
+		t.Fatalf("restricted login failed: %v", err)
+	}
+	if resp.RefreshToken != "" {
+		t.Error("restricted login must not issue refresh token")
+	}
+	token, err := h.JwtService.ValidateToken(resp.Token)
+	if err != nil {
+		t.Fatalf("restricted token should be valid: %v", err)
+	}
+	if v, ok := token.Claims.(jwt.MapClaims)["email_verified"]; !ok || v != false {
+		t.Errorf("expected email_verified=false, got %v", v)
+	}
+
+	h.UnconfirmedLogin = handler.UnconfirmedLoginReject
+	repoUser, _ := h.Repo.GetUserByEmail(email)
+	if resp, _ := h.ConfirmEmail(ctx, &user.ConfirmEmailRequest{Email: email, Token: repoUser.EmailConfirmationToken}); !resp.Success {
+		t.Fatalf("confirm failed: %s", resp.Message)
+	}
+	resp, err = h.Login(ctx, login)
+	if err != nil || resp.RefreshToken == "" {
+		t.Fatalf("expected full login after confirmation, got %v", err)
+	}
+	token, _ = h.JwtService.ValidateToken(resp.Token)
+	if _, ok := token.Claims.(jwt.MapClaims)["email_verified"]; ok {
+		t.Error("confirmed user token must not carry email_verified=false")
+	}
+}
+
+func TestConfirmEmail_ExpiredToken(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+	email := "expired@example.com"
+	if _, err := h.Register(ctx, &user.RegisterRequest{Username: "expired", Email: email, Password: "password123"}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	repoUser, _ := h.Repo.GetUserByEmail(email)
+	if repoUser.EmailConfirmationExpiresAt <= time.Now().Unix() {
+		t.Fatal("confirmation token should have expiry in the future")
+	}
+	_ = h.Repo.SetEmailConfirmationToken(repoUser, "oldtoken", time.Now().Add(-time.Minute).Unix())
+
+	resp, _ := h.ConfirmEmail(ctx, &user.ConfirmEmailRequest{Email: email, Token: "oldtoken"})
+	if resp.Success || resp.Message != "token expired" {
+		t.Errorf("expected token expired, got %v %s", resp.Success, resp.Message)
+	}
+}
+
+func TestResendConfirmation(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+	email := "resend@example.com"
+	if _, err := h.Register(ctx, &user.RegisterRequest{Username: "resend", Email: email, Password: "password123"}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	before, _ := h.Repo.GetUserByEmail(email)
+
+	resp, err := h.ResendConfirmation(ctx, &user.ResendConfirmationRequest{Email: email})
+	if err != nil || !resp.Success {
+		t.Fatalf("resend failed: %v %v", err, resp)
+	}
+	after, _ := h.Repo.GetUserByEmail(email)
+	if after.EmailConfirmationToken == before.EmailConfirmationToken {
+		t.Error("expected new confirmation token")
+	}
+	if resp, _ := h.ConfirmEmail(ctx, &user.ConfirmEmailRequest{Email: email, Token: before.EmailConfirmationToken}); resp.Success {
+		t.Error("old confirmation token must be invalidated")
+	}
+	if resp, _ := h.ConfirmEmail(ctx, &user.ConfirmEmailRequest{Email: email, Token: after.EmailConfirmationToken}); !resp.Success {
+		t.Errorf("confirm with new token failed: %s", resp.Message)
+	}
+}
+
+func TestResendConfirmation_NeutralResponse(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+	confirmed := "confirmed@example.com"
+	if _, err := h.Register(ctx, &user.RegisterRequest{Username: "confirmed", Email: confirmed, Password: "password123"}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	repoUser, _ := h.Repo.GetUserByEmail(confirmed)
+	h.ConfirmEmail(ctx, &user.ConfirmEmailRequest{Email: confirmed, Token: repoUser.EmailConfirmationToken})
+	h.DeliverOutbox(ctx)
+
+	// по ответу нельзя отличить неизвестный и подтверждённый email от обычного
+	pending := "pending@example.com"
+	h.Register(ctx, &user.RegisterRequest{Username: "pending", Email: pending, Password: "password123"})
+	want, err := h.ResendConfirmation(ctx, &user.ResendConfirmationRequest{Email: pending})
+	if err != nil || !want.Success {
+		t.Fatalf("resend failed: %v %v", err, want)
+	}
+	for _, email := range []string{confirmed, "missing@example.com"} {
+		resp, err := h.ResendConfirmation(ctx, &user.ResendConfirmationRequest{Email: email})
+		if err != nil || resp.Success != want.Success || resp.Message != want.Message {
+			t.Errorf("%s: expected %v, got %v %v", email, want, resp, err)
+		}
+	}
+	// письма ушли только при регистрации pending и повторной отправке ему
+	if sent, _ := h.DeliverOutbox(ctx); sent != 2 {
+		t.Errorf("expected 2 emails to pending only, got %d", sent)
+	}
+}
+
+func TestConfirmEmail_TokenWithoutExpiry(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+	email := "legacy@example.com"
+	if _, err := h.Register(ctx, &user.RegisterRequest{Username: "legacy", Email: email, Password: "password123"}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	repoUser, _ := h.Repo.GetUserByEmail(email)
+	// токен, выданный до миграции 7, не имеет срока
+	_ = h.Repo.SetEmailConfirmationToken(repoUser, "legacytoken", 0)
+
+	resp, _ := h.ConfirmEmail(ctx, &user.ConfirmEmailRequest{Email: email, Token: "legacytoken"})
+	if resp.Success || resp.Message != "token expired" {
+		t.Errorf("expected token without expiry to be expired, got %v %s", resp.Success, resp.Message)
+	}
+}
+
+func TestResendConfirmation_RateLimit(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+	email := "resend-limit@example.com"
+
+	var err error
+	for i := 0; i < 4; i++ {
+		_, err = h.ResendConfirmation(ctx, &user.ResendConfirmationRequest{Email: email})
+	}
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted after too many resends, got %v", err)
+	}
+}
@@ -1,18 +1,23 @@
 package test
 
 import (
+	"context"
 	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
-	"user-service/config"
+	"time"
 	"user-service/handler"
-)
+	"user-service/mail"
+	"user-service/model"
+	user "user-service/proto"
+	"user-service/repository"
 
-func TestEmailSendMock(t *testing.T) {
-	handler.SendEmailFunc = func(cfg *config.Config, to, token string) error {
-		return nil
-	}
-}
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
 
 func TestGenerateEmailToken(t *testing.T) {
 	token, err := handler.GenerateEmailToken()
@@ -34,35 +39,131 @@ func TestGenerateResetToken(t *testing.T) {
 	}
 }
 
-func TestSendConfirmationEmail_Format(t *testing.T) {
-	cfg := &config.Config{SMTPUser: "user", SMTPPass: "pass", SMTPHost: "smtp.example.com", SMTPPort: "587", FromEmail: "noreply@example.com"}
-	to := "test@example.com"
-	token := "sometoken"
-	// Подменяем smtp.SendMail на mock
-	called := false
-	handler.SendEmailFunc = func(cfg *config.Config, to, token string) error {
-		called = true
-		if !strings.Contains(to, "@") {
-			t.Error("invalid email format")
-		}
-		if token == "" {
-			t.Error("empty token")
-		}
-		return nil
+func TestRenderer_Templates(t *testing.T) {
+	r, err := mail.NewRenderer("https://app.example.com/", "ru")
+	if err != nil {
+		t.Fatalf("failed to create renderer: %v", err)
+	}
+	msg, err := r.Confirmation("test+1@example.com", "tok/en=")
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	link := "https://app.example.com/confirm?email=test%2B1%40example.com&token=tok%2Fen%3D"
+	if msg.To != "test+1@example.com" || msg.Subject != "Подтверждение email" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+	if !strings.Contains(msg.Text, link) {
+		t.Errorf("text body should contain link %s, got %s", link, msg.Text)
+	}
+	if !strings.Contains(msg.HTML, `href="https://app.example.com/confirm?email=test%2B1%40example.com&amp;token=tok%2Fen%3D"`) {
+		t.Errorf("html body should contain escaped link, got %s", msg.HTML)
+	}
+
+	msg, err = r.PasswordReset("test@example.com", "token")
+	if err != nil || !strings.Contains(msg.Text, "https://app.example.com/reset?") {
+		t.Errorf("unexpected reset email: %v %+v", err, msg)
+	}
+
+	if _, err := mail.NewRenderer("http://localhost", "xx"); err == nil {
+		t.Error("expected error for unknown locale")
+	}
+}
+
+func TestRegister_QueuesConfirmationEmail(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+	mailer := h.Mailer.(*mail.MemoryMailer)
+
+	if _, err := h.Register(ctx, &user.RegisterRequest{Username: "outbox", Email: "outbox@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	if len(mailer.Messages()) != 0 {
+		t.Fatal("email must be sent by outbox worker, not synchronously")
+	}
+
+	sent, err := h.DeliverOutbox(ctx)
+	if err != nil || sent != 1 {
+		t.Fatalf("expected 1 delivered email, got %d (%v)", sent, err)
+	}
+	repoUser, _ := h.Repo.GetUserByEmail("outbox@example.com")
+	msgs := mailer.Messages()
+	if len(msgs) != 1 || msgs[0].To != "outbox@example.com" || !strings.Contains(msgs[0].Text, url.QueryEscape(repoUser.EmailConfirmationToken)) {
+		t.Errorf("unexpected delivered emails: %+v", msgs)
+	}
+
+	if sent, _ := h.DeliverOutbox(ctx); sent != 0 {
+		t.Error("email must not be delivered twice")
+	}
+}
+
+func TestOutbox_RetriesFailedDelivery(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+	mailer := h.Mailer.(*mail.MemoryMailer)
+	mailer.Err = errors.New("smtp error")
+
+	if _, err := h.Register(ctx, &user.RegisterRequest{Username: "retry", Email: "retry@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	if sent, err := h.DeliverOutbox(ctx); err != nil || sent != 0 {
+		t.Fatalf("expected failed delivery, got %d (%v)", sent, err)
+	}
+	// следующая попытка отложена
+	mailer.Err = nil
+	if sent, _ := h.DeliverOutbox(ctx); sent != 0 {
+		t.Error("retry must wait for backoff")
+	}
+}
+
+func TestRegister_FailsWithoutOutbox(t *testing.T) {
+	h := SetupHandlerTest()
+	// outbox недоступен: аккаунт не должен остаться без письма подтверждения
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db.AutoMigrate(&model.User{})
+	h.Repo = repository.NewUserRepository(db)
+
+	if _, err := h.Register(context.Background(), &user.RegisterRequest{Username: "nomail", Email: "nomail@example.com", Password: "password123"}); err == nil {
+		t.Fatal("expected register to fail when the email cannot be queued")
 	}
-	_ = handler.SendEmailFunc(cfg, to, token)
-	if !called {
-		t.Error("SendEmailFunc was not called")
+	if u, _ := h.Repo.GetUserByEmail("nomail@example.com"); u != nil {
+		t.Error("user must not be created without a queued confirmation email")
 	}
 }
 
-func TestSendConfirmationEmail_Error(t *testing.T) {
-	cfg := &config.Config{}
-	handler.SendEmailFunc = func(cfg *config.Config, to, token string) error {
-		return errors.New("smtp error")
+func TestOutbox_ClaimsEmails(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+	if _, err := h.Register(ctx, &user.RegisterRequest{Username: "claim", Email: "claim@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	now := time.Now()
+	claimed, err := h.Repo.ClaimPendingEmails(now, now.Add(time.Minute), handler.MaxEmailAttempts, 10)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("expected 1 claimed email, got %d (%v)", len(claimed), err)
+	}
+	// захваченное письмо не достаётся другому воркеру
+	if again, _ := h.Repo.ClaimPendingEmails(now, now.Add(time.Minute), handler.MaxEmailAttempts, 10); len(again) != 0 {
+		t.Errorf("claimed email must not be claimed twice, got %d", len(again))
+	}
+	if sent, _ := h.DeliverOutbox(ctx); sent != 0 {
+		t.Errorf("claimed email must not be delivered by another worker, got %d", sent)
 	}
-	err := handler.SendEmailFunc(cfg, "fail@example.com", "token")
-	if err == nil || !strings.Contains(err.Error(), "smtp error") {
-		t.Error("expected smtp error")
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m := &mail.FileMailer{Dir: dir, From: "noreply@example.com"}
+	if err := m.Send(context.Background(), mail.Message{To: "u@example.com", Subject: "Hi", Text: "text", HTML: "<p>html</p>"}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected 1 eml file, got %d", len(files))
+	}
+	body, _ := os.ReadFile(files[0])
+	for _, want := range []string{"To: u@example.com", "multipart/alternative", "text/plain", "text/html", "<p>html</p>"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("eml should contain %q", want)
+		}
 	}
 }
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"user-service/security"
+)
+
+func TestAsymmetricJWT_SignAndValidate(t *testing.T) {
+	edKey, err := security.GenerateSigningKey("ed-1")
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	keys := []security.SigningKey{edKey, {KID: "rsa-2", Key: rsaPriv}}
+
+	// Токен, выданный старым ключом до ротации
+	oldSvc, err := security.NewAsymmetricJWTService(keys, "ed-1")
+	if err != nil {
+		t.Fatalf("failed to init jwt service: %v", err)
+	}
+	oldToken, err := oldSvc.GenerateToken("user-1", "user")
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	svc, err := security.NewAsymmetricJWTService(keys, "")
+	if err != nil {
+		t.Fatalf("failed to init jwt service: %v", err)
+	}
+	token, err := svc.GenerateToken("user-1", "user")
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	parsed, err := svc.ValidateToken(token)
+	if err != nil || !parsed.Valid {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+	if parsed.Header["kid"] != "rsa-2" || parsed.Method.Alg() != "RS256" {
+		t.Errorf("expected RS256 token with kid rsa-2, got %v %v", parsed.Header["kid"], parsed.Method.Alg())
+	}
+	if _, err := svc.ValidateToken(oldToken); err != nil {
+		t.Errorf("token signed by previous key should stay valid: %v", err)
+	}
+
+	hmacToken, _ := security.NewJWTService("testsecret").GenerateToken("user-1", "user")
+	if _, err := svc.ValidateToken(hmacToken); err == nil {
+		t.Error("HS256 token must be rejected by asymmetric service")
+	}
+}
+
+func TestJWKSHandler(t *testing.T) {
+	keys, err := security.LoadSigningKeys(t.TempDir())
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("expected generated key, got %v, %v", keys, err)
+	}
+	svc, err := security.NewAsymmetricJWTService(keys, "")
+	if err != nil {
+		t.Fatalf("failed to init jwt service: %v", err)
+	}
+
+	rw := httptest.NewRecorder()
+	svc.JWKSHandler().ServeHTTP(rw, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+
+	var set security.JWKS
+	if err := json.Unmarshal(rw.Body.Bytes(), &set); err != nil {
+		t.Fatalf("invalid jwks json: %v", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].Kid != keys[0].KID || set.Keys[0].Kty != "OKP" || set.Keys[0].X == "" {
+		t.Errorf("unexpected jwks: %+v", set)
+	}
+}
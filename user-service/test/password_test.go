@@ -0,0 +1,90 @@
+package test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"user-service/model"
+	user "user-service/proto"
+	"user-service/security/password"
+
+	"github.com/google/uuid"
+)
+
+func TestPasswordHashers(t *testing.T) {
+	hashers := map[string]password.Hasher{
+		"argon2id": password.NewArgon2id(password.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}),
+		"bcrypt":   password.NewBcrypt(4),
+	}
+	for name, h := range hashers {
+		svc := password.NewService(h)
+		hash, err := svc.Hash("password123")
+		if err != nil {
+			t.Fatalf("%s: hash failed: %v", name, err)
+		}
+		other, _ := svc.Hash("password123")
+		if hash == other {
+			t.Errorf("%s: expected salted hashes to differ", name)
+		}
+		if ok, rehash, err := svc.Verify("password123", hash); err != nil || !ok || rehash {
+			t.Errorf("%s: expected valid password without rehash, got ok=%v rehash=%v err=%v", name, ok, rehash, err)
+		}
+		if ok, _, _ := svc.Verify("wrongpass", hash); ok {
+			t.Errorf("%s: expected wrong password to be rejected", name)
+		}
+	}
+}
+
+func TestPasswordService_Rehash(t *testing.T) {
+	argon := password.NewService(password.NewArgon2id(password.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}))
+	stronger := password.NewService(password.NewArgon2id(password.Argon2idParams{Memory: 2048, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32}))
+	bcryptSvc := password.NewService(password.NewBcrypt(4))
+
+	hash, _ := argon.Hash("password123")
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("unexpected PHC string: %s", hash)
+	}
+	if ok, rehash, _ := stronger.Verify("password123", hash); !ok || !rehash {
+		t.Error("expected rehash for hash with outdated params")
+	}
+	if ok, rehash, _ := bcryptSvc.Verify("password123", hash); !ok || !rehash {
+		t.Error("expected rehash when active algorithm differs")
+	}
+	if _, _, err := argon.Verify("password123", "garbage"); err == nil {
+		t.Error("expected error for unknown hash format")
+	}
+}
+
+func TestLogin_UpgradesLegacySHA256(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+
+	sum := sha256.Sum256([]byte("password123"))
+	legacy := &model.User{
+		ID:               uuid.New(),
+		Username:         "legacy",
+		Email:            "legacy@example.com",
+		Password:         hex.EncodeToString(sum[:]),
+		Role:             "user",
+		IsEmailConfirmed: true,
+	}
+	if err := h.Repo.CreateUser(legacy); err != nil {
+		t.Fatalf("create user failed: %v", err)
+	}
+
+	if _, err := h.Login(ctx, &user.LoginRequest{Email: legacy.Email, Password: "wrongpass"}); err == nil {
+		t.Fatal("expected error for wrong password")
+	}
+	if _, err := h.Login(ctx, &user.LoginRequest{Email: legacy.Email, Password: "password123"}); err != nil {
+		t.Fatalf("login with legacy hash failed: %v", err)
+	}
+	stored, _ := h.Repo.GetUserByEmail(legacy.Email)
+	if !strings.HasPrefix(stored.Password, "$argon2id$") {
+		t.Fatalf("expected password to be rehashed with argon2id, got %s", stored.Password)
+	}
+	if _, err := h.Login(ctx, &user.LoginRequest{Email: legacy.Email, Password: "password123"}); err != nil {
+		t.Fatalf("login after rehash failed: %v", err)
+	}
+}
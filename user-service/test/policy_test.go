@@ -0,0 +1,124 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"shared/rbac"
+	"testing"
+	user "user-service/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	p := rbac.NewPolicy(rbac.DefaultRoles())
+	owner := func(ctx context.Context, userID string, req interface{}) (bool, error) {
+		return userID == "owner", nil
+	}
+	rule := rbac.Rule{Any: rbac.PermTaskDeleteAny, Own: rbac.PermTaskDeleteOwn, Owner: owner}
+
+	cases := []struct {
+		role, userID string
+		want         bool
+	}{
+		{"admin", "someone", true},
+		{"user", "owner", true},
+		{"user", "someone", false},
+		{"unknown", "owner", false},
+	}
+	for _, c := range cases {
+		if got, _ := p.Check(context.Background(), rule, c.userID, c.role, nil); got != c.want {
+			t.Errorf("role %s, user %s: expected %v, got %v", c.role, c.userID, c.want, got)
+		}
+	}
+	if ok, _ := p.Check(context.Background(), rbac.Rule{}, "someone", "unknown", nil); !ok {
+		t.Error("empty rule must allow any authenticated user")
+	}
+}
+
+func TestRoleManagement(t *testing.T) {
+	h := SetupHandlerTest()
+	client := newClient(t, h)
+	ctx := context.Background()
+	adminCtx := ctxAs(h, adminID, "admin")
+
+	roles, err := client.ListRoles(adminCtx, &user.ListRolesRequest{})
+	if err != nil || len(roles.Roles) != 2 || len(roles.Permissions) != len(rbac.AllPermissions) {
+		t.Fatalf("expected default roles, got %v, %v", roles, err)
+	}
+
+	regResp, _ := h.Register(ctx, &user.RegisterRequest{Username: "viewer", Email: "viewer@example.com", Password: "password123"})
+	viewerCtx := ctxAs(h, regResp.UserId, "user")
+	if _, err := client.ListRoles(viewerCtx, &user.ListRolesRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for user, got %v", err)
+	}
+	if _, err := client.ListUsers(viewerCtx, &user.ListUsersRequest{Page: 1, PageSize: 10}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied before role change, got %v", err)
+	}
+
+	// новая роль с правом просмотра списка пользователей
+	_, err = client.UpsertRole(adminCtx, &user.UpsertRoleRequest{Role: &user.RoleInfo{
+		Name: "auditor", Description: "Просмотр пользователей",
+		Permissions: []string{rbac.PermUserList, rbac.PermUserReadAny, rbac.PermUserList},
+	}})
+	if err != nil {
+		t.Fatalf("upsert role failed: %v", err)
+	}
+	if _, err := client.SetUserRole(adminCtx, &user.SetUserRoleRequest{UserId: regResp.UserId, Role: "auditor"}); err != nil {
+		t.Fatalf("set role failed: %v", err)
+	}
+	// сессии viewer отозваны при смене роли, права проверяются токеном другого пользователя с той же ролью
+	auditorCtx := ctxAs(h, uuid.NewString(), "auditor")
+	if _, err := client.ListUsers(auditorCtx, &user.ListUsersRequest{Page: 1, PageSize: 10}); err != nil {
+		t.Errorf("expected auditor to list users, got %v", err)
+	}
+	if _, err := client.DeleteUser(auditorCtx, &user.DeleteUserRequest{UserId: regResp.UserId}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for delete, got %v", err)
+	}
+
+	invalid := []*user.RoleInfo{
+		{Name: "Bad Name"},
+		{Name: "editor", Permissions: []string{"task:fly"}},
+		{Name: "admin", Permissions: []string{rbac.PermUserList}},
+	}
+	for _, r := range invalid {
+		if _, err := client.UpsertRole(adminCtx, &user.UpsertRoleRequest{Role: r}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument for %+v, got %v", r, err)
+		}
+	}
+
+	if _, err := client.DeleteRole(adminCtx, &user.DeleteRoleRequest{Name: "auditor"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for role in use, got %v", err)
+	}
+	if _, err := client.DeleteRole(adminCtx, &user.DeleteRoleRequest{Name: "user"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for builtin role, got %v", err)
+	}
+	if _, err := client.DeleteRole(adminCtx, &user.DeleteRoleRequest{Name: "ghost"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+	client.SetUserRole(adminCtx, &user.SetUserRoleRequest{UserId: regResp.UserId, Role: "user"})
+	if _, err := client.DeleteRole(adminCtx, &user.DeleteRoleRequest{Name: "auditor"}); err != nil {
+		t.Fatalf("delete role failed: %v", err)
+	}
+	if h.Policy.HasRole("auditor") {
+		t.Error("deleted role must be removed from policy")
+	}
+}
+
+func TestPolicyHandler(t *testing.T) {
+	h := SetupHandlerTest()
+	rec := httptest.NewRecorder()
+	h.PolicyHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/internal/policy", nil))
+	var doc struct {
+		Roles map[string][]string `json:"roles"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(doc.Roles["admin"]) != len(rbac.AllPermissions) || len(doc.Roles["user"]) == 0 {
+		t.Errorf("unexpected policy document: %+v", doc.Roles)
+	}
+}
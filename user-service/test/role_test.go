@@ -0,0 +1,89 @@
+package test
+
+import (
+	"context"
+	"testing"
+	user "user-service/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSetUserRole(t *testing.T) {
+	h := SetupHandlerTest()
+	client := newClient(t, h)
+	ctx := context.Background()
+
+	regResp, err := h.Register(ctx, &user.RegisterRequest{Username: "roleuser", Email: "role@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	registered, _ := h.Repo.GetUserByID(regResp.UserId)
+	if registered.Role != "user" {
+		t.Fatalf("registered user must get role user, got %s", registered.Role)
+	}
+	userToken, _ := h.Login(ctx, &user.LoginRequest{Email: "role@example.com", Password: "password123"})
+
+	_, err = client.SetUserRole(ctxWithJWT(userToken.Token), &user.SetUserRoleRequest{UserId: regResp.UserId, Role: "admin"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for self promotion, got %v", err)
+	}
+	adminCtx := ctxAs(h, adminID, "admin")
+	if _, err := client.SetUserRole(adminCtx, &user.SetUserRoleRequest{UserId: regResp.UserId, Role: "root"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for unknown role, got %v", err)
+	}
+	if _, err := client.SetUserRole(adminCtx, &user.SetUserRoleRequest{UserId: uuid.NewString(), Role: "admin"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for unknown user, got %v", err)
+	}
+
+	resp, err := client.SetUserRole(adminCtx, &user.SetUserRoleRequest{UserId: regResp.UserId, Role: "admin"})
+	if err != nil || resp.Role != "admin" {
+		t.Fatalf("set role failed: %v", err)
+	}
+	audit, _ := h.Repo.ListRoleAudit(registered.ID)
+	if len(audit) != 1 || audit[0].OldRole != "user" || audit[0].NewRole != "admin" || audit[0].ActorID.String() != adminID {
+		t.Errorf("unexpected audit log: %+v", audit)
+	}
+	// токен со старой ролью больше не действует
+	if _, err := h.GetAuthClaims(ctxWithJWT(userToken.Token)); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected old token to be revoked after role change, got %v", err)
+	}
+}
+
+func TestBootstrapAdmin(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+
+	admin, err := h.BootstrapAdmin("root@example.com", "root", "password123")
+	if err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	if admin.Role != "admin" || !admin.IsEmailConfirmed {
+		t.Errorf("expected confirmed admin, got %+v", admin)
+	}
+	if n, _ := h.Repo.CountUsersByRole("admin"); n != 1 {
+		t.Errorf("expected 1 admin, got %d", n)
+	}
+	if _, err := h.Login(ctx, &user.LoginRequest{Email: "root@example.com", Password: "password123"}); err != nil {
+		t.Errorf("bootstrap admin login failed: %v", err)
+	}
+
+	// существующий пользователь повышается до admin, пароль не меняется
+	regResp, _ := h.Register(ctx, &user.RegisterRequest{Username: "existing", Email: "existing@example.com", Password: "password123"})
+	if _, err := h.BootstrapAdmin("existing@example.com", "", ""); err != nil {
+		t.Fatalf("bootstrap existing user failed: %v", err)
+	}
+	promoted, _ := h.Repo.GetUserByID(regResp.UserId)
+	if promoted.Role != "admin" {
+		t.Errorf("expected existing user to become admin, got %s", promoted.Role)
+	}
+	audit, _ := h.Repo.ListRoleAudit(promoted.ID)
+	if len(audit) != 1 || audit[0].ActorID != uuid.Nil {
+		t.Errorf("expected bootstrap audit entry with nil actor, got %+v", audit)
+	}
+
+	if _, err := h.BootstrapAdmin("new@example.com", "new", "123"); err == nil {
+		t.Error("expected error for short password")
+	}
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	user "user-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLogout_RevokesTokens(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+
+	login := loginForTokens(t, h, "logout@example.com")
+	authCtx := ctxWithJWT(login.Token)
+	if _, err := h.GetAuthClaims(authCtx); err != nil {
+		t.Fatalf("token should be valid before logout: %v", err)
+	}
+
+	resp, err := h.Logout(authCtx, &user.LogoutRequest{RefreshToken: login.RefreshToken})
+	if err != nil || !resp.Success {
+		t.Fatalf("logout failed: %v", err)
+	}
+
+	if _, err := h.GetAuthClaims(authCtx); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected revoked access token, got %v", err)
+	}
+	if _, err := h.RefreshToken(ctx, &user.RefreshTokenRequest{RefreshToken: login.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected revoked refresh token, got %v", err)
+	}
+
+	rw := httptest.NewRecorder()
+	h.RevocationsHandler().ServeHTTP(rw, httptest.NewRequest("GET", "/internal/revocations", nil))
+	var doc struct {
+		Tokens []struct {
+			JTI string `json:"jti"`
+		} `json:"tokens"`
+	}
+	if err := json.Unmarshal(rw.Body.Bytes(), &doc); err != nil || len(doc.Tokens) != 1 || doc.Tokens[0].JTI == "" {
+		t.Errorf("expected revoked jti in revocation list, got %s", rw.Body.String())
+	}
+}
+
+func TestLogout_NoToken(t *testing.T) {
+	h := SetupHandlerTest()
+	if _, err := h.Logout(context.Background(), &user.LogoutRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated without token, got %v", err)
+	}
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	h := SetupHandlerTest()
+	client := newClient(t, h)
+	ctx := context.Background()
+
+	target := loginForTokens(t, h, "victim@example.com")
+	claims, err := h.GetAuthClaims(ctxWithJWT(target.Token))
+	if err != nil {
+		t.Fatalf("token should be valid: %v", err)
+	}
+	userID := claims.UserID.String()
+
+	// Обычный пользователь не может отзывать чужие сессии
+	_, err = client.RevokeUserSessions(ctxWithJWT(target.Token), &user.RevokeUserSessionsRequest{UserId: userID})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for non-admin, got %v", err)
+	}
+
+	resp, err := client.RevokeUserSessions(ctxAs(h, adminID, "admin"), &user.RevokeUserSessionsRequest{UserId: userID})
+	if err != nil || !resp.Success {
+		t.Fatalf("revoke sessions failed: %v", err)
+	}
+
+	if _, err := h.GetAuthClaims(ctxWithJWT(target.Token)); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected access token to be revoked, got %v", err)
+	}
+	if _, err := h.RefreshToken(ctx, &user.RefreshTokenRequest{RefreshToken: target.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected refresh token to be revoked, got %v", err)
+	}
+
+	// вход сразу после отзыва (в ту же секунду) выдаёт действующий токен
+	relogin, err := h.Login(ctx, &user.LoginRequest{Email: "victim@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("login after revocation failed: %v", err)
+	}
+	if _, err := h.GetAuthClaims(ctxWithJWT(relogin.Token)); err != nil {
+		t.Errorf("expected token issued after revocation to be valid, got %v", err)
+	}
+}
+
+func TestResetPassword_RevokesSessions(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+
+	stolen := loginForTokens(t, h, "reset-victim@example.com")
+	h.RequestPasswordReset(ctx, &user.RequestPasswordResetRequest{Email: "reset-victim@example.com"})
+	repoUser, _ := h.Repo.GetUserByEmail("reset-victim@example.com")
+	resp, err := h.ResetPassword(ctx, &user.ResetPasswordRequest{
+		Email:       "reset-victim@example.com",
+		Token:       repoUser.PasswordResetToken,
+		NewPassword: "newpassword123",
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("reset password failed: %v, %v", err, resp.GetMessage())
+	}
+
+	if _, err := h.GetAuthClaims(ctxWithJWT(stolen.Token)); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected access token to be revoked after password reset, got %v", err)
+	}
+	if _, err := h.RefreshToken(ctx, &user.RefreshTokenRequest{RefreshToken: stolen.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected refresh token to be revoked after password reset, got %v", err)
+	}
+	relogin, err := h.Login(ctx, &user.LoginRequest{Email: "reset-victim@example.com", Password: "newpassword123"})
+	if err != nil {
+		t.Fatalf("login with the new password failed: %v", err)
+	}
+	if _, err := h.GetAuthClaims(ctxWithJWT(relogin.Token)); err != nil {
+		t.Errorf("expected token issued after reset to be valid, got %v", err)
+	}
+}
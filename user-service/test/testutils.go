@@ -1,19 +1,79 @@
 package test
 
 import (
+	"context"
+	"net"
+	"shared/cursor"
+	"testing"
 	"user-service/handler"
+	"user-service/mail"
 	"user-service/model"
+	pb "user-service/proto"
 	"user-service/repository"
 	"user-service/security"
+	"user-service/security/password"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 func SetupHandlerTest() *handler.UserServer {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	db.AutoMigrate(&model.User{})
+	db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.SessionRevocation{}, &model.OutboxEmail{}, &model.RoleAudit{}, &model.Role{}, &model.RolePermission{})
 	repo := repository.NewUserRepository(db)
 	jwt := security.NewJWTService("testsecret")
-	return &handler.UserServer{Repo: repo, JwtService: jwt}
+	// дешёвые параметры argon2id, чтобы не замедлять тесты
+	hasher := password.NewArgon2id(password.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	templates, _ := mail.NewRenderer("http://localhost:8080", mail.DefaultLocale)
+	h := &handler.UserServer{
+		Repo:       repo,
+		JwtService: jwt,
+		Passwords:  password.NewService(hasher),
+		Mailer:     mail.NewMemoryMailer(),
+		Templates:  templates,
+		Cursors:    cursor.NewCodec([]byte("testsecret")),
+
+		// большинство тестов логинится сразу после регистрации; политика reject/restrict проверяется в confirmation_test.go
+		UnconfirmedLogin: handler.UnconfirmedLoginAllow,
+	}
+	// пустая таблица roles заполняется ролями по умолчанию
+	h.LoadPolicy()
+	return h
+}
+
+// newClient поднимает in-memory gRPC-сервер с AuthInterceptor, как в main, и возвращает клиент к нему:
+// права проверяет только интерцептор, поэтому защищённые RPC тестируются через клиент
+func newClient(t *testing.T, h *handler.UserServer) pb.UserServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(h.AuthInterceptor()))
+	pb.RegisterUserServiceServer(srv, h)
+	go srv.Serve(lis)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial test server: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+	})
+	return pb.NewUserServiceClient(conn)
+}
+
+// ctxWithJWT возвращает context с JWT в metadata: входящей — для прямого вызова обработчика,
+// исходящей — для вызова через newClient
+func ctxWithJWT(token string) context.Context {
+	md := metadata.New(map[string]string{"authorization": "Bearer " + token})
+	return metadata.NewOutgoingContext(metadata.NewIncomingContext(context.Background(), md), md)
+}
+
+// ctxAs возвращает context с access token пользователя userID с ролью role
+func ctxAs(h *handler.UserServer, userID, role string) context.Context {
+	token, _ := h.JwtService.GenerateToken(userID, role)
+	return ctxWithJWT(token)
 }
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"user-service/handler"
+	user "user-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// loginForTokens регистрирует пользователя и возвращает результат Login
+func loginForTokens(t *testing.T, h *handler.UserServer, email string) *user.LoginResponse {
+	ctx := context.Background()
+	if _, err := h.Register(ctx, &user.RegisterRequest{Username: "tokenuser", Email: email, Password: "password123"}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	resp, err := h.Login(ctx, &user.LoginRequest{Email: email, Password: "password123"})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	return resp
+}
+
+func TestRefreshToken_Rotation(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+
+	login := loginForTokens(t, h, "refresh@example.com")
+	if login.RefreshToken == "" || login.ExpiresIn <= 0 {
+		t.Fatalf("expected refresh token and expires_in, got %+v", login)
+	}
+
+	resp, err := h.RefreshToken(ctx, &user.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if resp.Token == "" || resp.RefreshToken == "" || resp.RefreshToken == login.RefreshToken {
+		t.Errorf("expected new token pair, got %+v", resp)
+	}
+	if _, err := h.JwtService.ValidateToken(resp.Token); err != nil {
+		t.Errorf("refreshed access token should be valid: %v", err)
+	}
+}
+
+func TestRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+
+	login := loginForTokens(t, h, "reuse@example.com")
+	first, err := h.RefreshToken(ctx, &user.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+
+	// Повторное использование уже обменянного токена
+	_, err = h.RefreshToken(ctx, &user.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated on reuse, got %v", err)
+	}
+
+	// Вся семья отозвана: последний выданный токен тоже недействителен
+	_, err = h.RefreshToken(ctx, &user.RefreshTokenRequest{RefreshToken: first.RefreshToken})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected family to be revoked, got %v", err)
+	}
+}
+
+func TestRefreshToken_Invalid(t *testing.T) {
+	h := SetupHandlerTest()
+	ctx := context.Background()
+
+	if _, err := h.RefreshToken(ctx, &user.RefreshTokenRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for empty token, got %v", err)
+	}
+	if _, err := h.RefreshToken(ctx, &user.RefreshTokenRequest{RefreshToken: "unknown"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated for unknown token, got %v", err)
+	}
+}
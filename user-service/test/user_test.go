@@ -2,13 +2,24 @@ package test
 
 import (
 	"context"
+	"net/url"
 	"strconv"
+	"strings"
 	"testing"
+	"user-service/handler"
+	"user-service/mail"
 	user "user-service/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+const adminID = "99999999-9999-9999-9999-999999999999"
+
 func TestUpdateAndDeleteUser(t *testing.T) {
 	h := SetupHandlerTest()
+	client := newClient(t, h)
 	ctx := context.Background()
 
 	// Create a user
@@ -21,28 +32,66 @@ func TestUpdateAndDeleteUser(t *testing.T) {
 		t.Fatalf("register failed: %v", err)
 	}
 
-	// Update the user's name
+	// Update the user's name (as the user themselves)
+	ctx = ctxAs(h, regResp.UserId, "user")
 	updReq := &user.UpdateUserRequest{
 		UserId:   regResp.UserId,
 		Username: "Jane Doe",
 		Email:    "john.doe@example.com",
 		Role:     "user",
 	}
-	_, err = h.UpdateUser(ctx, updReq)
+	_, err = client.UpdateUser(ctx, updReq)
 	if err != nil {
 		t.Fatalf("update failed: %v", err)
 	}
 
-	// Delete the user
+	// Delete the user (admin only)
 	delReq := &user.DeleteUserRequest{UserId: regResp.UserId}
-	_, err = h.DeleteUser(ctx, delReq)
+	_, err = client.DeleteUser(ctxAs(h, adminID, "admin"), delReq)
 	if err != nil {
 		t.Fatalf("delete failed: %v", err)
 	}
 }
 
+func TestUpdateUser_EmailChange(t *testing.T) {
+	h := SetupHandlerTest()
+	client := newClient(t, h)
+	ctx := context.Background()
+	mailer := h.Mailer.(*mail.MemoryMailer)
+
+	alice, _ := h.Register(ctx, &user.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: "password123"})
+	h.Register(ctx, &user.RegisterRequest{Username: "bob", Email: "bob@example.com", Password: "password123"})
+	confirmed, _ := h.Repo.GetUserByEmail("alice@example.com")
+	h.Repo.ConfirmUserEmail(confirmed)
+	h.DeliverOutbox(ctx)
+	aliceCtx := ctxAs(h, alice.UserId, "user")
+
+	_, err := client.UpdateUser(aliceCtx, &user.UpdateUserRequest{UserId: alice.UserId, Username: "alice", Email: "bob@example.com"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("expected AlreadyExists for a taken email, got %v", err)
+	}
+
+	_, err = client.UpdateUser(aliceCtx, &user.UpdateUserRequest{UserId: alice.UserId, Username: "alice", Email: "alice@new.example.com"})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	updated, _ := h.Repo.GetUserByID(alice.UserId)
+	if updated.Email != "alice@new.example.com" || updated.IsEmailConfirmed || updated.EmailConfirmationToken == "" {
+		t.Fatalf("expected new unconfirmed email with a token, got %+v", updated)
+	}
+	if sent, _ := h.DeliverOutbox(ctx); sent != 1 {
+		t.Fatalf("expected one confirmation email, got %d", sent)
+	}
+	msgs := mailer.Messages()
+	last := msgs[len(msgs)-1]
+	if last.To != "alice@new.example.com" || !strings.Contains(last.Text, url.QueryEscape(updated.EmailConfirmationToken)) {
+		t.Errorf("expected confirmation email to the new address, got %+v", last)
+	}
+}
+
 func TestListUsers(t *testing.T) {
 	h := SetupHandlerTest()
+	client := newClient(t, h)
 	ctx := context.Background()
 
 	// Create test users
@@ -51,16 +100,15 @@ func TestListUsers(t *testing.T) {
 			Username: "User" + strconv.Itoa(i),
 			Email:    "user" + strconv.Itoa(i) + "@example.com",
 			Password: "password123",
-			Role:     "user",
 		})
 		if err != nil {
 			t.Fatalf("register failed: %v", err)
 		}
 	}
 
-	// List users
+	// List users (admin only)
 	req := &user.ListUsersRequest{Page: 1, PageSize: 10}
-	res, err := h.ListUsers(ctx, req)
+	res, err := client.ListUsers(ctxAs(h, adminID, "admin"), req)
 	if err != nil {
 		t.Fatalf("list users failed: %v", err)
 	}
@@ -69,3 +117,153 @@ func TestListUsers(t *testing.T) {
 		t.Fatalf("expected 3 users, got %d", len(res.Users))
 	}
 }
+
+func TestListUsers_PageTokens(t *testing.T) {
+	h := SetupHandlerTest()
+	client := newClient(t, h)
+	ctx := context.Background()
+	adminCtx := ctxAs(h, adminID, "admin")
+
+	for i := 1; i <= 7; i++ {
+		if _, err := h.Register(ctx, &user.RegisterRequest{
+			Username: "User" + strconv.Itoa(i),
+			Email:    "user" + strconv.Itoa(i) + "@example.com",
+			Password: "password123",
+		}); err != nil {
+			t.Fatalf("register failed: %v", err)
+		}
+	}
+
+	seen := map[string]bool{}
+	req := &user.ListUsersRequest{PageSize: 3, IncludeTotal: true}
+	pages := 0
+	for {
+		res, err := client.ListUsers(adminCtx, req)
+		if err != nil {
+			t.Fatalf("list users failed: %v", err)
+		}
+		pages++
+		if res.Total != 7 {
+			t.Fatalf("expected total 7, got %d", res.Total)
+		}
+		for _, u := range res.Users {
+			if seen[u.UserId] {
+				t.Fatalf("user %s returned twice", u.Username)
+			}
+			seen[u.UserId] = true
+		}
+		if res.NextPageToken == "" {
+			break
+		}
+		req.PageToken = res.NextPageToken
+	}
+	if len(seen) != 7 || pages != 3 {
+		t.Fatalf("expected 7 users on 3 pages, got %d on %d", len(seen), pages)
+	}
+
+	// без include_total общее число не считается
+	res, err := client.ListUsers(adminCtx, &user.ListUsersRequest{PageSize: 3})
+	if err != nil {
+		t.Fatalf("list users failed: %v", err)
+	}
+	if res.Total != 0 || res.NextPageToken == "" {
+		t.Fatalf("expected no total and a next token, got %d %q", res.Total, res.NextPageToken)
+	}
+
+	// изменённый токен отклоняется
+	tampered := res.NextPageToken[:len(res.NextPageToken)-2] + "AA"
+	for _, token := range []string{tampered, "garbage"} {
+		_, err := client.ListUsers(adminCtx, &user.ListUsersRequest{PageToken: token})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("token %q: expected InvalidArgument, got %v", token, err)
+		}
+	}
+}
+
+func TestUserRPC_Authorization(t *testing.T) {
+	h := SetupHandlerTest()
+	client := newClient(t, h)
+	ctx := context.Background()
+
+	reg := func(name string) string {
+		resp, err := h.Register(ctx, &user.RegisterRequest{Username: name, Email: name + "@example.com", Password: "password123"})
+		if err != nil {
+			t.Fatalf("register failed: %v", err)
+		}
+		return resp.UserId
+	}
+	alice, bob := reg("alice"), reg("bob")
+	aliceCtx := ctxAs(h, alice, "user")
+	adminCtx := ctxAs(h, adminID, "admin")
+
+	type check struct {
+		name string
+		code codes.Code
+		err  error
+	}
+	var checks []check
+	add := func(name string, code codes.Code, err error) {
+		checks = append(checks, check{name, code, err})
+	}
+
+	_, err := client.GetProfile(ctx, &user.GetProfileRequest{UserId: alice})
+	add("get profile without token", codes.Unauthenticated, err)
+	_, err = client.GetProfile(aliceCtx, &user.GetProfileRequest{UserId: alice})
+	add("get own profile", codes.OK, err)
+	_, err = client.GetProfile(aliceCtx, &user.GetProfileRequest{UserId: bob})
+	add("get other profile", codes.PermissionDenied, err)
+	_, err = client.GetProfile(adminCtx, &user.GetProfileRequest{UserId: bob})
+	add("admin gets other profile", codes.OK, err)
+
+	_, err = client.UpdateUser(aliceCtx, &user.UpdateUserRequest{UserId: bob, Username: "bob2", Email: "bob@example.com"})
+	add("update other user", codes.PermissionDenied, err)
+	_, err = client.UpdateUser(aliceCtx, &user.UpdateUserRequest{UserId: alice, Username: "alice", Email: "alice@example.com", Role: "admin"})
+	add("self promotion", codes.PermissionDenied, err)
+	_, err = client.UpdateUser(adminCtx, &user.UpdateUserRequest{UserId: bob, Username: "bob", Email: "bob@example.com", Role: "admin"})
+	add("admin changes role", codes.OK, err)
+
+	_, err = client.DeleteUser(aliceCtx, &user.DeleteUserRequest{UserId: alice})
+	add("user deletes self", codes.PermissionDenied, err)
+	_, err = client.ListUsers(aliceCtx, &user.ListUsersRequest{Page: 1, PageSize: 10})
+	add("user lists users", codes.PermissionDenied, err)
+
+	for _, c := range checks {
+		if status.Code(c.err) != c.code {
+			t.Errorf("%s: expected %v, got %v", c.name, c.code, c.err)
+		}
+	}
+
+	profile, _ := client.GetProfile(adminCtx, &user.GetProfileRequest{UserId: alice})
+	if profile.Role != "user" {
+		t.Errorf("role must not change after denied self promotion, got %s", profile.Role)
+	}
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	h := SetupHandlerTest()
+	interceptor := h.AuthInterceptor()
+	called := false
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	}
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: user.UserService_Login_FullMethodName}, next)
+	if err != nil || !called {
+		t.Errorf("public method must pass without token, got %v", err)
+	}
+
+	called = false
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: user.UserService_GetProfile_FullMethodName}, next)
+	if status.Code(err) != codes.Unauthenticated || called {
+		t.Errorf("protected method must require token, got %v", err)
+	}
+
+	_, err = interceptor(ctxAs(h, adminID, "admin"), nil, &grpc.UnaryServerInfo{FullMethod: user.UserService_ListUsers_FullMethodName}, next)
+	if err != nil || !called {
+		t.Errorf("valid token must pass, got %v", err)
+	}
+	if !handler.PublicMethods[user.UserService_Register_FullMethodName] {
+		t.Error("Register must be public")
+	}
+}